@@ -24,6 +24,10 @@ func AutoMigrate() error {
 		log.Printf("迁移保存表失败: %v", err)
 		return err
 	}
+	if err := DB.AutoMigrate(&UsernameHistory{}); err != nil {
+		log.Printf("迁移改名历史表失败: %v", err)
+		return err
+	}
 
 	log.Println("数据库表结构迁移完成")
 	return nil
@@ -19,6 +19,14 @@ var (
 	ErrSaveNotFound     = errors.New("存档不存在")
 	ErrCreateSaveFailed = errors.New("创建存档失败")
 	ErrUpdateSaveFailed = errors.New("更新存档失败")
+	ErrSaveCorrupted    = errors.New("存档数据损坏")
+	ErrSaveConflict     = errors.New("存档已被修改，版本冲突")
+)
+
+// 存档状态
+const (
+	SaveStatusActive  = "active"  // 正常
+	SaveStatusDeleted = "deleted" // 已删除（软删除）
 )
 
 // Save 存档模型定义
@@ -30,8 +38,10 @@ var (
 //   - SaveName: 保存项名称
 //   - SaveDescription: 保存项描述
 //   - SaveData: 保存的具体内容（如JSON字符串）
+//   - Checksum: SaveData 的 SHA-256 校验和（十六进制），旧数据可能为空
 //   - SaveType: 保存类型（如草稿、配置等）
 //   - SaveStatus: 保存状态（如active、deleted等）
+//   - Version: 乐观锁版本号，创建时为1，每次更新自增
 //   - CreatedAt: 创建时间（unix时间戳）
 //   - UpdatedAt: 更新时间（unix时间戳）
 type Save struct {
@@ -41,8 +51,10 @@ type Save struct {
 	SaveName        string         `gorm:"type:varchar(128);not null" json:"save_name"`             // 保存项名称
 	SaveDescription string         `gorm:"type:varchar(512)" json:"save_description"`               // 保存项描述
 	SaveData        string         `gorm:"type:text;not null" json:"save_data"`                     // 保存的具体内容
+	Checksum        string         `gorm:"type:char(64)" json:"checksum"`                           // 保存内容的SHA-256校验和
 	SaveType        string         `gorm:"type:varchar(32);not null" json:"save_type"`              // 保存类型
 	SaveStatus      string         `gorm:"type:varchar(16);not null" json:"save_status"`            // 保存状态
+	Version         int64          `gorm:"not null;default:1" json:"version"`                       // 乐观锁版本号，每次更新自增
 	CreatedAt       int64          `gorm:"autoCreateTime" json:"created_at"`                        // 创建时间(unix时间戳)
 	UpdatedAt       int64          `gorm:"autoUpdateTime" json:"updated_at"`                        // 更新时间(unix时间戳)
 }
@@ -63,13 +75,17 @@ func CreateSave(save *Save) (int64, error) {
 	if save == nil {
 		return 0, ErrCreateSaveFailed
 	}
+	save.Checksum = saveChecksum(save.SaveData)
+	if save.Version <= 0 {
+		save.Version = 1
+	}
 	if err := DB.Create(save).Error; err != nil {
 		return 0, ErrCreateSaveFailed
 	}
 	return save.ID, nil
 }
 
-// QuerySaveByID 通过存档ID查询存档信息
+// QuerySaveByID 通过存档ID查询存档信息，已软删除的存档视为不存在
 // 参数:
 //   - saveID: 存档ID
 //
@@ -78,16 +94,19 @@ func CreateSave(save *Save) (int64, error) {
 //   - error: 操作错误信息
 func QuerySaveByID(saveID int64) (*Save, error) {
 	var save Save
-	if err := DB.Where("id = ?", saveID).First(&save).Error; err != nil {
+	if err := DB.Where("id = ? AND save_status <> ?", saveID, SaveStatusDeleted).First(&save).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrSaveNotFound
 		}
 		return nil, err
 	}
+	if err := verifySaveChecksum(&save); err != nil {
+		return nil, err
+	}
 	return &save, nil
 }
 
-// QuerySavesByUser 根据用户ID获取该用户所有存档，支持分页
+// QuerySavesByUser 根据用户ID获取该用户所有存档（不含已软删除的存档），支持分页
 // 参数:
 //   - userID: 用户ID
 //   - page: 页码（从1开始）
@@ -106,7 +125,7 @@ func QuerySavesByUser(userID int64, page, pageSize int) ([]Save, int64, error) {
 	if pageSize < 1 {
 		pageSize = 10
 	}
-	db := DB.Model(&Save{}).Where("user_id = ?", userID)
+	db := DB.Model(&Save{}).Where("user_id = ? AND save_status <> ?", userID, SaveStatusDeleted)
 	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -117,7 +136,72 @@ func QuerySavesByUser(userID int64, page, pageSize int) ([]Save, int64, error) {
 	return saves, total, nil
 }
 
-// QuerySavesBySaveID 通过保存唯一标识符查询存档
+// CountSavesByUser 统计用户当前的存档数量（不含已软删除的存档）
+// 参数:
+//   - userID: 用户ID
+//
+// 返回:
+//   - int64: 存档数量
+//   - error: 操作错误信息
+func CountSavesByUser(userID int64) (int64, error) {
+	var count int64
+	if err := DB.Model(&Save{}).Where("user_id = ? AND save_status <> ?", userID, SaveStatusDeleted).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// SumSaveDataSizeByUser 统计用户所有存档内容的总字节数（不含已软删除的存档）
+// 参数:
+//   - userID: 用户ID
+//
+// 返回:
+//   - int64: 存档内容总字节数
+//   - error: 操作错误信息
+func SumSaveDataSizeByUser(userID int64) (int64, error) {
+	var total int64
+	if err := DB.Model(&Save{}).Where("user_id = ? AND save_status <> ?", userID, SaveStatusDeleted).
+		Select("COALESCE(SUM(" + saveDataSizeExpr() + "), 0)").Scan(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// SaveTypeStats 按存档类型聚合的存储统计
+type SaveTypeStats struct {
+	SaveType string // 存档类型
+	Count    int64  // 存档数量
+	Bytes    int64  // 存档内容总字节数
+}
+
+// SumSaveStatsByUserGroupByType 按存档类型聚合统计用户的存档数量与内容字节数（不含已软删除的存档）
+// 通过一次 GROUP BY 聚合查询完成，不加载存档内容
+// 参数:
+//   - userID: 用户ID
+//
+// 返回:
+//   - []SaveTypeStats: 各类型的统计，按类型名排序
+//   - error: 操作错误信息
+func SumSaveStatsByUserGroupByType(userID int64) ([]SaveTypeStats, error) {
+	var stats []SaveTypeStats
+	if err := DB.Model(&Save{}).Where("user_id = ? AND save_status <> ?", userID, SaveStatusDeleted).
+		Select("save_type, COUNT(*) AS count, COALESCE(SUM(" + saveDataSizeExpr() + "), 0) AS bytes").
+		Group("save_type").Order("save_type").Scan(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// saveDataSizeExpr 返回计算存档内容字节数的 SQL 表达式
+// PostgreSQL 的 LENGTH 返回字符数，需用 OCTET_LENGTH；SQLite 转为 BLOB 后 LENGTH 即字节数
+func saveDataSizeExpr() string {
+	if DB.Dialector.Name() == "postgres" {
+		return "OCTET_LENGTH(save_data)"
+	}
+	return "LENGTH(CAST(save_data AS BLOB))"
+}
+
+// QuerySavesBySaveID 通过保存唯一标识符查询存档，已软删除的存档视为不存在
 // 参数:
 //   - saveID: 存档唯一标识符
 // 返回:
@@ -128,17 +212,22 @@ func QuerySavesBySaveID(saveID string) (*Save, error) {
 		return nil, ErrSaveNotFound
 	}
 	var save Save
-	err := DB.Where("save_id = ?", saveID).First(&save).Error
+	err := DB.Where("save_id = ? AND save_status <> ?", saveID, SaveStatusDeleted).First(&save).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrSaveNotFound
 		}
 		return nil, err
 	}
+	if err := verifySaveChecksum(&save); err != nil {
+		return nil, err
+	}
 	return &save, nil
 }
 
-// UpdateSave 更新存档内容
+// UpdateSave 更新存档内容并自增版本号
+// Version 大于0时按乐观锁更新：仅当库中版本与之相同时写入，成功后 save.Version 自增；
+// 版本不一致时返回 ErrSaveConflict。Version 为0时不校验版本
 // 参数:
 //   - save: 包含更新内容的存档结构体，必须有ID
 //
@@ -152,17 +241,31 @@ func UpdateSave(save *Save) error {
 		"save_name":        save.SaveName,
 		"save_description": save.SaveDescription,
 		"save_data":        save.SaveData,
+		"checksum":         saveChecksum(save.SaveData),
 		"save_type":        save.SaveType,
 		"save_status":      save.SaveStatus,
 		"updated_at":       time.Now().Unix(),
+		"version":          gorm.Expr("version + 1"),
 	}
-	if err := DB.Model(&Save{}).Where("id = ?", save.ID).Updates(m).Error; err != nil {
+	query := DB.Model(&Save{}).Where("id = ?", save.ID)
+	if save.Version > 0 {
+		query = query.Where("version = ?", save.Version)
+	}
+	result := query.Updates(m)
+	if result.Error != nil {
 		return ErrUpdateSaveFailed
 	}
+	if save.Version > 0 {
+		if result.RowsAffected == 0 {
+			return ErrSaveConflict
+		}
+		save.Version++
+	}
 	return nil
 }
 
 // DeleteSave 删除存档（软删除）
+// 只将 save_status 标记为已删除，不物理删除数据；存档不存在或已删除时返回 ErrSaveNotFound
 // 参数:
 //   - saveID: 存档ID
 //
@@ -172,12 +275,93 @@ func DeleteSave(saveID int64) error {
 	if saveID == 0 {
 		return ErrSaveNotFound
 	}
-	if err := DB.Where("id = ?", saveID).Delete(&Save{}).Error; err != nil {
-		return err
+	result := DB.Model(&Save{}).Where("id = ? AND save_status <> ?", saveID, SaveStatusDeleted).
+		Update("save_status", SaveStatusDeleted)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSaveNotFound
 	}
 	return nil
 }
 
+// BatchDeleteSavesByUser 在同一事务中批量软删除指定用户的存档
+// 逐个校验存档归属，只将属于该用户的存档标记为已删除；不存在、已删除或属于他人的存档计入失败明细
+// 参数:
+//   - userID: 用户ID
+//   - saveIDs: 存档唯一标识符列表，重复项只处理一次
+//
+// 返回:
+//   - []string: 成功删除的存档唯一标识符
+//   - map[string]error: 删除失败的存档唯一标识符及原因
+//   - error: 事务执行错误，此时不会删除任何存档
+func BatchDeleteSavesByUser(userID int64, saveIDs []string) ([]string, map[string]error, error) {
+	deleted := make([]string, 0, len(saveIDs))
+	failed := make(map[string]error)
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var saves []Save
+		if err := tx.Where("save_id IN ?", saveIDs).Find(&saves).Error; err != nil {
+			return err
+		}
+		owners := make(map[string]Save, len(saves))
+		for _, s := range saves {
+			owners[s.SaveID] = s
+		}
+
+		ids := make([]int64, 0, len(saves))
+		seen := make(map[string]struct{}, len(saveIDs))
+		for _, saveID := range saveIDs {
+			if _, ok := seen[saveID]; ok {
+				continue
+			}
+			seen[saveID] = struct{}{}
+
+			// 他人的存档与不存在的存档同样处理，避免泄露存档是否存在
+			s, ok := owners[saveID]
+			if !ok || s.UserID != userID || s.SaveStatus == SaveStatusDeleted {
+				failed[saveID] = ErrSaveNotFound
+				continue
+			}
+			ids = append(ids, s.ID)
+			deleted = append(deleted, saveID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&Save{}).Where("id IN ? AND user_id = ?", ids, userID).
+			Update("save_status", SaveStatusDeleted).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return deleted, failed, nil
+}
+
+// MarkStaleSavesDeleted 将指定类型中更新时间早于 cutoff 的存档标记为已删除
+// 只修改 save_status，不物理删除数据，已删除的存档不重复计数
+// 参数:
+//   - cutoff: 更新时间下限（unix时间戳），早于该时间的存档会被标记
+//   - types: 存档类型列表，为空时不做任何处理
+//
+// 返回:
+//   - int64: 被标记的存档数量
+//   - error: 操作错误信息
+func MarkStaleSavesDeleted(cutoff int64, types []string) (int64, error) {
+	if len(types) == 0 {
+		return 0, nil
+	}
+	result := DB.Model(&Save{}).
+		Where("updated_at < ? AND save_type IN ? AND save_status <> ?", cutoff, types, SaveStatusDeleted).
+		UpdateColumn("save_status", SaveStatusDeleted)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
 // ListSaves 获取所有存档（支持分页）
 // 参数:
 //   - page: 页码
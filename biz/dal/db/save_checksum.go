@@ -0,0 +1,41 @@
+/*
+ * NovelAI Project
+ * Copyright (C) 2023-2025
+ */
+
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+)
+
+// saveChecksum 计算存档内容的 SHA-256 校验和
+// 参数:
+//   - data: 存档内容
+//
+// 返回:
+//   - string: 十六进制编码的校验和
+func saveChecksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifySaveChecksum 重新计算存档内容的校验和并与库中记录比对
+// 未记录校验和的旧数据直接跳过
+// 参数:
+//   - save: 从数据库读出的存档
+//
+// 返回:
+//   - error: 校验和不一致时返回 ErrSaveCorrupted
+func verifySaveChecksum(save *Save) error {
+	if save.Checksum == "" {
+		return nil
+	}
+	if saveChecksum(save.SaveData) != save.Checksum {
+		log.Printf("存档校验和不一致: id=%d, save_id=%s", save.ID, save.SaveID)
+		return ErrSaveCorrupted
+	}
+	return nil
+}
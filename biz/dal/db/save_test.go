@@ -110,8 +110,9 @@ func TestUpdateSave(t *testing.T) {
 	save.SaveStatus = "deleted"
 	err := UpdateSave(save)
 	assert.NoError(t, err)
-	updated, err := QuerySaveByID(save.ID)
-	assert.NoError(t, err)
+	// 状态已改为删除，按ID查询会过滤掉，直接读表校验
+	var updated Save
+	assert.NoError(t, DB.Where("id = ?", save.ID).First(&updated).Error)
 	assert.Equal(t, "更新后的名称", updated.SaveName)
 	assert.Equal(t, "更新后的描述", updated.SaveDescription)
 	assert.Equal(t, "{\"key\":\"new\"}", updated.SaveData)
@@ -119,6 +120,27 @@ func TestUpdateSave(t *testing.T) {
 	assert.Equal(t, "deleted", updated.SaveStatus)
 }
 
+// TestUpdateSaveVersionConflict 测试基于同一旧版本的两次更新，第二次返回冲突
+func TestUpdateSaveVersionConflict(t *testing.T) {
+	setupSaveTestDB(t)
+	save := createTestSave(t, 5)
+	assert.Equal(t, int64(1), save.Version, "新建存档版本号为1")
+
+	first := *save
+	first.SaveData = "{\"from\":\"pc\"}"
+	assert.NoError(t, UpdateSave(&first))
+	assert.Equal(t, int64(2), first.Version)
+
+	second := *save
+	second.SaveData = "{\"from\":\"mobile\"}"
+	assert.ErrorIs(t, UpdateSave(&second), ErrSaveConflict)
+
+	updated, err := QuerySaveByID(save.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"from\":\"pc\"}", updated.SaveData, "冲突的更新不应覆盖已有内容")
+	assert.Equal(t, int64(2), updated.Version)
+}
+
 // TestDeleteSave 测试删除存档
 func TestDeleteSave(t *testing.T) {
 	setupSaveTestDB(t)
@@ -127,6 +149,16 @@ func TestDeleteSave(t *testing.T) {
 	assert.NoError(t, err)
 	_, err = QuerySaveByID(save.ID)
 	assert.ErrorIs(t, err, ErrSaveNotFound)
+	_, err = QuerySavesBySaveID(save.SaveID)
+	assert.ErrorIs(t, err, ErrSaveNotFound)
+
+	// 软删除只修改状态，记录仍保留在表中
+	var stored Save
+	assert.NoError(t, DB.Where("id = ?", save.ID).First(&stored).Error)
+	assert.Equal(t, SaveStatusDeleted, stored.SaveStatus)
+
+	// 已删除的存档再次删除返回不存在
+	assert.ErrorIs(t, DeleteSave(save.ID), ErrSaveNotFound)
 }
 
 // TestListSaves 测试分页获取所有存档
@@ -153,3 +185,76 @@ func TestCheckSaveExists(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, notExists)
 }
+
+// TestSaveChecksum 测试存档校验和的写入与校验
+func TestSaveChecksum(t *testing.T) {
+	setupSaveTestDB(t)
+	save := createTestSave(t, 7)
+	assert.Equal(t, saveChecksum(save.SaveData), save.Checksum)
+
+	// 正常读写校验通过
+	save.SaveData = "{\"key\":\"checksum\"}"
+	assert.NoError(t, UpdateSave(save))
+	updated, err := QuerySavesBySaveID(save.SaveID)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"key\":\"checksum\"}", updated.SaveData)
+	assert.Equal(t, saveChecksum(updated.SaveData), updated.Checksum)
+
+	// 绕过 DAL 篡改库中数据后读取报损坏
+	err = DB.Model(&Save{}).Where("id = ?", save.ID).Update("save_data", "{\"key\":\"tampered\"}").Error
+	assert.NoError(t, err)
+	_, err = QuerySaveByID(save.ID)
+	assert.ErrorIs(t, err, ErrSaveCorrupted)
+	_, err = QuerySavesBySaveID(save.SaveID)
+	assert.ErrorIs(t, err, ErrSaveCorrupted)
+
+	// 无校验和的旧数据跳过校验
+	err = DB.Model(&Save{}).Where("id = ?", save.ID).Update("checksum", "").Error
+	assert.NoError(t, err)
+	legacy, err := QuerySaveByID(save.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"key\":\"tampered\"}", legacy.SaveData)
+}
+
+// TestCountAndSumSavesByUser 测试按用户统计存档数量与内容字节数
+func TestCountAndSumSavesByUser(t *testing.T) {
+	setupSaveTestDB(t)
+	createTestSave(t, 8)
+	save := createTestSave(t, 8)
+	createTestSave(t, 9)
+
+	// 中文按 UTF-8 字节计算
+	save.SaveData = "存档"
+	assert.NoError(t, UpdateSave(save))
+
+	count, err := CountSavesByUser(8)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	size, err := SumSaveDataSizeByUser(8)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len("{\"key\":\"value\"}")+len("存档")), size)
+
+	empty, err := SumSaveDataSizeByUser(10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), empty)
+}
+
+// TestSumSaveStatsByUserGroupByType 测试按类型聚合用户的存档数量与内容字节数
+func TestSumSaveStatsByUserGroupByType(t *testing.T) {
+	setupSaveTestDB(t)
+	createTestSave(t, 8)
+	createTestSave(t, 8)
+	config := createTestSave(t, 8)
+	config.SaveType = "config"
+	config.SaveData = "存档"
+	assert.NoError(t, UpdateSave(config))
+	createTestSave(t, 9)
+
+	stats, err := SumSaveStatsByUserGroupByType(8)
+	assert.NoError(t, err)
+	assert.Equal(t, []SaveTypeStats{
+		{SaveType: "config", Count: 1, Bytes: int64(len("存档"))},
+		{SaveType: "draft", Count: 2, Bytes: int64(2 * len("{\"key\":\"value\"}"))},
+	}, stats)
+}
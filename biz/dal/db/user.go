@@ -6,9 +6,13 @@
 package db
 
 import (
+	"crypto/subtle"
 	"errors"
+	"strings"
 	"time"
 
+	"novelai/pkg/utils/crypto"
+
 	"gorm.io/gorm"
 )
 
@@ -24,6 +28,12 @@ var (
 // TableName 用户表名常量
 const TableNameUser = "users"
 
+// 用户角色，决定存档配额等权益
+const (
+	UserRoleNormal = "normal" // 普通用户
+	UserRoleMember = "member" // 会员用户
+)
+
 // User 用户模型定义 - 与protobuf定义保持一致
 // 包含用户基本信息
 type User struct {
@@ -34,6 +44,9 @@ type User struct {
 	Avatar    string         `gorm:"type:varchar(256)" json:"avatar,omitempty"`                     // 头像URL
 	Email     string         `gorm:"type:varchar(128);uniqueIndex" json:"email,omitempty"`          // 电子邮箱
 	Status    int32          `gorm:"default:0" json:"status,omitempty"`                             // 用户状态：0-正常，1-禁用
+	IsAdmin   bool           `gorm:"default:false" json:"is_admin,omitempty"`                       // 是否管理员
+	Role      string         `gorm:"type:varchar(32);default:normal" json:"role,omitempty"`         // 用户角色：normal-普通用户，member-会员
+	LastActiveAt int64       `gorm:"default:0;index" json:"last_active_at,omitempty"`               // 最后活跃时间（Unix时间戳，毫秒）
 	CreatedAt int64          `gorm:"autoCreateTime:milli" json:"created_at,omitempty"`              // 创建时间（Unix时间戳）
 	UpdatedAt int64          `gorm:"autoUpdateTime:milli" json:"updated_at,omitempty"`              // 更新时间（Unix时间戳）
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`                                                // 软删除时间
@@ -52,40 +65,38 @@ func (User) TableName() string {
 //   - int64: 创建成功返回用户ID
 //   - error: 操作错误信息
 func CreateUser(user *User) (int64, error) {
-	// 检查用户名是否已存在
-	var count int64
-	if err := DB.Model(&User{}).Where("username = ?", user.Username).Count(&count).Error; err != nil {
-		return 0, err
-	}
-	if count > 0 {
-		return 0, ErrUserAlreadyExists
-	}
-
-	// 创建用户记录
+	// 不做先查后插的预检查，用户名唯一性完全依赖数据库唯一索引，避免并发注册时的竞态
 	if err := DB.Create(user).Error; err != nil {
-		// 处理数据库唯一性冲突（多数据库兼容）
-		// Postgres: SQLSTATE 23505；SQLite: code 2067；MySQL: 1062
-		errMsg := err.Error()
-		if errMsg != "" {
-			if contains(errMsg, "duplicate key value") && contains(errMsg, "unique constraint") {
-				return 0, ErrUserAlreadyExists // Postgres
-			}
-			//if contains(errMsg, "UNIQUE constraint failed") {
-			//	return 0, ErrUserAlreadyExists // SQLite
-			//}
-			//if contains(errMsg, "Duplicate entry") {
-			//	return 0, ErrUserAlreadyExists // MySQL
-			//}
+		if isUniqueViolation(err) {
+			return 0, ErrUserAlreadyExists
 		}
 		return 0, ErrCreateUserFailed
 	}
 	return user.ID, nil
+}
 
+// uniqueViolationMarkers 各数据库唯一约束冲突的错误信息特征
+// Postgres: SQLSTATE 23505；SQLite: code 2067；MySQL: 1062
+var uniqueViolationMarkers = []string{
+	"duplicate key value violates unique constraint", // Postgres
+	"SQLSTATE 23505",           // Postgres（pgx）
+	"UNIQUE constraint failed", // SQLite
+	"Duplicate entry",          // MySQL
+	"Error 1062",               // MySQL
 }
 
-// contains 判断字符串是否包含子串
-func contains(s, substr string) bool {
-	return len(substr) > 0 && len(s) >= len(substr) && (s == substr || (len(s) > len(substr) && (s[:len(substr)] == substr || contains(s[1:], substr))))
+// isUniqueViolation 判断错误是否为数据库唯一约束冲突（多数据库兼容）
+func isUniqueViolation(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	errMsg := err.Error()
+	for _, marker := range uniqueViolationMarkers {
+		if strings.Contains(errMsg, marker) {
+			return true
+		}
+	}
+	return false
 }
 
 // QueryUserByUsername 通过用户名查询用户信息
@@ -126,7 +137,17 @@ func QueryUserByID(userID int64) (*User, error) {
 	return &user, nil
 }
 
+// GetRole 返回用户角色，未设置时为普通用户
+func (u *User) GetRole() string {
+	if u.Role == "" {
+		return UserRoleNormal
+	}
+	return u.Role
+}
+
 // VerifyUser 验证用户名和密码
+// 保存的密码为 bcrypt 哈希（如管理员重置后的密码）时按 bcrypt 校验，
+// 否则按常量时间直接比对，校验通过后将旧格式密码迁移为 bcrypt 哈希
 // 参数:
 //   - username: 用户名
 //   - password: 密码
@@ -136,13 +157,26 @@ func QueryUserByID(userID int64) (*User, error) {
 //   - error: 操作错误信息
 func VerifyUser(username, password string) (int64, error) {
 	var user User
-	result := DB.Where("username = ? AND password = ?", username, password).First(&user)
+	result := DB.Where("username = ?", username).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return 0, ErrInvalidPassword
 		}
 		return 0, result.Error
 	}
+	if crypto.IsBcryptHash(user.Password) {
+		if crypto.VerifyPasswordBcrypt(password, user.Password) != nil {
+			return 0, ErrInvalidPassword
+		}
+	} else {
+		if subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+			return 0, ErrInvalidPassword
+		}
+		// 旧格式密码校验通过后迁移为 bcrypt 哈希，迁移失败不影响本次登录
+		if hashed, err := crypto.HashPasswordBcrypt(password); err == nil {
+			DB.Model(&user).UpdateColumn("password", hashed)
+		}
+	}
 
 	// 更新最后登录时间
 	now := time.Now()
@@ -152,6 +186,7 @@ func VerifyUser(username, password string) (int64, error) {
 }
 
 // UpdateUserProfile 更新用户资料
+// 只更新用户可自助修改的字段，Status、IsAdmin 等敏感字段需通过 UpdateUserAdminFields 修改
 // 参数:
 //   - user: 包含更新信息的用户结构体
 //
@@ -160,10 +195,9 @@ func VerifyUser(username, password string) (int64, error) {
 func UpdateUserProfile(user *User) error {
 	// 只允许更新特定字段
 	result := DB.Model(&User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
-		"nickname":         user.Nickname,
-		"avatar":           user.Avatar,
-		"email":            user.Email,
-		"status":           user.Status,
+		"nickname": user.Nickname,
+		"avatar":   user.Avatar,
+		"email":    user.Email,
 	})
 
 	if result.Error != nil {
@@ -174,6 +208,47 @@ func UpdateUserProfile(user *User) error {
 		return ErrUserNotFound
 	}
 
+	// 资料变更后使缓存失效
+	InvalidateUserCache(user.ID)
+
+	return nil
+}
+
+// UserAdminFields 仅管理员可修改的用户敏感字段，nil 表示不修改
+type UserAdminFields struct {
+	Status  *int32 // 用户状态
+	IsAdmin *bool  // 是否管理员
+}
+
+// UpdateUserAdminFields 更新用户敏感字段
+// 参数:
+//   - userID: 用户ID
+//   - fields: 需要修改的敏感字段
+//
+// 返回:
+//   - error: 操作错误信息
+func UpdateUserAdminFields(userID int64, fields UserAdminFields) error {
+	updates := make(map[string]interface{}, 2)
+	if fields.Status != nil {
+		updates["status"] = *fields.Status
+	}
+	if fields.IsAdmin != nil {
+		updates["is_admin"] = *fields.IsAdmin
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	result := DB.Model(&User{}).Where("id = ?", userID).Updates(updates)
+	if result.Error != nil {
+		return ErrUpdateUserFailed
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+
+	InvalidateUserCache(userID)
+
 	return nil
 }
 
@@ -194,6 +269,7 @@ func UpdateUserPassword(userID int64, newPassword string) error {
 		return ErrUserNotFound
 	}
 
+	InvalidateUserCache(userID)
 	return nil
 }
 
@@ -213,6 +289,8 @@ func DeleteUser(userID int64) error {
 		return ErrUserNotFound
 	}
 
+	InvalidateUserCache(userID)
+
 	return nil
 }
 
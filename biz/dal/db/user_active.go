@@ -0,0 +1,97 @@
+/*
+ * NovelAI Project
+ * Copyright (C) 2023-2025
+ */
+
+package db
+
+import (
+	"sync"
+	"time"
+)
+
+var (
+	// ActiveThrottle 刷新最后活跃时间的最小间隔，节流期内不重复写库
+	ActiveThrottle = 5 * time.Minute
+
+	// OnlineWindow 在线判定窗口，最近该时长内活跃视为在线
+	// 节流期内最后活跃时间不会刷新，窗口取节流间隔的两倍，避免持续活跃的用户被判为离线
+	OnlineWindow = 2 * ActiveThrottle
+)
+
+// nowFunc 获取当前时间，测试时可替换
+var nowFunc = time.Now
+
+// activeWrites 记录每个用户最近一次写库的时间，用于节流
+// 超过节流间隔的记录已不再起作用，每个节流间隔清理一次，避免随用户数无限增长
+var activeWrites = struct {
+	sync.Mutex
+	last      map[int64]time.Time
+	lastPrune time.Time
+}{last: make(map[int64]time.Time)}
+
+// pruneActiveWritesLocked 清理超过节流间隔的写库记录，调用方需持有锁
+func pruneActiveWritesLocked(now time.Time) {
+	if now.Sub(activeWrites.lastPrune) < ActiveThrottle {
+		return
+	}
+	activeWrites.lastPrune = now
+	for userID, last := range activeWrites.last {
+		if now.Sub(last) >= ActiveThrottle {
+			delete(activeWrites.last, userID)
+		}
+	}
+}
+
+// TouchUserActive 刷新用户最后活跃时间（节流）
+// 参数:
+//   - userID: 用户ID
+//
+// 返回:
+//   - bool: 本次是否实际写库，节流期内返回 false
+//   - error: 操作错误信息
+func TouchUserActive(userID int64) (bool, error) {
+	now := nowFunc()
+
+	activeWrites.Lock()
+	if last, ok := activeWrites.last[userID]; ok && now.Sub(last) < ActiveThrottle {
+		activeWrites.Unlock()
+		return false, nil
+	}
+	pruneActiveWritesLocked(now)
+	activeWrites.last[userID] = now
+	activeWrites.Unlock()
+
+	result := DB.Model(&User{}).Where("id = ?", userID).UpdateColumn("last_active_at", now.UnixMilli())
+	if result.Error == nil && result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// 写库失败时撤销节流记录，允许下次请求重试
+	activeWrites.Lock()
+	delete(activeWrites.last, userID)
+	activeWrites.Unlock()
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return false, ErrUserNotFound
+}
+
+// IsOnline 判断用户是否在线（最近 OnlineWindow 内活跃）
+// 参数:
+//   - userID: 用户ID
+//
+// 返回:
+//   - bool: 是否在线
+//   - error: 操作错误信息
+func IsOnline(userID int64) (bool, error) {
+	user, err := QueryUserByID(userID)
+	if err != nil {
+		return false, err
+	}
+	if user.LastActiveAt == 0 {
+		return false, nil
+	}
+	lastActive := time.UnixMilli(user.LastActiveAt)
+	return nowFunc().Sub(lastActive) < OnlineWindow, nil
+}
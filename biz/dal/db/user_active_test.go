@@ -0,0 +1,108 @@
+/*
+ * NovelAI Project
+ * Copyright (C) 2023-2025
+ */
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUserActiveAndOnline 测试最后活跃时间节流刷新与在线状态
+func TestUserActiveAndOnline(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	originalNow := nowFunc
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = originalNow }()
+
+	user := createTestUser(t)
+
+	// 未活跃过时为离线
+	online, err := IsOnline(user.ID)
+	require.NoError(t, err)
+	assert.False(t, online)
+
+	// 活跃后为在线
+	written, err := TouchUserActive(user.ID)
+	require.NoError(t, err)
+	assert.True(t, written)
+	online, err = IsOnline(user.ID)
+	require.NoError(t, err)
+	assert.True(t, online)
+
+	// 节流期内不重复写库
+	now = now.Add(time.Minute)
+	written, err = TouchUserActive(user.ID)
+	require.NoError(t, err)
+	assert.False(t, written)
+	stored, err := QueryUserByID(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Minute).UnixMilli(), stored.LastActiveAt)
+
+	// 超过在线窗口后为离线
+	now = now.Add(10 * time.Minute)
+	online, err = IsOnline(user.ID)
+	require.NoError(t, err)
+	assert.False(t, online)
+
+	// 节流期过后再次写库
+	written, err = TouchUserActive(user.ID)
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	_, err = TouchUserActive(99999)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+// TestOnlineWindowCoversThrottle 测试持续活跃的用户在节流期内始终在线，且过期的节流记录会被清理
+func TestOnlineWindowCoversThrottle(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local)
+	originalNow := nowFunc
+	nowFunc = func() time.Time { return now }
+	defer func() { nowFunc = originalNow }()
+
+	// 重置其他测试留下的节流状态
+	activeWrites.Lock()
+	activeWrites.last = make(map[int64]time.Time)
+	activeWrites.lastPrune = time.Time{}
+	activeWrites.Unlock()
+
+	user := createTestUser(t)
+
+	written, err := TouchUserActive(user.ID)
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	// 节流期即将结束时仍有请求，最后活跃时间未刷新但仍应在线
+	now = now.Add(ActiveThrottle - time.Second)
+	written, err = TouchUserActive(user.ID)
+	require.NoError(t, err)
+	assert.False(t, written)
+	now = now.Add(time.Second)
+	online, err := IsOnline(user.ID)
+	require.NoError(t, err)
+	assert.True(t, online, "距上次写库恰好一个节流间隔时应在线")
+
+	// 超过在线窗口后为离线
+	now = now.Add(OnlineWindow - ActiveThrottle)
+	online, err = IsOnline(user.ID)
+	require.NoError(t, err)
+	assert.False(t, online)
+
+	// 其他请求写库时清理过期的节流记录
+	_, err = TouchUserActive(user.ID + 1)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	activeWrites.Lock()
+	_, stale := activeWrites.last[user.ID]
+	activeWrites.Unlock()
+	assert.False(t, stale, "超过节流间隔的记录应被清理")
+}
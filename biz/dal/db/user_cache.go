@@ -0,0 +1,143 @@
+/*
+ * NovelAI Project
+ * Copyright (C) 2023-2025
+ */
+
+package db
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultUserCacheTTL 用户信息缓存默认过期时间
+const DefaultUserCacheTTL = 5 * time.Minute
+
+// userCacheEntry 用户缓存条目
+type userCacheEntry struct {
+	user     User      // 用户信息副本
+	expireAt time.Time // 过期时间
+}
+
+// userCache 带 TTL 的用户信息缓存
+// 用于列表渲染等按 userId 批量取昵称、头像的场景，减少重复查库
+type userCache struct {
+	mu        sync.RWMutex
+	ttl       time.Duration
+	entries   map[int64]userCacheEntry
+	lastEvict time.Time // 上次清理过期条目的时间
+}
+
+// evictExpiredLocked 清理已过期的条目，每个 TTL 周期最多执行一次，调用方需持有写锁
+func (c *userCache) evictExpiredLocked(now time.Time) {
+	if now.Sub(c.lastEvict) < c.ttl {
+		return
+	}
+	c.lastEvict = now
+	for id, entry := range c.entries {
+		if !now.Before(entry.expireAt) {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// defaultUserCache 全局用户信息缓存
+var defaultUserCache = &userCache{
+	ttl:     DefaultUserCacheTTL,
+	entries: make(map[int64]userCacheEntry),
+}
+
+// loadUsersByIDs 批量查询用户，测试时可替换以统计查库次数
+var loadUsersByIDs = func(ids []int64) ([]User, error) {
+	var users []User
+	if err := DB.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUsersCached 按用户ID批量获取用户信息，优先读取缓存
+// 未命中或已过期的ID会批量查库并回填缓存；不存在的用户不出现在结果中
+// 缓存与返回的用户信息均不含密码哈希
+// 参数:
+//   - ids: 用户ID列表，允许重复
+//
+// 返回:
+//   - map[int64]*User: 用户ID到用户信息的映射，返回的均为副本，修改不影响缓存
+func GetUsersCached(ids []int64) map[int64]*User {
+	result := make(map[int64]*User, len(ids))
+	now := time.Now()
+
+	// 读取缓存，收集未命中的ID
+	missing := make([]int64, 0)
+	seen := make(map[int64]struct{}, len(ids))
+	defaultUserCache.mu.RLock()
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		if entry, ok := defaultUserCache.entries[id]; ok && now.Before(entry.expireAt) {
+			user := entry.user
+			result[id] = &user
+			continue
+		}
+		missing = append(missing, id)
+	}
+	defaultUserCache.mu.RUnlock()
+
+	if len(missing) == 0 {
+		return result
+	}
+
+	// 批量查库并回填
+	users, err := loadUsersByIDs(missing)
+	if err != nil {
+		log.Printf("批量查询用户失败: %v", err)
+		return result
+	}
+
+	defaultUserCache.mu.Lock()
+	now = time.Now()
+	defaultUserCache.evictExpiredLocked(now)
+	expireAt := now.Add(defaultUserCache.ttl)
+	for _, u := range users {
+		u.Password = ""
+		defaultUserCache.entries[u.ID] = userCacheEntry{user: u, expireAt: expireAt}
+		user := u
+		result[u.ID] = &user
+	}
+	defaultUserCache.mu.Unlock()
+
+	return result
+}
+
+// InvalidateUserCache 使指定用户的缓存失效
+// 参数:
+//   - ids: 需要失效的用户ID
+func InvalidateUserCache(ids ...int64) {
+	defaultUserCache.mu.Lock()
+	defer defaultUserCache.mu.Unlock()
+
+	for _, id := range ids {
+		delete(defaultUserCache.entries, id)
+	}
+}
+
+// SetUserCacheTTL 设置用户信息缓存过期时间，并清空现有缓存
+// 参数:
+//   - ttl: 过期时间，小于等于0时使用默认值
+func SetUserCacheTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultUserCacheTTL
+	}
+
+	defaultUserCache.mu.Lock()
+	defer defaultUserCache.mu.Unlock()
+
+	defaultUserCache.ttl = ttl
+	defaultUserCache.entries = make(map[int64]userCacheEntry)
+	defaultUserCache.lastEvict = time.Time{}
+}
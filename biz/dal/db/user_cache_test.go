@@ -0,0 +1,68 @@
+/*
+ * NovelAI Project
+ * Copyright (C) 2023-2025
+ */
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetUsersCached 测试用户信息缓存的命中与失效
+func TestGetUsersCached(t *testing.T) {
+	setupTestDB(t)
+	SetUserCacheTTL(time.Minute)
+	defer SetUserCacheTTL(DefaultUserCacheTTL)
+
+	// 统计查库次数
+	originalLoader := loadUsersByIDs
+	defer func() { loadUsersByIDs = originalLoader }()
+	loads := 0
+	loadUsersByIDs = func(ids []int64) ([]User, error) {
+		loads++
+		return originalLoader(ids)
+	}
+
+	user := createTestUser(t)
+
+	// 第一次查库
+	users := GetUsersCached([]int64{user.ID, user.ID, 99999})
+	require.Contains(t, users, user.ID)
+	assert.NotContains(t, users, int64(99999), "不存在的用户不应出现在结果中")
+	assert.Equal(t, "测试用户", users[user.ID].Nickname)
+	assert.Empty(t, users[user.ID].Password, "缓存的用户信息不应包含密码")
+	assert.Equal(t, 1, loads)
+
+	// 第二次命中缓存，修改返回值不影响缓存
+	users[user.ID].Nickname = "被修改"
+	users = GetUsersCached([]int64{user.ID})
+	assert.Equal(t, "测试用户", users[user.ID].Nickname)
+	assert.Equal(t, 1, loads)
+
+	// 资料更新后缓存失效，重新查库
+	user.Nickname = "新昵称"
+	require.NoError(t, UpdateUserProfile(user))
+	users = GetUsersCached([]int64{user.ID})
+	assert.Equal(t, "新昵称", users[user.ID].Nickname)
+	assert.Equal(t, 2, loads)
+
+	// 过期后重新查库
+	SetUserCacheTTL(time.Millisecond)
+	GetUsersCached([]int64{user.ID})
+	time.Sleep(5 * time.Millisecond)
+	GetUsersCached([]int64{user.ID})
+	assert.Equal(t, 4, loads)
+
+	// 过期条目在回填时被清理
+	time.Sleep(5 * time.Millisecond)
+	GetUsersCached([]int64{99999})
+	defaultUserCache.mu.RLock()
+	_, cached := defaultUserCache.entries[user.ID]
+	defaultUserCache.mu.RUnlock()
+	assert.False(t, cached, "过期条目应被清理")
+}
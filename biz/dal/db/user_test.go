@@ -7,9 +7,12 @@ package db
 
 import (
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
+	"novelai/pkg/utils/crypto"
+
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -94,6 +97,45 @@ func TestCreateUser(t *testing.T) {
 	assert.Equal(t, ErrUserAlreadyExists, err, "错误类型应为ErrUserAlreadyExists")
 }
 
+// TestCreateUserConcurrent 测试并发创建同名用户只有一个成功
+func TestCreateUserConcurrent(t *testing.T) {
+	setupTestDB(t)
+	// SQLite 共享缓存内存库并发写会报表锁，限制为单连接，冲突只来自唯一索引
+	sqlDB, err := DB.DB()
+	assert.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = CreateUser(&User{
+				Username: "concurrent_user",
+				Password: "pass123",
+				Email:    "concurrent_" + strconv.Itoa(i) + "@example.com",
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrUserAlreadyExists, "冲突应映射为ErrUserAlreadyExists")
+	}
+	assert.Equal(t, 1, succeeded, "并发创建同名用户只应有一个成功")
+
+	var count int64
+	assert.NoError(t, DB.Model(&User{}).Where("username = ?", "concurrent_user").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
 // TestQueryUserByUsername 测试通过用户名查询
 func TestQueryUserByUsername(t *testing.T) {
 	setupTestDB(t)
@@ -149,6 +191,32 @@ func TestVerifyUser(t *testing.T) {
 	assert.Equal(t, ErrInvalidPassword, err, "错误类型应为ErrInvalidPassword")
 }
 
+// TestVerifyUserUpgradesLegacyPassword 测试旧格式密码登录成功后迁移为 bcrypt 哈希
+func TestVerifyUserUpgradesLegacyPassword(t *testing.T) {
+	setupTestDB(t)
+	originalUser := createTestUser(t)
+	assert.False(t, crypto.IsBcryptHash(originalUser.Password), "测试用户应使用旧格式密码")
+
+	// 错误密码不触发迁移
+	_, err := VerifyUser(originalUser.Username, "wrongpassword")
+	assert.Equal(t, ErrInvalidPassword, err)
+	var stored User
+	assert.NoError(t, DB.First(&stored, originalUser.ID).Error)
+	assert.Equal(t, originalUser.Password, stored.Password, "校验失败时密码不应变化")
+
+	_, err = VerifyUser(originalUser.Username, originalUser.Password)
+	assert.NoError(t, err)
+	assert.NoError(t, DB.First(&stored, originalUser.ID).Error)
+	assert.True(t, crypto.IsBcryptHash(stored.Password), "登录成功后应迁移为 bcrypt 哈希")
+
+	// 迁移后仍可使用原密码登录
+	id, err := VerifyUser(originalUser.Username, originalUser.Password)
+	assert.NoError(t, err, "迁移后验证原密码失败")
+	assert.Equal(t, originalUser.ID, id)
+	_, err = VerifyUser(originalUser.Username, "wrongpassword")
+	assert.Equal(t, ErrInvalidPassword, err)
+}
+
 // TestUpdateUserProfile 测试更新用户资料
 func TestUpdateUserProfile(t *testing.T) {
 	setupTestDB(t)
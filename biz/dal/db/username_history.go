@@ -0,0 +1,91 @@
+/*
+ * NovelAI Project
+ * Copyright (C) 2023-2025
+ */
+
+package db
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrUsernameUnchanged 新用户名与当前用户名相同
+var ErrUsernameUnchanged = errors.New("新用户名与当前用户名相同")
+
+// TableNameUsernameHistory 改名历史表名常量
+const TableNameUsernameHistory = "username_history"
+
+// UsernameHistory 用户改名历史
+// 每次改名记录一条，用于按旧用户名追溯历史数据
+type UsernameHistory struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement" json:"id,omitempty"`        // 记录ID
+	UserID      int64  `gorm:"index;not null" json:"user_id,omitempty"`             // 用户ID
+	OldUsername string `gorm:"type:varchar(64);index;not null" json:"old_username"` // 改名前的用户名
+	NewUsername string `gorm:"type:varchar(64);not null" json:"new_username"`       // 改名后的用户名
+	CreatedAt   int64  `gorm:"autoCreateTime:milli" json:"created_at,omitempty"`    // 改名时间（Unix时间戳，毫秒）
+}
+
+// TableName 返回改名历史表名
+func (UsernameHistory) TableName() string {
+	return TableNameUsernameHistory
+}
+
+// ChangeUsername 修改用户名并记录改名历史
+// 更新用户名与写入历史在同一事务中完成；新用户名的唯一性由用户表唯一索引保证
+// 参数:
+//   - userID: 用户ID
+//   - newUsername: 新用户名
+//
+// 返回:
+//   - error: 用户不存在返回 ErrUserNotFound，新用户名已被占用返回 ErrUserAlreadyExists，
+//     与当前用户名相同返回 ErrUsernameUnchanged
+func ChangeUsername(userID int64, newUsername string) error {
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var user User
+		if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if user.Username == newUsername {
+			return ErrUsernameUnchanged
+		}
+
+		if err := tx.Model(&User{}).Where("id = ?", userID).Update("username", newUsername).Error; err != nil {
+			if isUniqueViolation(err) {
+				return ErrUserAlreadyExists
+			}
+			return ErrUpdateUserFailed
+		}
+
+		return tx.Create(&UsernameHistory{
+			UserID:      userID,
+			OldUsername: user.Username,
+			NewUsername: newUsername,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	InvalidateUserCache(userID)
+	return nil
+}
+
+// ListUsernameHistory 查询用户的改名历史，按改名先后排序
+// 参数:
+//   - userID: 用户ID
+//
+// 返回:
+//   - []UsernameHistory: 改名历史
+//   - error: 操作错误信息
+func ListUsernameHistory(userID int64) ([]UsernameHistory, error) {
+	var histories []UsernameHistory
+	if err := DB.Where("user_id = ?", userID).Order("id ASC").Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
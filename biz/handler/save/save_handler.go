@@ -4,13 +4,18 @@ package save
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/url"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 
+	"novelai/pkg/errno"
 	"novelai/pkg/middleware"
 
+	db "novelai/biz/dal/db"
 	"novelai/biz/model/save"
 	svc "novelai/biz/service/save"
 )
@@ -41,31 +46,11 @@ func CreateSave(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 3. 解析 JWT 用户ID，类型兼容与校验
-	idVal, ok := c.Get(middleware.IdentityKey)
-	if !ok {
-		c.JSON(consts.StatusUnauthorized, &save.CreateSaveResponse{
-			Code:    401,
-			Message: "未登录或Token无效",
-		})
-		return
-	}
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
-		c.JSON(consts.StatusUnauthorized, &save.CreateSaveResponse{
-			Code:    401,
-			Message: "无法解析用户ID（JWT类型错误）",
-		})
-		return
-	}
-	if userId <= 0 {
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(consts.StatusUnauthorized, &save.CreateSaveResponse{
 			Code:    401,
-			Message: "用户ID无效",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -77,9 +62,18 @@ func CreateSave(ctx context.Context, c *app.RequestContext) {
 		SaveDescription: req.SaveDescription,
 		SaveData:        req.SaveData,
 		SaveType:        req.SaveType,
+		UserRole:        middleware.GetUserRole(ctx, c),
 	}
 	serviceResp, err := svc.Create(ctx, serviceReq)
 	if err != nil {
+		var quotaErr *errno.Errno
+		if errors.As(err, &quotaErr) {
+			c.JSON(consts.StatusForbidden, &save.CreateSaveResponse{
+				Code:    int32(quotaErr.Code),
+				Message: quotaErr.Message,
+			})
+			return
+		}
 		switch err.Error() {
 		case "请求参数不合法":
 			c.JSON(consts.StatusBadRequest, &save.CreateSaveResponse{
@@ -151,31 +145,11 @@ func GetSave(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 3. 解析 JWT 用户ID，类型兼容与校验
-	idVal, ok := c.Get(middleware.IdentityKey)
-	if !ok {
-		c.JSON(consts.StatusUnauthorized, &save.GetSaveResponse{
-			Code:    401,
-			Message: "未登录或Token无效",
-		})
-		return
-	}
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
-		c.JSON(consts.StatusUnauthorized, &save.GetSaveResponse{
-			Code:    401,
-			Message: "无法解析用户ID（JWT类型错误）",
-		})
-		return
-	}
-	if userId <= 0 {
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(consts.StatusUnauthorized, &save.GetSaveResponse{
 			Code:    401,
-			Message: "用户ID无效",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -259,31 +233,11 @@ func UpdateSave(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 3. 解析 JWT 用户ID，类型兼容与校验
-	idVal, ok := c.Get(middleware.IdentityKey)
-	if !ok {
-		c.JSON(consts.StatusUnauthorized, &save.UpdateSaveResponse{
-			Code:    401,
-			Message: "未登录或Token无效",
-		})
-		return
-	}
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
-		c.JSON(consts.StatusUnauthorized, &save.UpdateSaveResponse{
-			Code:    401,
-			Message: "无法解析用户ID（JWT类型错误）",
-		})
-		return
-	}
-	if userId <= 0 {
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(consts.StatusUnauthorized, &save.UpdateSaveResponse{
 			Code:    401,
-			Message: "用户ID无效",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -295,9 +249,18 @@ func UpdateSave(ctx context.Context, c *app.RequestContext) {
 		SaveName:        req.SaveName,
 		SaveDescription: req.SaveDescription,
 		SaveData:        req.SaveData,
+		UserRole:        middleware.GetUserRole(ctx, c),
 	}
-	_, err := svc.Update(ctx, serviceReq)
+	_, err = svc.Update(ctx, serviceReq)
 	if err != nil {
+		var quotaErr *errno.Errno
+		if errors.As(err, &quotaErr) {
+			c.JSON(consts.StatusForbidden, &save.UpdateSaveResponse{
+				Code:    int32(quotaErr.Code),
+				Message: quotaErr.Message,
+			})
+			return
+		}
 		switch err.Error() {
 		case "请求参数不合法":
 			c.JSON(consts.StatusBadRequest, &save.UpdateSaveResponse{
@@ -311,6 +274,12 @@ func UpdateSave(ctx context.Context, c *app.RequestContext) {
 				Message: "保存项不存在",
 			})
 			return
+		case "存档已被修改，版本冲突":
+			c.JSON(consts.StatusConflict, &save.UpdateSaveResponse{
+				Code:    409,
+				Message: "存档已被修改，请刷新后重试",
+			})
+			return
 		case "更新存档失败":
 			c.JSON(consts.StatusInternalServerError, &save.UpdateSaveResponse{
 				Code:    500,
@@ -367,31 +336,11 @@ func DeleteSave(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 3. 解析 JWT 用户ID，类型兼容与校验
-	idVal, ok := c.Get(middleware.IdentityKey)
-	if !ok {
-		c.JSON(consts.StatusUnauthorized, &save.DeleteSaveResponse{
-			Code:    401,
-			Message: "未登录或Token无效",
-		})
-		return
-	}
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
-		c.JSON(consts.StatusUnauthorized, &save.DeleteSaveResponse{
-			Code:    401,
-			Message: "无法解析用户ID（JWT类型错误）",
-		})
-		return
-	}
-	if userId <= 0 {
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(consts.StatusUnauthorized, &save.DeleteSaveResponse{
 			Code:    401,
-			Message: "用户ID无效",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -401,7 +350,7 @@ func DeleteSave(ctx context.Context, c *app.RequestContext) {
 		UserId: userId,
 		SaveId: req.SaveId,
 	}
-	_, err := svc.Delete(ctx, serviceReq)
+	_, err = svc.Delete(ctx, serviceReq)
 	if err != nil {
 		switch err.Error() {
 		case "请求参数不合法":
@@ -466,31 +415,11 @@ func ListSaves(ctx context.Context, c *app.RequestContext) {
 	}
 
 	// 3. 解析 JWT 用户ID，类型兼容与校验
-	idVal, ok := c.Get(middleware.IdentityKey)
-	if !ok {
-		c.JSON(consts.StatusUnauthorized, &save.ListSavesResponse{
-			Code:    401,
-			Message: "未登录或Token无效",
-		})
-		return
-	}
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
-		c.JSON(consts.StatusUnauthorized, &save.ListSavesResponse{
-			Code:    401,
-			Message: "无法解析用户ID（JWT类型错误）",
-		})
-		return
-	}
-	if userId <= 0 {
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(consts.StatusUnauthorized, &save.ListSavesResponse{
 			Code:    401,
-			Message: "用户ID无效",
+			Message: err.Error(),
 		})
 		return
 	}
@@ -542,3 +471,63 @@ func ListSaves(ctx context.Context, c *app.RequestContext) {
 // 5. 所有分支均结构化响应，便于前端统一处理
 // 6. 变量作用域最小化，避免全局变量和递归，圈复杂度低于 10
 
+
+// 导出保存
+// ExportSave 将存档导出为JSON文件下载，导出前校验存档归属
+// 参数: ctx 上下文，c Hertz请求上下文
+// 返回: 成功时为附件形式的JSON文件，失败时为JSON结构化响应
+func ExportSave(ctx context.Context, c *app.RequestContext) {
+	// 1. 绑定并校验 query 参数
+	req := new(save.GetSaveRequest)
+	if err := c.BindQuery(req); err != nil {
+		c.JSON(consts.StatusBadRequest, &save.GetSaveResponse{
+			Code:    400,
+			Message: "参数绑定失败: " + err.Error(),
+		})
+		return
+	}
+	if req.SaveId == "" {
+		c.JSON(consts.StatusBadRequest, &save.GetSaveResponse{
+			Code:    400,
+			Message: "缺少必需参数: save_id",
+		})
+		return
+	}
+
+	// 2. 解析 JWT 用户ID
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
+		c.JSON(consts.StatusUnauthorized, &save.GetSaveResponse{
+			Code:    401,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	// 3. 调用 service 层导出存档
+	data, fileName, err := svc.ExportSave(ctx, userId, req.SaveId)
+	if err != nil {
+		switch {
+		case errors.Is(err, svc.ErrInvalidRequest):
+			c.JSON(consts.StatusBadRequest, &save.GetSaveResponse{
+				Code:    400,
+				Message: "请求参数不合法",
+			})
+		case errors.Is(err, db.ErrSaveNotFound):
+			c.JSON(consts.StatusNotFound, &save.GetSaveResponse{
+				Code:    404,
+				Message: "保存项不存在",
+			})
+		default:
+			c.JSON(consts.StatusInternalServerError, &save.GetSaveResponse{
+				Code:    500,
+				Message: "服务器内部错误: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	// 4. 设置下载响应头并返回文件内容，filename 为 ASCII 兜底，filename* 携带原始文件名
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"; filename*=UTF-8''%s`, req.SaveId, url.PathEscape(fileName)))
+	c.Data(consts.StatusOK, "application/json; charset=utf-8", data)
+}
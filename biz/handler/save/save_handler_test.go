@@ -0,0 +1,130 @@
+package save
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	db "novelai/biz/dal/db"
+	svc "novelai/biz/service/save"
+	"novelai/pkg/middleware"
+	jwtImpl "novelai/pkg/middleware/jwt"
+	"novelai/pkg/utils/crypto"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupHandlerTestDB 初始化 handler 层测试数据库
+func setupHandlerTestDB(t *testing.T) {
+	var err error
+	db.DB, err = gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err, "初始化测试数据库失败")
+	require.NoError(t, db.DB.AutoMigrate(&db.Save{}, &db.User{}), "自动迁移数据表失败")
+	db.DB.Exec("DELETE FROM " + (db.Save{}).TableName())
+	db.DB.Exec("DELETE FROM " + db.TableNameUser)
+}
+
+// withSaveQuota 临时替换角色配额，测试结束后恢复
+func withSaveQuota(t *testing.T, role string, quota svc.SaveQuota) {
+	previous := svc.QuotaForRole(role)
+	svc.SetSaveQuota(role, quota)
+	t.Cleanup(func() { svc.SetSaveQuota(role, previous) })
+}
+
+// TestCreateSaveUsesRoleQuota 测试创建存档按令牌中的角色套用配额，会员可以超出普通用户上限
+func TestCreateSaveUsesRoleQuota(t *testing.T) {
+	setupHandlerTestDB(t)
+	withSaveQuota(t, svc.RoleNormal, svc.SaveQuota{MaxSaves: 1})
+	withSaveQuota(t, svc.RoleMember, svc.SaveQuota{MaxSaves: 3})
+
+	jwtMw, err := middleware.JwtMiddleware()
+	require.NoError(t, err)
+	engine := route.NewEngine(config.NewOptions(nil))
+	group := engine.Group("/api/save", jwtMw.MiddlewareFunc())
+	group.POST("/create", CreateSave)
+
+	normal, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: int64(1)})
+	require.NoError(t, err)
+	member, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: int64(2), jwtImpl.RoleKey: svc.RoleMember})
+	require.NoError(t, err)
+	create := func(token string) int {
+		payload := `{"save_name":"存档","save_data":"{}","save_type":"draft"}`
+		body := &ut.Body{Body: strings.NewReader(payload), Len: len(payload)}
+		return ut.PerformRequest(engine, http.MethodPost, "/api/save/create", body,
+			ut.Header{Key: "Authorization", Value: "Bearer " + token},
+			ut.Header{Key: "Content-Type", Value: "application/json"},
+		).Result().StatusCode()
+	}
+
+	assert.Equal(t, http.StatusOK, create(normal))
+	assert.Equal(t, http.StatusForbidden, create(normal), "普通用户超出数量上限应被拒")
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, create(member), "会员第%d个存档应按会员配额放行", i+1)
+	}
+	assert.Equal(t, http.StatusForbidden, create(member), "会员超出会员配额同样被拒")
+}
+
+// TestLoginMemberGetsMemberQuota 测试会员登录后令牌携带用户表中的角色，创建存档按会员配额放行
+func TestLoginMemberGetsMemberQuota(t *testing.T) {
+	setupHandlerTestDB(t)
+	withSaveQuota(t, svc.RoleNormal, svc.SaveQuota{MaxSaves: 1})
+	withSaveQuota(t, svc.RoleMember, svc.SaveQuota{MaxSaves: 2})
+
+	for _, u := range []*db.User{
+		{Username: "normal_user", Password: crypto.HashPassword("secret"), Email: "normal@example.com"},
+		{Username: "member_user", Password: crypto.HashPassword("secret"), Email: "member@example.com", Role: db.UserRoleMember},
+	} {
+		_, err := db.CreateUser(u)
+		require.NoError(t, err)
+	}
+
+	jwtMw, err := middleware.JwtMiddleware()
+	require.NoError(t, err)
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.POST("/api/user/login", jwtMw.LoginHandler)
+	group := engine.Group("/api/save", jwtMw.MiddlewareFunc())
+	group.POST("/create", CreateSave)
+
+	login := func(username string) string {
+		payload := `{"username":"` + username + `","password":"secret"}`
+		resp := ut.PerformRequest(engine, http.MethodPost, "/api/user/login",
+			&ut.Body{Body: strings.NewReader(payload), Len: len(payload)},
+			ut.Header{Key: "Content-Type", Value: "application/json"},
+		).Result()
+		require.Equal(t, http.StatusOK, resp.StatusCode(), string(resp.Body()))
+		var body struct {
+			Token string `json:"token"`
+		}
+		require.NoError(t, json.Unmarshal(resp.Body(), &body))
+		require.NotEmpty(t, body.Token)
+		return body.Token
+	}
+	create := func(token string) int {
+		payload := `{"save_name":"存档","save_data":"{}","save_type":"draft"}`
+		return ut.PerformRequest(engine, http.MethodPost, "/api/save/create",
+			&ut.Body{Body: strings.NewReader(payload), Len: len(payload)},
+			ut.Header{Key: "Authorization", Value: "Bearer " + token},
+			ut.Header{Key: "Content-Type", Value: "application/json"},
+		).Result().StatusCode()
+	}
+
+	normal := login("normal_user")
+	assert.Equal(t, http.StatusOK, create(normal))
+	assert.Equal(t, http.StatusForbidden, create(normal), "普通用户超出普通配额应被拒")
+
+	member := login("member_user")
+	assert.Equal(t, http.StatusOK, create(member))
+	assert.Equal(t, http.StatusOK, create(member), "会员登录后应按会员配额放行")
+	assert.Equal(t, http.StatusForbidden, create(member))
+}
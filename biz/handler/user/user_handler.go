@@ -93,18 +93,11 @@ func GetUser(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 	// 统一从 JWT 获取 userId，避免前端传递
-	idVal, _ := c.Get(middleware.IdentityKey)
-	// 兼容 float64/int64 类型，防止 interface conversion panic
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(constants.StatusUnauthorized, map[string]interface{}{
 			"code":    401,
-			"message": "无法解析用户ID（JWT类型错误）",
+			"message": err.Error(),
 		})
 		return
 	}
@@ -151,23 +144,16 @@ func UpdateUser(ctx context.Context, c *app.RequestContext) {
 		return
 	}
 	// 统一从 JWT 获取 userId，避免前端传递
-	idVal, _ := c.Get(middleware.IdentityKey)
-	// 兼容 float64/int64 类型，防止 interface conversion panic
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(constants.StatusUnauthorized, map[string]interface{}{
 			"code":    401,
-			"message": "无法解析用户ID（JWT类型错误）",
+			"message": err.Error(),
 		})
 		return
 	}
 	svc := service.NewUserService(ctx, c)
-	err := svc.UpdateUserProfile(userId, req)
+	err = svc.UpdateUserProfile(userId, req)
 	if err != nil {
 		if err == db.ErrUserNotFound {
 			c.JSON(constants.StatusOK, &userpb.UpdateUserResponse{
@@ -208,24 +194,17 @@ func ChangePassword(ctx context.Context, c *app.RequestContext) {
 	oldHash := generatePasswordHash(req.OldPassword)
 	newHash := generatePasswordHash(req.NewPassword)
 	// 获取用户ID
-	idVal, _ := c.Get(middleware.IdentityKey)
-	// 兼容 float64/int64 类型，防止 interface conversion panic
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(constants.StatusUnauthorized, map[string]interface{}{
 			"code":    401,
-			"message": "无法解析用户ID（JWT类型错误）",
+			"message": err.Error(),
 		})
 		return
 	}
 	// 调用服务
 	svc := service.NewUserService(ctx, c)
-	err := svc.UpdateUserPassword(userId, oldHash, newHash)
+	err = svc.UpdateUserPassword(userId, oldHash, newHash)
 	if err != nil {
 		if err == db.ErrInvalidPassword {
 			c.JSON(constants.StatusOK, &userpb.UpdateUserResponse{Code: 1002, Message: "旧密码错误"})
@@ -240,23 +219,16 @@ func ChangePassword(ctx context.Context, c *app.RequestContext) {
 // DeleteUser 删除当前用户（软删除）
 func DeleteUser(ctx context.Context, c *app.RequestContext) {
 	// 获取用户ID
-	idVal, _ := c.Get(middleware.IdentityKey)
-	// 兼容 float64/int64 类型，防止 interface conversion panic
-	var userId int64
-	switch v := idVal.(type) {
-	case float64:
-		userId = int64(v)
-	case int64:
-		userId = v
-	default:
+	userId, err := middleware.GetUserID(c)
+	if err != nil {
 		c.JSON(constants.StatusUnauthorized, map[string]interface{}{
 			"code":    401,
-			"message": "无法解析用户ID（JWT类型错误）",
+			"message": err.Error(),
 		})
 		return
 	}
 	svc := service.NewUserService(ctx, c)
-	err := svc.DeleteUser(userId)
+	err = svc.DeleteUser(userId)
 	if err != nil {
 		if err == db.ErrUserNotFound {
 			c.JSON(constants.StatusOK, &userpb.UpdateUserResponse{Code: 1003, Message: "用户不存在"})
@@ -17,12 +17,13 @@ func RegisterRoutes(r *server.Hertz) {
 		panic("JWT中间件初始化失败: " + err.Error())
 	}
 	saveGroup := r.Group("/api/save")
-	saveGroup.Use(jwtMw.MiddlewareFunc())
+	saveGroup.Use(jwtMw.MiddlewareFunc(), middleware.ActiveTracker())
 	{
-		saveGroup.POST("/create", handler.CreateSave)
+		saveGroup.POST("/create", middleware.MaxBodySize(middleware.DefaultMaxBodySize), handler.CreateSave)
 		saveGroup.GET("/get", handler.GetSave)
-		saveGroup.PUT("/update", handler.UpdateSave)
+		saveGroup.PUT("/update", middleware.MaxBodySize(middleware.DefaultMaxBodySize), handler.UpdateSave)
 		saveGroup.DELETE("/delete", handler.DeleteSave)
 		saveGroup.GET("/list", handler.ListSaves)
+		saveGroup.GET("/export", handler.ExportSave)
 	}
 }
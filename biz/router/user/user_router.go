@@ -20,8 +20,8 @@ func RegisterRoutes(r *server.Hertz) {
 	{
 		userGroup.POST("/register", handler.Register)
 		userGroup.POST("/login", jwtMw.LoginHandler)
-		userGroup.GET("/refresh", jwtMw.RefreshHandler)
-		userGroup.Use(jwtMw.MiddlewareFunc())
+		userGroup.GET("/refresh", middleware.RefreshHandler(jwtMw))
+		userGroup.Use(jwtMw.MiddlewareFunc(), middleware.ActiveTracker())
 		// 用户登出
 		userGroup.POST("/logout", jwtMw.LogoutHandler)
 		// 用户信息与修改
@@ -0,0 +1,134 @@
+// save_diff.go 存档差异对比，对两份 JSON 存档数据做结构化比较
+package save
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	db "novelai/biz/dal/db"
+)
+
+// 差异类型
+const (
+	DiffOpAdded    = "added"    // 新增字段
+	DiffOpRemoved  = "removed"  // 删除字段
+	DiffOpModified = "modified" // 修改字段
+)
+
+// ErrSaveDataNotJSON 存档数据不是合法的 JSON，无法做结构化对比
+var ErrSaveDataNotJSON = errors.New("存档数据不是合法的JSON")
+
+// SaveDiffEntry 单个字段的差异
+// Path 为字段路径，如 "player.items[2].name"，根节点为 "$"
+type SaveDiffEntry struct {
+	Path     string      // 字段路径
+	Op       string      // 差异类型：added/removed/modified
+	OldValue interface{} // 存档A中的值，新增时为nil
+	NewValue interface{} // 存档B中的值，删除时为nil
+}
+
+// SaveDiff 两个存档之间的差异
+type SaveDiff struct {
+	SaveIdA string          // 对比基准存档ID
+	SaveIdB string          // 对比目标存档ID
+	Entries []SaveDiffEntry // 差异条目，按字段路径逐段排序：对象键按字典序，数组下标按数值
+}
+
+// DiffSaves 对比同一用户的两个存档，返回从存档A到存档B的字段级差异
+// ctx: 上下文，userId: 用户ID，saveIdA/saveIdB: 两个存档ID
+// 返回: 差异结果和错误；任一存档不属于该用户时返回 db.ErrSaveNotFound，数据不是 JSON 时返回 ErrSaveDataNotJSON
+func DiffSaves(ctx context.Context, userId int64, saveIdA, saveIdB string) (*SaveDiff, error) {
+	if userId <= 0 || saveIdA == "" || saveIdB == "" {
+		return nil, ErrInvalidRequest
+	}
+	dataA, err := loadSaveJSON(userId, saveIdA)
+	if err != nil {
+		return nil, err
+	}
+	dataB, err := loadSaveJSON(userId, saveIdB)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SaveDiff{SaveIdA: saveIdA, SaveIdB: saveIdB, Entries: []SaveDiffEntry{}}
+	diffJSON("$", dataA, dataB, &diff.Entries)
+	return diff, nil
+}
+
+// loadSaveJSON 查询存档并校验归属，解析其 JSON 数据
+func loadSaveJSON(userId int64, saveId string) (interface{}, error) {
+	dbSave, err := db.QuerySavesBySaveID(saveId)
+	if err != nil {
+		return nil, err
+	}
+	if dbSave.UserID != userId {
+		return nil, db.ErrSaveNotFound
+	}
+	var data interface{}
+	if err := json.Unmarshal([]byte(dbSave.SaveData), &data); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrSaveDataNotJSON, saveId)
+	}
+	return data, nil
+}
+
+// diffJSON 递归比较两个 JSON 值，差异追加到 entries
+// 对象按键比较，数组按下标比较，类型不同或标量不等视为修改；
+// 对象键按字典序、数组按下标顺序遍历，追加的条目即按路径逐段有序，避免 "[10]" 排在 "[2]" 之前
+func diffJSON(path string, a, b interface{}, entries *[]SaveDiffEntry) {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(va)+len(vb))
+		for key := range va {
+			keys = append(keys, key)
+		}
+		for key := range vb {
+			if _, exists := va[key]; !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := path + "." + key
+			valueA, inA := va[key]
+			valueB, inB := vb[key]
+			switch {
+			case !inB:
+				*entries = append(*entries, SaveDiffEntry{Path: childPath, Op: DiffOpRemoved, OldValue: valueA})
+			case !inA:
+				*entries = append(*entries, SaveDiffEntry{Path: childPath, Op: DiffOpAdded, NewValue: valueB})
+			default:
+				diffJSON(childPath, valueA, valueB, entries)
+			}
+		}
+		return
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(va) || i < len(vb); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(vb):
+				*entries = append(*entries, SaveDiffEntry{Path: childPath, Op: DiffOpRemoved, OldValue: va[i]})
+			case i >= len(va):
+				*entries = append(*entries, SaveDiffEntry{Path: childPath, Op: DiffOpAdded, NewValue: vb[i]})
+			default:
+				diffJSON(childPath, va[i], vb[i], entries)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*entries = append(*entries, SaveDiffEntry{Path: path, Op: DiffOpModified, OldValue: a, NewValue: b})
+	}
+}
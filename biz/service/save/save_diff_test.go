@@ -0,0 +1,89 @@
+package save
+
+import (
+	"context"
+	"testing"
+
+	db "novelai/biz/dal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createDiffTestSave 创建指定数据的测试存档
+func createDiffTestSave(t *testing.T, userID int64, data string) string {
+	resp, err := Create(context.Background(), &CreateSaveServiceRequest{
+		UserId:   userID,
+		SaveName: "对比存档",
+		SaveData: data,
+		SaveType: "draft",
+	})
+	require.NoError(t, err, "创建测试存档失败")
+	return resp.SaveId
+}
+
+// TestDiffSaves 测试两个已知差异的 JSON 存档产出预期的 diff 条目
+func TestDiffSaves(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+
+	saveA := createDiffTestSave(t, 1, `{"chapter": 1, "player": {"name": "艾琳", "hp": 100, "items": ["剑", "盾"]}, "flags": {"met_king": true}}`)
+	saveB := createDiffTestSave(t, 1, `{"chapter": 2, "player": {"name": "艾琳", "hp": 80, "items": ["剑", "弓", "药水"], "level": 3}, "flags": {}}`)
+
+	diff, err := DiffSaves(ctx, 1, saveA, saveB)
+	require.NoError(t, err)
+	assert.Equal(t, saveA, diff.SaveIdA)
+	assert.Equal(t, saveB, diff.SaveIdB)
+
+	want := []SaveDiffEntry{
+		{Path: "$.chapter", Op: DiffOpModified, OldValue: float64(1), NewValue: float64(2)},
+		{Path: "$.flags.met_king", Op: DiffOpRemoved, OldValue: true},
+		{Path: "$.player.hp", Op: DiffOpModified, OldValue: float64(100), NewValue: float64(80)},
+		{Path: "$.player.items[1]", Op: DiffOpModified, OldValue: "盾", NewValue: "弓"},
+		{Path: "$.player.items[2]", Op: DiffOpAdded, NewValue: "药水"},
+		{Path: "$.player.level", Op: DiffOpAdded, NewValue: float64(3)},
+	}
+	assert.Equal(t, want, diff.Entries)
+
+	// 相同存档无差异
+	same, err := DiffSaves(ctx, 1, saveA, saveA)
+	require.NoError(t, err)
+	assert.Empty(t, same.Entries)
+}
+
+// TestDiffSavesArrayOrder 测试超过10个元素的数组差异按下标数值排序
+func TestDiffSavesArrayOrder(t *testing.T) {
+	setupServiceTestDB(t)
+
+	saveA := createDiffTestSave(t, 1, `{"log": [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11], "tag": "a"}`)
+	saveB := createDiffTestSave(t, 1, `{"log": [0, 1, -2, 3, 4, 5, 6, 7, 8, 9, -10, 11, 12], "tag": "b"}`)
+
+	diff, err := DiffSaves(context.Background(), 1, saveA, saveB)
+	require.NoError(t, err)
+	want := []SaveDiffEntry{
+		{Path: "$.log[2]", Op: DiffOpModified, OldValue: float64(2), NewValue: float64(-2)},
+		{Path: "$.log[10]", Op: DiffOpModified, OldValue: float64(10), NewValue: float64(-10)},
+		{Path: "$.log[12]", Op: DiffOpAdded, NewValue: float64(12)},
+		{Path: "$.tag", Op: DiffOpModified, OldValue: "a", NewValue: "b"},
+	}
+	assert.Equal(t, want, diff.Entries)
+}
+
+// TestDiffSavesValidation 测试归属校验与非 JSON 数据
+func TestDiffSavesValidation(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+
+	own := createDiffTestSave(t, 1, `{"chapter": 1}`)
+	others := createDiffTestSave(t, 2, `{"chapter": 2}`)
+	text := createDiffTestSave(t, 1, `纯文本存档`)
+
+	_, err := DiffSaves(ctx, 1, own, others)
+	assert.ErrorIs(t, err, db.ErrSaveNotFound)
+
+	_, err = DiffSaves(ctx, 1, own, text)
+	assert.ErrorIs(t, err, ErrSaveDataNotJSON)
+
+	_, err = DiffSaves(ctx, 1, own, "")
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
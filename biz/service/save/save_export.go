@@ -0,0 +1,90 @@
+// save_export.go 将存档导出为可下载的JSON文件，并支持从导出文件重新导入
+package save
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// saveExportVersion 存档导出文件的格式版本
+const saveExportVersion = 1
+
+// ErrInvalidSaveExport 导入的文件不是合法的存档导出文件
+var ErrInvalidSaveExport = errors.New("存档导出文件格式不合法")
+
+// SaveExport 存档导出文件格式，包含存档内容与元数据
+type SaveExport struct {
+	Version         int    `json:"version"`          // 格式版本
+	SaveId          string `json:"save_id"`          // 原存档ID，导入时重新生成
+	SaveName        string `json:"save_name"`        // 存档名称
+	SaveDescription string `json:"save_description"` // 存档描述
+	SaveType        string `json:"save_type"`        // 存档类型
+	SaveData        string `json:"save_data"`        // 存档数据
+	CreatedAt       int64  `json:"created_at"`       // 原存档创建时间(unix时间戳)
+	UpdatedAt       int64  `json:"updated_at"`       // 原存档更新时间(unix时间戳)
+	ExportedAt      int64  `json:"exported_at"`      // 导出时间(unix时间戳)
+}
+
+// ExportSave 导出存档为完整的JSON文件
+// ctx: 上下文，userId: 用户ID，saveId: 存档ID
+// 返回: 文件内容、建议文件名和错误；存档不属于该用户时返回 db.ErrSaveNotFound
+func ExportSave(ctx context.Context, userId int64, saveId string) ([]byte, string, error) {
+	resp, err := Get(ctx, &GetSaveServiceRequest{UserId: userId, SaveId: saveId})
+	if err != nil {
+		return nil, "", err
+	}
+	s := resp.Save
+	data, err := json.MarshalIndent(SaveExport{
+		Version:         saveExportVersion,
+		SaveId:          s.SaveId,
+		SaveName:        s.SaveName,
+		SaveDescription: s.SaveDescription,
+		SaveType:        s.SaveType,
+		SaveData:        s.SaveData,
+		CreatedAt:       s.CreatedAt,
+		UpdatedAt:       s.UpdatedAt,
+		ExportedAt:      nowUnix(),
+	}, "", "  ")
+	if err != nil {
+		return nil, "", fmt.Errorf("序列化存档失败: %w", err)
+	}
+	return data, exportFileName(s.SaveName, s.SaveId), nil
+}
+
+// ImportSave 从导出文件创建新存档，存档ID重新生成，配额校验与普通存档一致
+// ctx: 上下文，userId: 用户ID，data: 导出文件内容，userRole: 用户角色
+// 返回: 新存档的创建结果和错误；文件格式不合法时返回 ErrInvalidSaveExport
+func ImportSave(ctx context.Context, userId int64, data []byte, userRole string) (*CreateSaveServiceResponse, error) {
+	var export SaveExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSaveExport, err)
+	}
+	if export.Version != saveExportVersion {
+		return nil, fmt.Errorf("%w: 不支持的版本 %d", ErrInvalidSaveExport, export.Version)
+	}
+	return Create(ctx, &CreateSaveServiceRequest{
+		UserId:          userId,
+		SaveName:        export.SaveName,
+		SaveDescription: export.SaveDescription,
+		SaveData:        export.SaveData,
+		SaveType:        export.SaveType,
+		UserRole:        userRole,
+	})
+}
+
+// exportFileName 生成导出文件名，去掉文件名中不允许的字符，名称为空时使用存档ID
+func exportFileName(saveName, saveId string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(saveName))
+	if name == "" {
+		name = saveId
+	}
+	return name + ".json"
+}
@@ -0,0 +1,58 @@
+package save
+
+import (
+	"context"
+	"testing"
+
+	db "novelai/biz/dal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestExportImportSave 测试导出的存档再次导入后能还原出等价存档
+func TestExportImportSave(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+	saveID := createServiceTestSave(t, 1)
+
+	data, fileName, err := ExportSave(ctx, 1, saveID)
+	require.NoError(t, err)
+	assert.Equal(t, "原存档.json", fileName)
+	assert.Contains(t, string(data), saveID, "导出内容应包含元数据")
+
+	resp, err := ImportSave(ctx, 1, data, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, saveID, resp.SaveId, "导入后应生成新的 save_id")
+
+	original, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: saveID})
+	require.NoError(t, err)
+	imported, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: resp.SaveId})
+	require.NoError(t, err)
+	assert.Equal(t, original.Save.SaveName, imported.Save.SaveName)
+	assert.Equal(t, original.Save.SaveDescription, imported.Save.SaveDescription)
+	assert.Equal(t, original.Save.SaveType, imported.Save.SaveType)
+	assert.Equal(t, original.Save.SaveData, imported.Save.SaveData)
+}
+
+// TestExportSaveErrors 测试导出校验归属与导入格式校验
+func TestExportSaveErrors(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+	saveID := createServiceTestSave(t, 1)
+
+	_, _, err := ExportSave(ctx, 2, saveID)
+	assert.ErrorIs(t, err, db.ErrSaveNotFound, "不能导出其他用户的存档")
+
+	_, err = ImportSave(ctx, 1, []byte("not json"), "")
+	assert.ErrorIs(t, err, ErrInvalidSaveExport)
+
+	_, err = ImportSave(ctx, 1, []byte(`{"version":99,"save_name":"a","save_data":"b","save_type":"draft"}`), "")
+	assert.ErrorIs(t, err, ErrInvalidSaveExport)
+}
+
+// TestExportFileName 测试导出文件名去掉非法字符
+func TestExportFileName(t *testing.T) {
+	assert.Equal(t, "第一章_草稿.json", exportFileName("第一章/草稿", "save-1"))
+	assert.Equal(t, "save-1.json", exportFileName("  ", "save-1"))
+}
@@ -0,0 +1,72 @@
+// save_generation.go 将整套生成结果打包为一个存档，并支持从存档还原
+package save
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"novelai/pkg/middleware"
+	"novelai/pkg/wf/storys/background"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SaveTypeSetting 设定套装存档类型，存档数据为序列化后的整套生成结果
+const SaveTypeSetting = "setting"
+
+// generationSaveVersion 设定套装存档数据的格式版本
+const generationSaveVersion = 1
+
+// ErrNotSettingSave 存档不是设定套装类型，无法还原为生成结果
+var ErrNotSettingSave = errors.New("存档不是设定套装类型")
+
+// generationSaveData 设定套装存档数据格式
+type generationSaveData struct {
+	Version int              `json:"version"` // 格式版本
+	Story   background.Story `json:"story"`   // 生成结果
+}
+
+// SaveGenerationAsSave 将整套生成结果（世界观、规则、背景）序列化后保存为一个设定套装存档
+// ctx: 上下文，c: 请求上下文，用于从 JWT 中读取用户角色（为空时按普通用户处理），userId: 用户ID，result: 生成结果，saveName: 存档名称
+// 返回: 创建结果和错误，配额按用户角色校验，与普通存档一致
+func SaveGenerationAsSave(ctx context.Context, c *app.RequestContext, userId int64, result *background.Story, saveName string) (*CreateSaveServiceResponse, error) {
+	if result == nil {
+		return nil, ErrInvalidRequest
+	}
+	var userRole string
+	if c != nil {
+		userRole = middleware.GetUserRole(ctx, c)
+	}
+	data, err := json.Marshal(generationSaveData{Version: generationSaveVersion, Story: *result})
+	if err != nil {
+		return nil, fmt.Errorf("序列化生成结果失败: %w", err)
+	}
+	return Create(ctx, &CreateSaveServiceRequest{
+		UserId:          userId,
+		SaveName:        saveName,
+		SaveDescription: fmt.Sprintf("设定套装：%d 个世界观，%d 条规则，%d 个背景", len(result.WorldViews), len(result.Rules), len(result.Backgrounds)),
+		SaveData:        string(data),
+		SaveType:        SaveTypeSetting,
+		UserRole:        userRole,
+	})
+}
+
+// LoadGenerationFromSave 读取设定套装存档并还原为生成结果
+// ctx: 上下文，userId: 用户ID，saveId: 存档ID
+// 返回: 生成结果和错误，存档类型不是设定套装时返回 ErrNotSettingSave
+func LoadGenerationFromSave(ctx context.Context, userId int64, saveId string) (*background.Story, error) {
+	resp, err := Get(ctx, &GetSaveServiceRequest{UserId: userId, SaveId: saveId})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Save.SaveType != SaveTypeSetting {
+		return nil, ErrNotSettingSave
+	}
+	var data generationSaveData
+	if err := json.Unmarshal([]byte(resp.Save.SaveData), &data); err != nil {
+		return nil, fmt.Errorf("解析设定套装存档失败: %w", err)
+	}
+	return &data.Story, nil
+}
@@ -0,0 +1,76 @@
+package save
+
+import (
+	"context"
+	"testing"
+
+	jwtImpl "novelai/pkg/middleware/jwt"
+	"novelai/pkg/wf/storys/background"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/hertz-contrib/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSaveGenerationAsSave 测试生成结果打包成存档后能读回还原出结构
+func TestSaveGenerationAsSave(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+
+	story := &background.Story{
+		WorldViews: []background.Worldview{{
+			ID: 1, Name: "星海帝国", Description: "横跨银河的帝国", Tag: "科幻",
+			Children: []background.Worldview{{ID: 2, Name: "边境星域", ParentID: 1}},
+		}},
+		Rules:       []background.Rule{{ID: 1, WorldviewID: 1, Name: "跃迁法则", Description: "跃迁需要星门"}},
+		Backgrounds: []background.Background{{ID: 1, WorldviewID: 1, Name: "帝都", Description: "帝国的心脏"}},
+	}
+
+	resp, err := SaveGenerationAsSave(ctx, nil, 1, story, "星海设定")
+	require.NoError(t, err)
+
+	got, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: resp.SaveId})
+	require.NoError(t, err)
+	assert.Equal(t, SaveTypeSetting, got.Save.SaveType)
+	assert.Equal(t, "星海设定", got.Save.SaveName)
+
+	restored, err := LoadGenerationFromSave(ctx, 1, resp.SaveId)
+	require.NoError(t, err)
+	assert.Equal(t, story, restored, "还原的生成结果应与原结果一致")
+
+	_, err = LoadGenerationFromSave(ctx, 2, resp.SaveId)
+	assert.Error(t, err, "其他用户不能读取该存档")
+}
+
+// TestLoadGenerationFromSaveWrongType 测试非设定套装存档不能还原
+func TestLoadGenerationFromSaveWrongType(t *testing.T) {
+	setupServiceTestDB(t)
+	saveID := createServiceTestSave(t, 1)
+
+	_, err := LoadGenerationFromSave(context.Background(), 1, saveID)
+	assert.ErrorIs(t, err, ErrNotSettingSave)
+
+	_, err = SaveGenerationAsSave(context.Background(), nil, 1, nil, "空")
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+// TestSaveGenerationAsSaveUsesRoleQuota 测试按请求上下文中 JWT 的角色套用配额
+func TestSaveGenerationAsSaveUsesRoleQuota(t *testing.T) {
+	setupServiceTestDB(t)
+	withSaveQuota(t, RoleNormal, SaveQuota{MaxSaves: 1})
+	withSaveQuota(t, RoleMember, SaveQuota{MaxSaves: 2})
+	ctx := context.Background()
+	story := &background.Story{WorldViews: []background.Worldview{{Name: "星海帝国"}}}
+
+	normal := app.NewContext(0)
+	_, err := SaveGenerationAsSave(ctx, normal, 1, story, "设定1")
+	require.NoError(t, err)
+	_, err = SaveGenerationAsSave(ctx, normal, 1, story, "设定2")
+	assert.ErrorIs(t, err, ErrSaveCountExceeded, "普通用户超出普通配额应被拒")
+
+	member := app.NewContext(0)
+	member.Set("JWT_PAYLOAD", jwt.MapClaims{jwtImpl.RoleKey: RoleMember})
+	_, err = SaveGenerationAsSave(ctx, member, 1, story, "设定2")
+	require.NoError(t, err, "会员应按会员配额放行")
+}
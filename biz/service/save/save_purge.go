@@ -0,0 +1,55 @@
+// save_purge.go 过期存档清理，按类型软删除长时间未更新的临时存档
+package save
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "novelai/biz/dal/db"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// ErrPurgeTypesRequired 清理时未指定存档类型，为避免误删用户重要存档必须显式指定
+var ErrPurgeTypesRequired = errors.New("清理存档必须指定存档类型")
+
+// PurgeStaleSaves 软删除超过指定时长未更新且属于指定类型的存档
+// ctx: 上下文，olderThan: 未更新时长，types: 存档类型列表（如 checkpoint），不能为空
+// 返回: 清理数量和错误
+func PurgeStaleSaves(ctx context.Context, olderThan time.Duration, types []string) (int64, error) {
+	if olderThan <= 0 {
+		return 0, ErrInvalidRequest
+	}
+	if len(types) == 0 {
+		return 0, ErrPurgeTypesRequired
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-olderThan).Unix()
+	return db.MarkStaleSavesDeleted(cutoff, types)
+}
+
+// RunSavePurger 按固定间隔执行过期存档清理，直到 ctx 取消
+// 供定时任务调度使用，单次清理失败只记录日志，不中断后续调度
+func RunSavePurger(ctx context.Context, interval, olderThan time.Duration, types []string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := PurgeStaleSaves(ctx, olderThan, types)
+			if err != nil {
+				hlog.CtxErrorf(ctx, "清理过期存档失败: %v", err)
+				continue
+			}
+			if purged > 0 {
+				hlog.CtxInfof(ctx, "已清理过期存档 %d 个, 类型=%v", purged, types)
+			}
+		}
+	}
+}
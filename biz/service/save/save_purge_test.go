@@ -0,0 +1,72 @@
+package save
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "novelai/biz/dal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTypedServiceTestSave 创建指定类型的测试存档，并可将更新时间回拨
+func createTypedServiceTestSave(t *testing.T, userID int64, saveType string, age time.Duration) string {
+	resp, err := Create(context.Background(), &CreateSaveServiceRequest{
+		UserId:   userID,
+		SaveName: saveType + "存档",
+		SaveData: `{"chapter":1}`,
+		SaveType: saveType,
+	})
+	require.NoError(t, err, "创建测试存档失败")
+	if age > 0 {
+		err = db.DB.Model(&db.Save{}).Where("save_id = ?", resp.SaveId).
+			UpdateColumn("updated_at", time.Now().Add(-age).Unix()).Error
+		require.NoError(t, err, "回拨存档更新时间失败")
+	}
+	return resp.SaveId
+}
+
+// TestPurgeStaleSaves 测试只清理过期的指定类型存档
+func TestPurgeStaleSaves(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+
+	oldCheckpoint := createTypedServiceTestSave(t, 1, "checkpoint", 48*time.Hour)
+	newCheckpoint := createTypedServiceTestSave(t, 1, "checkpoint", 0)
+	oldDraft := createTypedServiceTestSave(t, 1, "draft", 48*time.Hour)
+
+	purged, err := PurgeStaleSaves(ctx, 24*time.Hour, []string{"checkpoint"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged, "只应清理一个过期的 checkpoint 存档")
+
+	var dbSave db.Save
+	require.NoError(t, db.DB.Where("save_id = ?", oldCheckpoint).First(&dbSave).Error)
+	assert.Equal(t, db.SaveStatusDeleted, dbSave.SaveStatus, "过期 checkpoint 应被软删除")
+
+	_, err = Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: oldCheckpoint})
+	assert.Error(t, err, "已软删除的存档不应再能读取")
+
+	for _, id := range []string{newCheckpoint, oldDraft} {
+		dbSave, err := db.QuerySavesBySaveID(id)
+		require.NoError(t, err)
+		assert.Equal(t, db.SaveStatusActive, dbSave.SaveStatus, "未过期或非指定类型的存档应保留")
+	}
+
+	// 重复清理不应再计数已删除的存档
+	purged, err = PurgeStaleSaves(ctx, 24*time.Hour, []string{"checkpoint"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+}
+
+// TestPurgeStaleSavesInvalidArgs 测试清理参数校验
+func TestPurgeStaleSavesInvalidArgs(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := PurgeStaleSaves(ctx, time.Hour, nil)
+	assert.ErrorIs(t, err, ErrPurgeTypesRequired)
+
+	_, err = PurgeStaleSaves(ctx, 0, []string{"checkpoint"})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
@@ -0,0 +1,90 @@
+// save_quota.go 存档配额控制，按用户角色限制存档数量与存档内容总字节数
+package save
+
+import (
+	"sync"
+
+	db "novelai/biz/dal/db"
+	"novelai/pkg/errno"
+)
+
+// 用户角色，决定适用的存档配额，取值与用户表的 role 字段一致
+const (
+	RoleNormal = db.UserRoleNormal // 普通用户
+	RoleMember = db.UserRoleMember // 会员用户
+)
+
+// SaveQuota 存档配额
+// MaxSaves: 最多存档数量，小于等于0表示不限制
+// MaxBytes: 存档内容总字节数上限，小于等于0表示不限制
+type SaveQuota struct {
+	MaxSaves int64
+	MaxBytes int64
+}
+
+// 配额超限错误，handler 层可通过 errors.As 取出错误码
+var (
+	ErrSaveCountExceeded = errno.QuotaExceededError("存档数量已达上限")
+	ErrSaveSizeExceeded  = errno.QuotaExceededError("存档存储空间不足")
+)
+
+var (
+	quotaMutex sync.RWMutex
+	// saveQuotas 各角色的存档配额，未配置的角色按普通用户处理
+	saveQuotas = map[string]SaveQuota{
+		RoleNormal: {MaxSaves: 50, MaxBytes: 20 << 20},
+		RoleMember: {MaxSaves: 500, MaxBytes: 200 << 20},
+	}
+)
+
+// SetSaveQuota 设置指定角色的存档配额
+// role: 用户角色，quota: 配额
+func SetSaveQuota(role string, quota SaveQuota) {
+	quotaMutex.Lock()
+	defer quotaMutex.Unlock()
+	saveQuotas[role] = quota
+}
+
+// QuotaForRole 获取指定角色的存档配额，未知或空角色返回普通用户配额
+func QuotaForRole(role string) SaveQuota {
+	quotaMutex.RLock()
+	defer quotaMutex.RUnlock()
+	if quota, ok := saveQuotas[role]; ok {
+		return quota
+	}
+	return saveQuotas[RoleNormal]
+}
+
+// checkSaveQuota 检查用户再新增 addBytes 字节的存档后是否超出配额
+// 返回: 超出数量上限返回 ErrSaveCountExceeded，超出字节配额返回 ErrSaveSizeExceeded
+func checkSaveQuota(userID int64, role string, addBytes int64) error {
+	quota := QuotaForRole(role)
+	if quota.MaxSaves > 0 {
+		count, err := db.CountSavesByUser(userID)
+		if err != nil {
+			return err
+		}
+		if count >= quota.MaxSaves {
+			return ErrSaveCountExceeded
+		}
+	}
+	return checkSaveSizeQuota(userID, role, addBytes)
+}
+
+// checkSaveSizeQuota 检查用户存档内容再增加 addBytes 字节后是否超出字节配额
+// addBytes 小于等于0（如更新后存档变小）时不检查
+// 返回: 超出字节配额返回 ErrSaveSizeExceeded
+func checkSaveSizeQuota(userID int64, role string, addBytes int64) error {
+	quota := QuotaForRole(role)
+	if quota.MaxBytes <= 0 || addBytes <= 0 {
+		return nil
+	}
+	used, err := db.SumSaveDataSizeByUser(userID)
+	if err != nil {
+		return err
+	}
+	if used+addBytes > quota.MaxBytes {
+		return ErrSaveSizeExceeded
+	}
+	return nil
+}
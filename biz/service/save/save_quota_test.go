@@ -0,0 +1,99 @@
+package save
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"novelai/pkg/errno"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withSaveQuota 临时替换角色配额，测试结束后恢复
+func withSaveQuota(t *testing.T, role string, quota SaveQuota) {
+	previous := QuotaForRole(role)
+	SetSaveQuota(role, quota)
+	t.Cleanup(func() { SetSaveQuota(role, previous) })
+}
+
+// TestCreateSaveCountQuota 测试达到存档数量上限后创建被拒
+func TestCreateSaveCountQuota(t *testing.T) {
+	setupServiceTestDB(t)
+	withSaveQuota(t, RoleNormal, SaveQuota{MaxSaves: 2})
+	withSaveQuota(t, RoleMember, SaveQuota{MaxSaves: 3})
+	ctx := context.Background()
+
+	createServiceTestSave(t, 1)
+	createServiceTestSave(t, 1)
+
+	_, err := Create(ctx, &CreateSaveServiceRequest{
+		UserId: 1, SaveName: "超额存档", SaveData: "{}", SaveType: "draft",
+	})
+	assert.ErrorIs(t, err, ErrSaveCountExceeded)
+	var e *errno.Errno
+	require.True(t, errors.As(err, &e), "配额错误应为 errno 类型")
+	assert.Equal(t, 10004, e.Code)
+
+	// 会员配额更高，同样数量下仍可创建
+	_, err = Create(ctx, &CreateSaveServiceRequest{
+		UserId: 1, SaveName: "会员存档", SaveData: "{}", SaveType: "draft", UserRole: RoleMember,
+	})
+	assert.NoError(t, err)
+
+	// 其他用户不受影响
+	createServiceTestSave(t, 2)
+}
+
+// TestCreateSaveSizeQuota 测试超出字节配额后创建被拒
+func TestCreateSaveSizeQuota(t *testing.T) {
+	setupServiceTestDB(t)
+	withSaveQuota(t, RoleNormal, SaveQuota{MaxBytes: 32})
+	ctx := context.Background()
+
+	_, err := Create(ctx, &CreateSaveServiceRequest{
+		UserId: 1, SaveName: "小存档", SaveData: strings.Repeat("a", 20), SaveType: "draft",
+	})
+	require.NoError(t, err)
+
+	_, err = Create(ctx, &CreateSaveServiceRequest{
+		UserId: 1, SaveName: "大存档", SaveData: strings.Repeat("b", 13), SaveType: "draft",
+	})
+	assert.ErrorIs(t, err, ErrSaveSizeExceeded)
+
+	// 恰好用满配额允许创建
+	_, err = Create(ctx, &CreateSaveServiceRequest{
+		UserId: 1, SaveName: "刚好用满", SaveData: strings.Repeat("c", 12), SaveType: "draft",
+	})
+	assert.NoError(t, err)
+}
+
+// TestUpdateSaveSizeQuota 测试更新存档时按新旧内容的字节差检查配额
+func TestUpdateSaveSizeQuota(t *testing.T) {
+	setupServiceTestDB(t)
+	withSaveQuota(t, RoleNormal, SaveQuota{MaxBytes: 32})
+	withSaveQuota(t, RoleMember, SaveQuota{MaxBytes: 64})
+	ctx := context.Background()
+
+	resp, err := Create(ctx, &CreateSaveServiceRequest{
+		UserId: 1, SaveName: "小存档", SaveData: strings.Repeat("a", 10), SaveType: "draft",
+	})
+	require.NoError(t, err)
+	update := func(data, role string) error {
+		_, err := Update(ctx, &UpdateSaveServiceRequest{
+			UserId: 1, SaveId: resp.SaveId, SaveName: "小存档", SaveData: data, SaveType: "draft", UserRole: role,
+		})
+		return err
+	}
+
+	assert.ErrorIs(t, update(strings.Repeat("b", 33), RoleNormal), ErrSaveSizeExceeded, "更新后超出字节配额应被拒")
+	require.NoError(t, update(strings.Repeat("b", 32), RoleNormal), "恰好用满配额允许更新")
+	assert.NoError(t, update(strings.Repeat("c", 5), RoleNormal), "缩小存档不受配额限制")
+	assert.NoError(t, update(strings.Repeat("d", 60), RoleMember), "会员按会员配额检查")
+
+	got, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: resp.SaveId})
+	require.NoError(t, err)
+	assert.Len(t, got.Save.SaveData, 60)
+}
@@ -20,6 +20,7 @@ type CreateSaveServiceRequest struct {
 	SaveDescription string // 保存描述
 	SaveData        string // 保存数据
 	SaveType        string // 保存类型
+	UserRole        string // 用户角色，决定存档配额，为空按普通用户处理
 }
 
 // CreateSaveServiceResponse 创建保存业务返回值
@@ -43,6 +44,10 @@ func Create(ctx context.Context, req *CreateSaveServiceRequest) (*CreateSaveServ
 	if req.UserId <= 0 || req.SaveName == "" || req.SaveData == "" || req.SaveType == "" {
 		return nil, ErrInvalidRequest
 	}
+	// 配额检查
+	if err := checkSaveQuota(req.UserId, req.UserRole, int64(len(req.SaveData))); err != nil {
+		return nil, err
+	}
 	// 构造 db.Save
 	dbSave := &db.Save{
 		UserID:          req.UserId,
@@ -51,7 +56,7 @@ func Create(ctx context.Context, req *CreateSaveServiceRequest) (*CreateSaveServ
 		SaveDescription: req.SaveDescription,
 		SaveData:        req.SaveData,
 		SaveType:        req.SaveType,
-		SaveStatus:      "active",
+		SaveStatus:      db.SaveStatusActive,
 		CreatedAt:       nowUnix(),
 		UpdatedAt:       nowUnix(),
 	}
@@ -92,7 +97,8 @@ type GetSaveServiceRequest struct {
 // 包含保存项详细信息
 // 仅用于 service 层
 type GetSaveServiceResponse struct {
-	Save *save.Save // 保存项
+	Save    *save.Save // 保存项
+	Version int64      // 存档版本号，更新时回传用于并发冲突检测
 }
 
 // Get 获取保存业务逻辑，返回保存项和错误
@@ -105,7 +111,7 @@ func Get(ctx context.Context, req *GetSaveServiceRequest) (*GetSaveServiceRespon
 	if req == nil || req.UserId <= 0 || req.SaveId == "" {
 		return nil, ErrInvalidRequest
 	}
-	dbSave, err := querySaveBySaveID(req.SaveId)
+	dbSave, err := db.QuerySavesBySaveID(req.SaveId)
 	if err != nil {
 		return nil, err
 	}
@@ -125,12 +131,7 @@ func Get(ctx context.Context, req *GetSaveServiceRequest) (*GetSaveServiceRespon
 		CreatedAt:       dbSave.CreatedAt,
 		UpdatedAt:       dbSave.UpdatedAt,
 	}
-	return &GetSaveServiceResponse{Save: modelSave}, nil
-}
-
-// querySaveBySaveID 通过保存唯一标识符查询存档，直接调用 dal 层接口
-func querySaveBySaveID(saveID string) (*db.Save, error) {
-	return db.QuerySavesBySaveID(saveID)
+	return &GetSaveServiceResponse{Save: modelSave, Version: dbSave.Version}, nil
 }
 
 // UpdateSaveServiceRequest 更新保存业务参数
@@ -143,11 +144,14 @@ type UpdateSaveServiceRequest struct {
 	SaveDescription string // 保存描述
 	SaveData        string // 保存数据
 	SaveType        string // 保存类型
+	Version         int64  // 客户端持有的存档版本号，为0时以读取到的当前版本为准
+	UserRole        string // 用户角色，决定字节配额，为空时按普通用户处理
 }
 
 // UpdateSaveServiceResponse 更新保存业务返回值
 // 仅用于 service 层
 type UpdateSaveServiceResponse struct {
+	Version int64 // 更新后的存档版本号
 }
 
 // Update 更新保存业务逻辑，返回错误
@@ -160,23 +164,30 @@ func Update(ctx context.Context, req *UpdateSaveServiceRequest) (*UpdateSaveServ
 	if req == nil || req.UserId <= 0 || req.SaveId == "" {
 		return nil, ErrInvalidRequest
 	}
-	dbSave, err := querySaveBySaveID(req.SaveId)
+	dbSave, err := db.QuerySavesBySaveID(req.SaveId)
 	if err != nil {
 		return nil, err
 	}
 	if dbSave.UserID != req.UserId {
 		return nil, db.ErrSaveNotFound
 	}
+	// 按新旧内容的字节差检查配额，避免先建小存档再通过更新突破配额
+	if err := checkSaveSizeQuota(req.UserId, req.UserRole, int64(len(req.SaveData))-int64(len(dbSave.SaveData))); err != nil {
+		return nil, err
+	}
 	dbSave.SaveName = req.SaveName
 	dbSave.SaveDescription = req.SaveDescription
 	dbSave.SaveData = req.SaveData
 	dbSave.SaveType = req.SaveType
 	dbSave.UpdatedAt = nowUnix()
+	if req.Version > 0 {
+		dbSave.Version = req.Version
+	}
 	err = db.UpdateSave(dbSave)
 	if err != nil {
 		return nil, err
 	}
-	return &UpdateSaveServiceResponse{}, nil
+	return &UpdateSaveServiceResponse{Version: dbSave.Version}, nil
 }
 
 // DeleteSaveServiceRequest 删除保存业务参数
@@ -202,7 +213,7 @@ func Delete(ctx context.Context, req *DeleteSaveServiceRequest) (*DeleteSaveServ
 	if req == nil || req.UserId <= 0 || req.SaveId == "" {
 		return nil, ErrInvalidRequest
 	}
-	dbSave, err := querySaveBySaveID(req.SaveId)
+	dbSave, err := db.QuerySavesBySaveID(req.SaveId)
 	if err != nil {
 		return nil, err
 	}
@@ -216,6 +227,43 @@ func Delete(ctx context.Context, req *DeleteSaveServiceRequest) (*DeleteSaveServ
 	return &DeleteSaveServiceResponse{}, nil
 }
 
+// MaxBatchDeleteSize 单次批量删除的最大存档数量
+const MaxBatchDeleteSize = 100
+
+// BatchDeleteFailure 批量删除失败项
+type BatchDeleteFailure struct {
+	SaveId string // 保存ID
+	Reason string // 失败原因
+}
+
+// BatchDeleteSavesServiceResponse 批量删除保存业务返回值
+// 仅用于 service 层
+type BatchDeleteSavesServiceResponse struct {
+	DeletedCount int                  // 成功删除的数量
+	Failed       []BatchDeleteFailure // 失败明细，顺序与请求一致
+}
+
+// BatchDeleteSaves 批量删除保存业务逻辑，只删除归属该用户的存档
+// ctx: 上下文，userId: 用户ID，saveIds: 保存ID列表
+// 返回: 成功删除的数量、失败明细和错误
+func BatchDeleteSaves(ctx context.Context, userId int64, saveIds []string) (*BatchDeleteSavesServiceResponse, error) {
+	if userId <= 0 || len(saveIds) == 0 || len(saveIds) > MaxBatchDeleteSize {
+		return nil, ErrInvalidRequest
+	}
+	deleted, failed, err := db.BatchDeleteSavesByUser(userId, saveIds)
+	if err != nil {
+		return nil, err
+	}
+	resp := &BatchDeleteSavesServiceResponse{DeletedCount: len(deleted)}
+	for _, saveId := range saveIds {
+		if reason, ok := failed[saveId]; ok {
+			resp.Failed = append(resp.Failed, BatchDeleteFailure{SaveId: saveId, Reason: reason.Error()})
+			delete(failed, saveId)
+		}
+	}
+	return resp, nil
+}
+
 // ListSavesServiceRequest 列出保存业务参数
 // 包含用户ID、分页参数等
 // 仅用于 service 层，便于扩展和单元测试
@@ -261,3 +309,27 @@ func List(ctx context.Context, req *ListSavesServiceRequest) (*ListSavesServiceR
 	}
 	return &ListSavesServiceResponse{Saves: modelSaves, Total: int(total)}, nil
 }
+
+// DuplicateSave 复制存档（另存为），基于现有存档创建一个新分支，配额校验与普通存档一致
+// ctx: 上下文，userId: 用户ID，saveId: 原存档ID，newName: 新存档名称，userRole: 用户角色
+// 返回: 新存档的创建结果和错误；原存档不属于该用户时返回 db.ErrSaveNotFound
+func DuplicateSave(ctx context.Context, userId int64, saveId string, newName string, userRole string) (*CreateSaveServiceResponse, error) {
+	if userId <= 0 || saveId == "" || newName == "" {
+		return nil, ErrInvalidRequest
+	}
+	source, err := db.QuerySavesBySaveID(saveId)
+	if err != nil {
+		return nil, err
+	}
+	if source.UserID != userId {
+		return nil, db.ErrSaveNotFound
+	}
+	return Create(ctx, &CreateSaveServiceRequest{
+		UserId:          userId,
+		SaveName:        newName,
+		SaveDescription: source.SaveDescription,
+		SaveData:        source.SaveData,
+		SaveType:        source.SaveType,
+		UserRole:        userRole,
+	})
+}
@@ -0,0 +1,179 @@
+package save
+
+import (
+	"context"
+	"testing"
+
+	db "novelai/biz/dal/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupServiceTestDB 初始化测试数据库，使用SQLite内存数据库
+func setupServiceTestDB(t *testing.T) {
+	var err error
+	db.DB, err = gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err, "初始化测试数据库失败")
+	require.NoError(t, db.DB.AutoMigrate(&db.Save{}), "自动迁移存档表失败")
+	db.DB.Exec("DELETE FROM " + (db.Save{}).TableName())
+}
+
+// createServiceTestSave 创建测试存档
+func createServiceTestSave(t *testing.T, userID int64) string {
+	resp, err := Create(context.Background(), &CreateSaveServiceRequest{
+		UserId:          userID,
+		SaveName:        "原存档",
+		SaveDescription: "原存档描述",
+		SaveData:        `{"chapter":1}`,
+		SaveType:        "draft",
+	})
+	require.NoError(t, err, "创建测试存档失败")
+	return resp.SaveId
+}
+
+// TestDuplicateSave 测试复制存档
+func TestDuplicateSave(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+	sourceID := createServiceTestSave(t, 1)
+
+	resp, err := DuplicateSave(ctx, 1, sourceID, "分支存档", "")
+	require.NoError(t, err)
+	assert.NotEqual(t, sourceID, resp.SaveId, "复制后应生成新的 save_id")
+
+	copied, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: resp.SaveId})
+	require.NoError(t, err)
+	assert.Equal(t, "分支存档", copied.Save.SaveName)
+	assert.Equal(t, "原存档描述", copied.Save.SaveDescription)
+	assert.Equal(t, `{"chapter":1}`, copied.Save.SaveData)
+	assert.Equal(t, "draft", copied.Save.SaveType)
+
+	// 修改新存档不影响原存档
+	_, err = Update(ctx, &UpdateSaveServiceRequest{
+		UserId:   1,
+		SaveId:   resp.SaveId,
+		SaveName: "分支存档",
+		SaveData: `{"chapter":2}`,
+		SaveType: "draft",
+	})
+	require.NoError(t, err)
+	source, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: sourceID})
+	require.NoError(t, err)
+	assert.Equal(t, `{"chapter":1}`, source.Save.SaveData)
+}
+
+// TestDuplicateSaveValidation 测试复制存档的参数与归属校验
+func TestDuplicateSaveValidation(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+	sourceID := createServiceTestSave(t, 1)
+
+	_, err := DuplicateSave(ctx, 1, sourceID, "", "")
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	_, err = DuplicateSave(ctx, 2, sourceID, "他人存档", "")
+	assert.ErrorIs(t, err, db.ErrSaveNotFound, "不能复制他人的存档")
+
+	_, err = DuplicateSave(ctx, 1, "save-not-exist", "不存在", "")
+	assert.Error(t, err)
+}
+
+// TestDuplicateSaveUsesRoleQuota 测试复制存档按传入的角色套用配额
+func TestDuplicateSaveUsesRoleQuota(t *testing.T) {
+	setupServiceTestDB(t)
+	withSaveQuota(t, RoleNormal, SaveQuota{MaxSaves: 1})
+	withSaveQuota(t, RoleMember, SaveQuota{MaxSaves: 2})
+	ctx := context.Background()
+	sourceID := createServiceTestSave(t, 1)
+
+	_, err := DuplicateSave(ctx, 1, sourceID, "普通用户分支", RoleNormal)
+	assert.ErrorIs(t, err, ErrSaveCountExceeded)
+
+	_, err = DuplicateSave(ctx, 1, sourceID, "会员分支", RoleMember)
+	require.NoError(t, err, "会员应按会员配额放行")
+	_, err = DuplicateSave(ctx, 1, sourceID, "会员分支2", RoleMember)
+	assert.ErrorIs(t, err, ErrSaveCountExceeded)
+}
+
+// TestBatchDeleteSaves 测试混合自己和他人的存档时只删除自己的
+func TestBatchDeleteSaves(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+	mine1 := createServiceTestSave(t, 1)
+	mine2 := createServiceTestSave(t, 1)
+	others := createServiceTestSave(t, 2)
+
+	resp, err := BatchDeleteSaves(ctx, 1, []string{mine1, others, mine2, "save-not-exist", mine1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.DeletedCount)
+	assert.Equal(t, []BatchDeleteFailure{
+		{SaveId: others, Reason: db.ErrSaveNotFound.Error()},
+		{SaveId: "save-not-exist", Reason: db.ErrSaveNotFound.Error()},
+	}, resp.Failed)
+
+	// 自己的存档已删除，他人的存档保留
+	_, err = Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: mine1})
+	assert.ErrorIs(t, err, db.ErrSaveNotFound)
+	_, err = Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: mine2})
+	assert.ErrorIs(t, err, db.ErrSaveNotFound)
+	kept, err := Get(ctx, &GetSaveServiceRequest{UserId: 2, SaveId: others})
+	require.NoError(t, err)
+	assert.Equal(t, others, kept.Save.SaveId)
+
+	// 批量删除为软删除，记录仍保留在表中
+	for _, saveID := range []string{mine1, mine2} {
+		var dbSave db.Save
+		require.NoError(t, db.DB.Where("save_id = ?", saveID).First(&dbSave).Error)
+		assert.Equal(t, db.SaveStatusDeleted, dbSave.SaveStatus)
+	}
+
+	// 已删除的存档再次删除计入失败明细
+	resp, err = BatchDeleteSaves(ctx, 1, []string{mine1})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.DeletedCount)
+	assert.Equal(t, []BatchDeleteFailure{{SaveId: mine1, Reason: db.ErrSaveNotFound.Error()}}, resp.Failed)
+
+	// 参数校验
+	_, err = BatchDeleteSaves(ctx, 1, nil)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+	_, err = BatchDeleteSaves(ctx, 0, []string{mine1})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
+
+// TestUpdateVersionConflict 测试带同一旧版本号的两次更新，第二次返回冲突
+func TestUpdateVersionConflict(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+	saveID := createServiceTestSave(t, 1)
+
+	got, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: saveID})
+	require.NoError(t, err)
+	update := func(data string) (*UpdateSaveServiceResponse, error) {
+		return Update(ctx, &UpdateSaveServiceRequest{
+			UserId:   1,
+			SaveId:   saveID,
+			SaveName: "原存档",
+			SaveData: data,
+			SaveType: "draft",
+			Version:  got.Version,
+		})
+	}
+
+	resp, err := update(`{"chapter":2}`)
+	require.NoError(t, err)
+	assert.Equal(t, got.Version+1, resp.Version)
+
+	_, err = update(`{"chapter":3}`)
+	assert.ErrorIs(t, err, db.ErrSaveConflict)
+
+	latest, err := Get(ctx, &GetSaveServiceRequest{UserId: 1, SaveId: saveID})
+	require.NoError(t, err)
+	assert.Equal(t, `{"chapter":2}`, latest.Save.SaveData)
+	assert.Equal(t, resp.Version, latest.Version)
+}
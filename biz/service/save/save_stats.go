@@ -0,0 +1,44 @@
+// save_stats.go 存档存储占用统计，供用户中心展示已使用的存储空间
+package save
+
+import (
+	"context"
+
+	db "novelai/biz/dal/db"
+)
+
+// TypeStorageStats 单个存档类型的存储占用
+type TypeStorageStats struct {
+	Count int64 // 存档数量
+	Bytes int64 // 存档内容总字节数
+}
+
+// StorageStats 用户的存档存储占用统计，不含已删除的存档
+type StorageStats struct {
+	TotalBytes int64                       // 存档内容总字节数
+	SaveCount  int64                       // 存档数量
+	ByType     map[string]TypeStorageStats // 按存档类型的分布
+}
+
+// GetUserStorageStats 统计用户所有存档的存储占用
+// ctx: 上下文，userId: 用户ID
+// 返回: 存储占用统计和错误；通过聚合查询完成，不拉取存档内容
+func GetUserStorageStats(ctx context.Context, userId int64) (*StorageStats, error) {
+	if userId <= 0 {
+		return nil, ErrInvalidRequest
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	typeStats, err := db.SumSaveStatsByUserGroupByType(userId)
+	if err != nil {
+		return nil, err
+	}
+	stats := &StorageStats{ByType: make(map[string]TypeStorageStats, len(typeStats))}
+	for _, s := range typeStats {
+		stats.TotalBytes += s.Bytes
+		stats.SaveCount += s.Count
+		stats.ByType[s.SaveType] = TypeStorageStats{Count: s.Count, Bytes: s.Bytes}
+	}
+	return stats, nil
+}
@@ -0,0 +1,49 @@
+package save
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetUserStorageStats 测试按用户聚合存档的字节数、数量与类型分布
+func TestGetUserStorageStats(t *testing.T) {
+	setupServiceTestDB(t)
+	ctx := context.Background()
+
+	create := func(userID int64, saveType, data string) string {
+		resp, err := Create(ctx, &CreateSaveServiceRequest{
+			UserId: userID, SaveName: "统计存档", SaveData: data, SaveType: saveType,
+		})
+		require.NoError(t, err)
+		return resp.SaveId
+	}
+	create(1, "draft", strings.Repeat("a", 100))
+	create(1, "draft", "北境") // 中文按 UTF-8 字节计算
+	create(1, "config", strings.Repeat("c", 40))
+	deleted := create(1, "checkpoint", strings.Repeat("x", 1000))
+	create(2, "draft", strings.Repeat("b", 500))
+
+	_, err := Delete(ctx, &DeleteSaveServiceRequest{UserId: 1, SaveId: deleted})
+	require.NoError(t, err)
+
+	stats, err := GetUserStorageStats(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.SaveCount)
+	assert.Equal(t, int64(100+len("北境")+40), stats.TotalBytes)
+	assert.Equal(t, map[string]TypeStorageStats{
+		"draft":  {Count: 2, Bytes: int64(100 + len("北境"))},
+		"config": {Count: 1, Bytes: 40},
+	}, stats.ByType)
+
+	empty, err := GetUserStorageStats(ctx, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), empty.SaveCount)
+	assert.Empty(t, empty.ByType)
+
+	_, err = GetUserStorageStats(ctx, 0)
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+}
@@ -7,14 +7,31 @@ package user
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"novelai/biz/dal/db"
 	"novelai/biz/model/user"
+	"novelai/pkg/middleware/jwt"
 	"novelai/pkg/utils/crypto"
 
 	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 )
 
+// ErrAdminRequired 非管理员尝试执行管理员操作
+var ErrAdminRequired = errors.New("仅管理员可执行该操作")
+
+// ErrInvalidUsername 用户名为空或超出长度限制
+var ErrInvalidUsername = errors.New("用户名不合法")
+
+// ErrEmptyPassword 新密码为空
+var ErrEmptyPassword = errors.New("新密码不能为空")
+
+// maxUsernameLength 用户名最大长度，与用户表 username 字段一致
+const maxUsernameLength = 64
+
 // UserService 用户服务结构体
 // 负责处理所有与用户相关的业务逻辑
 type UserService struct {
@@ -47,15 +64,6 @@ func generatePasswordHash(password string) string {
 //   - userId: 用户ID
 //   - error: 操作错误信息
 func (s *UserService) Register(req *user.RegisterRequest) (userId int64, err error) {
-	// 检查用户名是否已存在
-	existUser, err := db.QueryUserByUsername(req.Username)
-	if err != nil && err != db.ErrUserNotFound {
-		return 0, err
-	}
-	if existUser != nil {
-		return 0, db.ErrUserAlreadyExists
-	}
-
 	// 密码加密
 	passwordHash := generatePasswordHash(req.Password)
 
@@ -68,7 +76,7 @@ func (s *UserService) Register(req *user.RegisterRequest) (userId int64, err err
 		Status:   0, // 默认状态：正常
 	}
 
-	// 调用数据库层创建用户
+	// 调用数据库层创建用户，用户名重复时由唯一索引保证返回 db.ErrUserAlreadyExists
 	userId, err = db.CreateUser(newUser)
 	if err != nil {
 		return 0, err
@@ -151,6 +159,111 @@ func (s *UserService) UpdateUserProfile(userId int64, req *user.UpdateUserReques
 	return db.UpdateUserProfile(updateUser)
 }
 
+// AdminUpdateUserRequest 管理员更新用户敏感字段的请求，nil 字段表示不修改
+type AdminUpdateUserRequest struct {
+	Status  *int32 // 用户状态：0-正常，1-禁用
+	IsAdmin *bool  // 是否管理员
+}
+
+// AdminUpdateUser 管理员更新用户的敏感字段
+// 普通用户只能通过 UpdateUserProfile 修改昵称、头像等资料，Status、IsAdmin 只能经此路径修改，
+// 每次修改都会记录操作日志
+// 参数:
+//   - operatorId: 操作者用户ID，必须是管理员
+//   - targetId: 目标用户ID
+//   - req: 需要修改的敏感字段
+//
+// 返回:
+//   - error: 操作者不是管理员时返回 ErrAdminRequired
+func (s *UserService) AdminUpdateUser(operatorId, targetId int64, req *AdminUpdateUserRequest) error {
+	operator, err := db.QueryUserByID(operatorId)
+	if err != nil {
+		return err
+	}
+	if !operator.IsAdmin {
+		return ErrAdminRequired
+	}
+
+	err = db.UpdateUserAdminFields(targetId, db.UserAdminFields{
+		Status:  req.Status,
+		IsAdmin: req.IsAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	hlog.CtxInfof(s.ctx, "管理员更新用户敏感字段: operator=%d, target=%d, status=%s, is_admin=%s",
+		operatorId, targetId, formatOptional(req.Status), formatOptional(req.IsAdmin))
+	return nil
+}
+
+// formatOptional 格式化可选字段用于日志，nil 显示为“未修改”
+func formatOptional[T any](v *T) string {
+	if v == nil {
+		return "未修改"
+	}
+	return fmt.Sprint(*v)
+}
+
+// AdminResetPassword 管理员重置其他用户的密码
+// 新密码按登录链路先做 MD5 再以 bcrypt 存储，重置后目标用户已签发的令牌全部失效，
+// 需使用新密码重新登录；每次重置都会记录操作日志
+// 注意：令牌吊销记录只保存在当前进程内存中，服务重启后丢失，多实例部署时也不会同步到其他实例，
+// 此时旧令牌在过期前仍可使用；需要可靠吊销时应缩短令牌有效期或改为持久化令牌版本
+// 参数:
+//   - adminId: 操作者用户ID，必须是管理员
+//   - targetUserId: 目标用户ID
+//   - newPassword: 新密码(明文)
+//
+// 返回:
+//   - error: 操作者不是管理员时返回 ErrAdminRequired，新密码为空返回 ErrEmptyPassword
+func (s *UserService) AdminResetPassword(adminId, targetUserId int64, newPassword string) error {
+	if newPassword == "" {
+		return ErrEmptyPassword
+	}
+	admin, err := db.QueryUserByID(adminId)
+	if err != nil {
+		return err
+	}
+	if !admin.IsAdmin {
+		return ErrAdminRequired
+	}
+
+	passwordHash, err := crypto.HashPasswordBcrypt(generatePasswordHash(newPassword))
+	if err != nil {
+		return err
+	}
+	if err := db.UpdateUserPassword(targetUserId, passwordHash); err != nil {
+		return err
+	}
+	jwt.RevokeUserTokens(targetUserId)
+
+	hlog.CtxInfof(s.ctx, "管理员重置用户密码: operator=%d, target=%d", adminId, targetUserId)
+	return nil
+}
+
+// ChangeUsername 修改用户名
+// 新用户名需唯一，修改成功后记录一条改名历史，可通过 db.ListUsernameHistory 查询
+// 参数:
+//   - userId: 用户ID
+//   - newUsername: 新用户名，首尾空白会被去掉
+//
+// 返回:
+//   - error: 新用户名不合法返回 ErrInvalidUsername，已被占用返回 db.ErrUserAlreadyExists
+func (s *UserService) ChangeUsername(userId int64, newUsername string) error {
+	newUsername = strings.TrimSpace(newUsername)
+	if newUsername == "" || len(newUsername) > maxUsernameLength {
+		return ErrInvalidUsername
+	}
+
+	if err := db.ChangeUsername(userId, newUsername); err != nil {
+		return err
+	}
+
+	hlog.CtxInfof(s.ctx, "用户修改用户名: userId=%d, newUsername=%s", userId, newUsername)
+	return nil
+}
+
 // UpdateUserPassword 更新用户密码
 // 参数:
 //   - userId: 用户ID
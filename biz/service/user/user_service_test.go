@@ -0,0 +1,166 @@
+/*
+ * NovelAI Project
+ * Copyright (C) 2023-2025
+ */
+
+package user
+
+import (
+	"context"
+	"testing"
+
+	"novelai/biz/dal/db"
+	"novelai/biz/model/user"
+	"novelai/pkg/middleware/jwt"
+	"novelai/pkg/utils/crypto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupServiceTestDB 初始化服务层测试数据库
+func setupServiceTestDB(t *testing.T) {
+	var err error
+	db.DB, err = gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	require.NoError(t, err, "初始化测试数据库失败")
+	require.NoError(t, db.DB.AutoMigrate(&db.User{}), "自动迁移用户表失败")
+	db.DB.Exec("DELETE FROM " + db.TableNameUser)
+}
+
+// createServiceTestUser 创建测试用户
+func createServiceTestUser(t *testing.T, username string, isAdmin bool) int64 {
+	id, err := db.CreateUser(&db.User{
+		Username: username,
+		Password: "password123",
+		Nickname: username,
+		Email:    username + "@example.com",
+		Status:   1,
+		IsAdmin:  isAdmin,
+	})
+	require.NoError(t, err, "创建测试用户失败")
+	return id
+}
+
+// TestUpdateUserProfileKeepsSensitiveFields 测试普通资料更新无法修改 Status
+func TestUpdateUserProfileKeepsSensitiveFields(t *testing.T) {
+	setupServiceTestDB(t)
+	svc := NewUserService(context.Background(), nil)
+	userId := createServiceTestUser(t, "normal_user", false)
+
+	err := svc.UpdateUserProfile(userId, &user.UpdateUserRequest{
+		Nickname: "新昵称",
+		Email:    "normal_user_new@example.com",
+	})
+	require.NoError(t, err)
+
+	dbUser, err := db.QueryUserByID(userId)
+	require.NoError(t, err)
+	assert.Equal(t, "新昵称", dbUser.Nickname)
+	assert.Equal(t, int32(1), dbUser.Status, "普通资料更新不应修改状态")
+	assert.False(t, dbUser.IsAdmin)
+}
+
+// TestAdminUpdateUser 测试管理员可以修改 Status 与 IsAdmin，普通用户不行
+func TestAdminUpdateUser(t *testing.T) {
+	setupServiceTestDB(t)
+	svc := NewUserService(context.Background(), nil)
+	adminId := createServiceTestUser(t, "admin_user", true)
+	normalId := createServiceTestUser(t, "normal_user", false)
+	targetId := createServiceTestUser(t, "target_user", false)
+
+	status := int32(0)
+	isAdmin := true
+	req := &AdminUpdateUserRequest{Status: &status, IsAdmin: &isAdmin}
+
+	err := svc.AdminUpdateUser(normalId, targetId, req)
+	assert.ErrorIs(t, err, ErrAdminRequired, "普通用户不能走管理员更新路径")
+
+	require.NoError(t, svc.AdminUpdateUser(adminId, targetId, req))
+	dbUser, err := db.QueryUserByID(targetId)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), dbUser.Status)
+	assert.True(t, dbUser.IsAdmin)
+
+	// 只修改状态时保留其他敏感字段
+	disabled := int32(1)
+	require.NoError(t, svc.AdminUpdateUser(adminId, targetId, &AdminUpdateUserRequest{Status: &disabled}))
+	dbUser, err = db.QueryUserByID(targetId)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), dbUser.Status)
+	assert.True(t, dbUser.IsAdmin)
+
+	err = svc.AdminUpdateUser(adminId, 9999, req)
+	assert.ErrorIs(t, err, db.ErrUserNotFound)
+}
+
+// TestChangeUsername 测试改名成功且历史记录可查，改成已存在的名字被拒
+func TestChangeUsername(t *testing.T) {
+	setupServiceTestDB(t)
+	require.NoError(t, db.DB.AutoMigrate(&db.UsernameHistory{}), "自动迁移改名历史表失败")
+	db.DB.Exec("DELETE FROM " + db.TableNameUsernameHistory)
+	svc := NewUserService(context.Background(), nil)
+	userId := createServiceTestUser(t, "old_name", false)
+	createServiceTestUser(t, "taken_name", false)
+
+	require.NoError(t, svc.ChangeUsername(userId, " new_name "))
+	dbUser, err := db.QueryUserByID(userId)
+	require.NoError(t, err)
+	assert.Equal(t, "new_name", dbUser.Username)
+
+	histories, err := db.ListUsernameHistory(userId)
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	assert.Equal(t, "old_name", histories[0].OldUsername)
+	assert.Equal(t, "new_name", histories[0].NewUsername)
+
+	err = svc.ChangeUsername(userId, "taken_name")
+	assert.ErrorIs(t, err, db.ErrUserAlreadyExists)
+	dbUser, err = db.QueryUserByID(userId)
+	require.NoError(t, err)
+	assert.Equal(t, "new_name", dbUser.Username, "改名失败时用户名不变")
+	histories, err = db.ListUsernameHistory(userId)
+	require.NoError(t, err)
+	assert.Len(t, histories, 1, "改名失败时不记录历史")
+
+	assert.ErrorIs(t, svc.ChangeUsername(userId, "new_name"), db.ErrUsernameUnchanged)
+	assert.ErrorIs(t, svc.ChangeUsername(userId, "  "), ErrInvalidUsername)
+	assert.ErrorIs(t, svc.ChangeUsername(999999, "ghost"), db.ErrUserNotFound)
+}
+
+// TestAdminResetPassword 测试管理员重置密码后新密码可登录、旧令牌失效，普通用户无权重置
+func TestAdminResetPassword(t *testing.T) {
+	setupServiceTestDB(t)
+	svc := NewUserService(context.Background(), nil)
+	adminId := createServiceTestUser(t, "reset_admin", true)
+	normalId := createServiceTestUser(t, "reset_normal", false)
+	targetId := createServiceTestUser(t, "reset_target", false)
+
+	err := svc.AdminResetPassword(normalId, targetId, "new-secret")
+	assert.ErrorIs(t, err, ErrAdminRequired, "普通用户不能重置他人密码")
+	_, err = db.VerifyUser("reset_target", "password123")
+	assert.NoError(t, err, "被拒绝时密码不变")
+
+	versionBefore := jwt.UserTokenVersion(targetId)
+	require.NoError(t, svc.AdminResetPassword(adminId, targetId, "new-secret"))
+	assert.Greater(t, jwt.UserTokenVersion(targetId), versionBefore, "重置后应吊销目标用户的旧令牌")
+
+	dbUser, err := db.QueryUserByID(targetId)
+	require.NoError(t, err)
+	assert.True(t, crypto.IsBcryptHash(dbUser.Password), "重置后的密码应以 bcrypt 存储")
+
+	userId, err := db.VerifyUser("reset_target", crypto.HashPassword("new-secret"))
+	require.NoError(t, err, "新密码应可登录")
+	assert.Equal(t, targetId, userId)
+	_, err = db.VerifyUser("reset_target", "password123")
+	assert.ErrorIs(t, err, db.ErrInvalidPassword, "旧密码不能再登录")
+	_, err = db.VerifyUser("reset_target", crypto.HashPassword("wrong"))
+	assert.ErrorIs(t, err, db.ErrInvalidPassword)
+
+	assert.ErrorIs(t, svc.AdminResetPassword(adminId, targetId, ""), ErrEmptyPassword)
+	assert.ErrorIs(t, svc.AdminResetPassword(adminId, 999999, "new-secret"), db.ErrUserNotFound)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 
 	"novelai/biz/dal/db"
+	"novelai/pkg/middleware"
 )
 
 // 获取环境变量值，如果不存在则使用默认值
@@ -63,8 +64,8 @@ func main() {
 	initDB()
 	hlog.Debug("数据库初始化完成")
 
-	// 创建Hertz服务器实例
-	h := server.Default()
+	// 创建Hertz服务器实例，读取请求时即拒绝超过上限的请求体，避免超大请求占满内存
+	h := server.Default(server.WithMaxRequestBodySize(middleware.ServerMaxBodySize))
 	hlog.Debug("Hertz 服务器实例创建完成")
 
 	// 注册路由
@@ -50,6 +50,15 @@ func NotFoundError(resourceName string) *Errno {
 	return New(10003, message)
 }
 
+// QuotaExceededError 创建一个表示配额超限的错误
+// Code: 10004 (示例)
+func QuotaExceededError(message string) *Errno {
+	if message == "" {
+		message = "Quota exceeded"
+	}
+	return New(10004, message)
+}
+
 // Specific error instances (can be expanded)
 var (
 	// Common errors
@@ -15,22 +15,46 @@ import (
 // GenericAdvancedAgent 通用高级智能体
 // 这是一个示例实现，展示如何创建同时支持工具和记忆的智能体
 type GenericAdvancedAgent struct {
-	*BaseAdvancedAgent           // 嵌入基础高级智能体实现
-	prompt             string    // 提示模板
-	maxRetries         int       // 最大重试次数
-	lastProcessTime    time.Time // 上次处理时间
+	*BaseAdvancedAgent                   // 嵌入基础高级智能体实现
+	prompt             string            // 提示模板
+	maxRetries         int               // 最大重试次数
+	lastProcessTime    time.Time         // 上次处理时间
+	clock              Clock             // 时钟，默认使用系统时钟
+	outputProcessors   []OutputProcessor // 输出后处理器链，生成普通响应内容前依次应用
 }
 
 // NewGenericAdvancedAgent 创建新的通用高级智能体
 func NewGenericAdvancedAgent(id string, agentType AgentType, prompt string) *GenericAdvancedAgent {
+	// 未指定提示模板时按类型使用默认提示词库
+	if prompt == "" {
+		prompt = DefaultPromptFor(agentType)
+	}
 	agent := &GenericAdvancedAgent{
 		BaseAdvancedAgent: NewBaseAdvancedAgent(id, agentType),
 		prompt:            prompt,
 		maxRetries:        3,
+		clock:             SystemClock,
 	}
 	return agent
 }
 
+// SetClock 设置智能体使用的时钟，传入 nil 时恢复为系统时钟
+func (a *GenericAdvancedAgent) SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	a.clock = clock
+}
+
+// AddOutputProcessor 注册输出后处理器，按注册顺序依次应用于普通响应的内容
+func (a *GenericAdvancedAgent) AddOutputProcessor(processors ...OutputProcessor) {
+	for _, processor := range processors {
+		if processor != nil {
+			a.outputProcessors = append(a.outputProcessors, processor)
+		}
+	}
+}
+
 // Initialize 实现Agent接口，进行初始化
 func (a *GenericAdvancedAgent) Initialize(ctx context.Context) error {
 	hlog.CtxInfof(ctx, "初始化通用高级智能体: ID=%s, Type=%s", a.GetID(), a.GetType())
@@ -65,7 +89,7 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 		msg.ID, msg.Type, msg.Subject)
 
 	// 记录处理时间
-	now := time.Now()
+	now := a.clock.Now()
 	a.lastProcessTime = now
 
 	// 记录到记忆
@@ -75,9 +99,9 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 			hlog.CtxWarnf(ctx, "保存处理时间到记忆失败: %v", err)
 		}
 
-		// 保存消息历史
+		// 保存消息历史，按消息所属会话隔离
 		historyKey := memory.CreateTaggedKey(a.GetID(), "history", msg.ID)
-		if err := a.SaveMemory(ctx, historyKey, msg); err != nil {
+		if err := a.SaveMemory(memorySessionContext(ctx, msg), historyKey, msg); err != nil {
 			hlog.CtxWarnf(ctx, "保存消息历史到记忆失败: %v", err)
 		}
 	}
@@ -95,23 +119,7 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 	promptTemplate := a.prompt
 	if promptTemplate == "" {
 		// 默认提示模板包含智能体角色、工具能力和通信能力的说明
-		promptTemplate = `你是一个智能体，类型为%s。你可以：
-1. 处理用户消息并直接回复
-2. 调用可用工具完成任务
-3. 向其他智能体发送消息
-
-消息类型: %s
-消息来源: %s
-消息主题: %s
-
-消息内容:
-%s
-
-如需调用工具，请使用格式：
-{"tool":"工具名称","input":"参数"}
-
-如需发送消息给其他智能体，请使用格式：
-{"send_to":"目标智能体ID","message":"消息内容"}`
+		promptTemplate = DefaultPromptFor(a.GetType())
 	}
 	
 	// 生成提示，包含完整的消息上下文
@@ -130,20 +138,9 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 
 	if a.GetModel().SupportsJSON() {
 		// 使用JSON模式
-		messages := []llms.MessageContent{
-			{
-				Role: "system",
-				Parts: []llms.ContentPart{
-					llms.TextPart(fmt.Sprintf("你是一个智能体，类型为%s。请以JSON格式回复。", a.GetType())),
-				},
-			},
-			{
-				Role: "user",
-				Parts: []llms.ContentPart{
-					llms.TextPart(msg.Content),
-				},
-			},
-		}
+		messages := append([]llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeSystem, fmt.Sprintf("你是一个智能体，类型为%s。请以JSON格式回复。", a.GetType())),
+		}, ToLLMMessages([]*Message{msg})...)
 
 		// 使用GenerateContent方法
 		contentResponse, err := a.GetModel().GenerateContent(ctx, messages)
@@ -171,8 +168,9 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 	}
 
 	var sendMessage struct {
-		SendTo  string `json:"send_to"`
-		Message string `json:"message"`
+		SendTo     string `json:"send_to"`
+		Message    string `json:"message"`
+		AwaitReply bool   `json:"await_reply"`
 	}
 
 	// 尝试解析工具调用
@@ -184,8 +182,12 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 			return nil, fmt.Errorf("智能体没有工具调用器，无法执行工具调用请求")
 		}
 
-		// 执行工具调用
-		toolResult, err := a.CallTool(ctx, toolCall.Tool, toolCall.Input)
+		// 执行工具调用，同一会话内的只读工具结果可由缓存复用
+		toolCtx := ctx
+		if ToolSessionFromContext(ctx) == "" {
+			toolCtx = ContextWithToolSession(ctx, toolSessionID(msg))
+		}
+		toolResult, err := a.CallTool(toolCtx, toolCall.Tool, toolCall.Input)
 		if err != nil {
 			hlog.CtxErrorf(ctx, "工具调用失败: %v", err)
 			return nil, fmt.Errorf("工具调用失败: %w", err)
@@ -194,10 +196,10 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 		// 创建工具调用结果消息
 		response := NewMessage(MessageTypeToolResult, a.GetID(), msg.From)
 		response.Subject = "工具调用结果: " + toolCall.Tool
-		response.Content = toolResult
+		response.SetToolResult(toolResult)
 		response.ReplyTo = msg.ID
 		response.SetMetadata("tool_name", toolCall.Tool)
-		response.SetMetadata("process_time", time.Since(now).String())
+		response.SetMetadata("process_time", a.clock.Now().Sub(now).String())
 		response.SetMetadata("agent_type", string(a.GetType()))
 
 		return response, nil
@@ -212,9 +214,15 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 		response.Subject = fmt.Sprintf("来自%s的消息", a.GetType())
 		response.Content = sendMessage.Message
 		response.ReplyTo = msg.ID
+		response.Hops = msg.Hops + 1
 		response.SetMetadata("original_from", msg.From)
-		response.SetMetadata("process_time", time.Since(now).String())
+		response.SetMetadata("process_time", a.clock.Now().Sub(now).String())
 		response.SetMetadata("agent_type", string(a.GetType()))
+		// 需要对方结果才能继续时，由编排器把对方的响应回给本智能体
+		if sendMessage.AwaitReply {
+			response.CorrelationID = msg.ID
+			response.SetMetadata(MetadataAwaitReply, true)
+		}
 
 		return response, nil
 	}
@@ -222,11 +230,11 @@ func (a *GenericAdvancedAgent) Process(ctx context.Context, msg *Message) (*Mess
 	// 如果不是工具调用或模型间通信，则创建普通响应消息
 	response := NewMessage(MessageTypeResponse, a.GetID(), msg.From)
 	response.Subject = "处理结果: " + msg.Subject
-	response.Content = modelResponse
+	response.Content = applyOutputProcessors(modelResponse, a.outputProcessors)
 	response.ReplyTo = msg.ID
 
 	// 添加处理元数据
-	response.SetMetadata("process_time", time.Since(now).String())
+	response.SetMetadata("process_time", a.clock.Now().Sub(now).String())
 	response.SetMetadata("agent_type", string(a.GetType()))
 	response.SetMetadata("model_name", a.GetModel().ModelName())
 	response.SetMetadata("model_type", string(a.GetModel().ModelType()))
@@ -274,7 +282,7 @@ func (a *GenericAdvancedAgent) handleToolCallMessage(ctx context.Context, msg *M
 	// 记录工具调用结果到记忆
 	if a.GetMemoryManager() != nil {
 		toolResultKey := memory.CreateTaggedKey(a.GetID(), "tool_results", msg.ID)
-		if err := a.SaveMemory(ctx, toolResultKey, result); err != nil {
+		if err := a.SaveMemory(memorySessionContext(ctx, msg), toolResultKey, result); err != nil {
 			hlog.CtxWarnf(ctx, "保存工具调用结果到记忆失败: %v", err)
 		}
 	}
@@ -289,7 +297,7 @@ func (a *GenericAdvancedAgent) Shutdown(ctx context.Context) error {
 	// 保存最终状态到记忆
 	if a.GetMemoryManager() != nil {
 		stateKey := memory.CreateTaggedKey(a.GetID(), "state", "shutdown_time")
-		if err := a.SaveMemory(ctx, stateKey, time.Now().Format(time.RFC3339)); err != nil {
+		if err := a.SaveMemory(ctx, stateKey, a.clock.Now().Format(time.RFC3339)); err != nil {
 			hlog.CtxWarnf(ctx, "保存关闭时间到记忆失败: %v", err)
 		}
 	}
@@ -299,6 +307,7 @@ func (a *GenericAdvancedAgent) Shutdown(ctx context.Context) error {
 }
 
 // UseMemory 从记忆中查找相关信息
+// ctx 中带有会话ID时只返回该会话下的记忆
 // category: 记忆类别
 // prefix: 键前缀
 // 返回: 找到的记忆映射
@@ -307,8 +316,8 @@ func (a *GenericAdvancedAgent) UseMemory(ctx context.Context, category string, p
 		return nil, nil
 	}
 
-	// 创建搜索前缀
-	searchPrefix := memory.CreateTaggedKey(a.GetID(), category, prefix)
+	// 创建搜索前缀，ctx 中带有会话ID时只查找该会话下的记忆
+	searchPrefix := memory.SessionKey(memory.SessionFromContext(ctx), memory.CreateTaggedKey(a.GetID(), category, prefix))
 
 	// 列出匹配的键
 	keys, err := a.GetMemoryManager().List(ctx, searchPrefix)
@@ -319,8 +328,9 @@ func (a *GenericAdvancedAgent) UseMemory(ctx context.Context, category string, p
 	// 加载所有匹配的记忆
 	memories := make(map[string]interface{})
 	for _, key := range keys {
-		_, _, shortKey := memory.ExtractKeyParts(key)
-		value, err := a.LoadMemory(ctx, key)
+		_, taggedKey := memory.SplitSessionKey(key)
+		_, _, shortKey := memory.ExtractKeyParts(taggedKey)
+		value, err := a.LoadMemory(ctx, taggedKey)
 		if err != nil {
 			hlog.CtxWarnf(ctx, "加载记忆失败 %s: %v", key, err)
 			continue
@@ -330,3 +340,12 @@ func (a *GenericAdvancedAgent) UseMemory(ctx context.Context, category string, p
 
 	return memories, nil
 }
+
+// memorySessionContext 返回记录消息相关记忆使用的 context
+// ctx 未指定会话时以消息的 CorrelationID 作为会话，两者都为空时不按会话隔离
+func memorySessionContext(ctx context.Context, msg *Message) context.Context {
+	if memory.SessionFromContext(ctx) != "" || msg.CorrelationID == "" {
+		return ctx
+	}
+	return memory.ContextWithSession(ctx, msg.CorrelationID)
+}
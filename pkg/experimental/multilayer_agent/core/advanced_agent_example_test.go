@@ -0,0 +1,259 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"novelai/pkg/experimental/multilayer_agent/shared/memory"
+	"novelai/pkg/experimental/multilayer_agent/shared/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// stubLLM 测试用语言模型，返回值由 callFunc 决定
+type stubLLM struct {
+	callFunc func(ctx context.Context, prompt string) (string, error)
+}
+
+// Call 实现llms.Model接口
+func (m *stubLLM) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return m.callFunc(ctx, prompt)
+}
+
+// GenerateContent 实现llms.Model接口，取最后一条消息的文本作为提示
+func (m *stubLLM) GenerateContent(ctx context.Context, messages []llms.MessageContent, options ...llms.CallOption) (*llms.ContentResponse, error) {
+	prompt := ""
+	if len(messages) > 0 {
+		for _, part := range messages[len(messages)-1].Parts {
+			if text, ok := part.(llms.TextContent); ok {
+				prompt += text.Text
+			}
+		}
+	}
+	content, err := m.callFunc(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: content}}}, nil
+}
+
+// newStubModel 创建包装 stubLLM 的测试模型
+func newStubModel(fn func(ctx context.Context, prompt string) (string, error)) *model.ModelWrapper {
+	return &model.ModelWrapper{
+		BaseModel: &stubLLM{callFunc: fn},
+		Type:      model.ModelTypeOllama,
+		Name:      "stub",
+	}
+}
+
+// fakeClock 可控的假时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now 实现Clock接口
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance 将假时钟向前拨动指定时长
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// TestGenericAdvancedAgentClock 测试注入假时钟后处理耗时是确定值
+func TestGenericAdvancedAgentClock(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)}
+
+	agent := NewGenericAdvancedAgent("plot-agent", AgentTypePlot, "")
+	agent.SetClock(clock)
+	// 模型调用期间时钟前进 1.5 秒
+	agent.SetModel(newStubModel(func(ctx context.Context, prompt string) (string, error) {
+		clock.Advance(1500 * time.Millisecond)
+		return "情节草稿", nil
+	}))
+
+	msg := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	msg.Content = "写一段开头"
+
+	response, err := agent.Process(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "情节草稿", response.Content)
+
+	processTime, ok := response.GetMetadata("process_time")
+	require.True(t, ok)
+	assert.Equal(t, "1.5s", processTime)
+	assert.Equal(t, time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC), agent.lastProcessTime)
+}
+
+// TestGenericAdvancedAgentDefaultPrompt 测试未指定提示时按类型填充默认提示
+func TestGenericAdvancedAgentDefaultPrompt(t *testing.T) {
+	prompts := make(map[AgentType]string)
+	for _, agentType := range []AgentType{AgentTypeWorldview, AgentTypeCharacter, AgentTypePlot} {
+		agent := NewGenericAdvancedAgent(string(agentType)+"-agent", agentType, "")
+		assert.NotEmpty(t, agent.prompt)
+		assert.Equal(t, DefaultPromptFor(agentType), agent.prompt)
+		assert.Equal(t, 5, strings.Count(agent.prompt, "%s"), "默认提示应包含5个占位符")
+		prompts[agentType] = agent.prompt
+	}
+	assert.NotEqual(t, prompts[AgentTypeWorldview], prompts[AgentTypeCharacter])
+	assert.NotEqual(t, prompts[AgentTypeCharacter], prompts[AgentTypePlot])
+	assert.Contains(t, prompts[AgentTypeWorldview], "世界观")
+
+	// 没有专门说明的类型使用通用模板
+	assert.Equal(t, promptHeader+promptBody, DefaultPromptFor(AgentTypeFormatter))
+
+	// 传入自定义提示时不被覆盖
+	custom := NewGenericAdvancedAgent("custom-agent", AgentTypeWorldview, "自定义提示%s%s%s%s%s")
+	assert.Equal(t, "自定义提示%s%s%s%s%s", custom.prompt)
+
+	// 默认提示渲染后包含角色说明与消息内容
+	var rendered string
+	agent := NewGenericAdvancedAgent("character-agent", AgentTypeCharacter, "")
+	agent.SetModel(newStubModel(func(ctx context.Context, prompt string) (string, error) {
+		rendered = prompt
+		return "角色设定", nil
+	}))
+	msg := NewMessage(MessageTypeRequest, "tester", "character-agent")
+	msg.Content = "设计一个反派"
+	_, err := agent.Process(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Contains(t, rendered, "类型为character")
+	assert.Contains(t, rendered, "塑造小说角色")
+	assert.Contains(t, rendered, "设计一个反派")
+	assert.NotContains(t, rendered, "%!")
+}
+
+// TestGenericAdvancedAgentOutputProcessor 测试注册输出后处理器后响应内容被清洗
+func TestGenericAdvancedAgentOutputProcessor(t *testing.T) {
+	agent := NewGenericAdvancedAgent("plot-agent", AgentTypePlot, "")
+	agent.SetModel(newStubModel(func(ctx context.Context, prompt string) (string, error) {
+		return "好的，以下是为您生成的开头：\n夜色笼罩着王都，钟声响了十二下。  ", nil
+	}))
+	agent.AddOutputProcessor(StripPreamble, TrimOutput)
+
+	msg := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	msg.Content = "写一段开头"
+	response, err := agent.Process(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "夜色笼罩着王都，钟声响了十二下。", response.Content)
+
+	// 处理器按注册顺序依次应用
+	agent.AddOutputProcessor(TruncateOutput(4))
+	response, err = agent.Process(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "夜色笼罩", response.Content)
+}
+
+// TestStripPreamble 测试客套话前缀的识别范围
+func TestStripPreamble(t *testing.T) {
+	assert.Equal(t, "正文", StripPreamble("当然！下面为您续写：正文"))
+	assert.Equal(t, "正文", StripPreamble("以下是世界观设定:\n正文"))
+	assert.Equal(t, "他说：好的，我们走", StripPreamble("他说：好的，我们走"), "正文中的冒号不应被误删")
+}
+
+// TestGenericAdvancedAgentAwaitReply 测试模型要求等待回复时生成同步转发消息
+func TestGenericAdvancedAgentAwaitReply(t *testing.T) {
+	agent := NewGenericAdvancedAgent("plot-agent", AgentTypePlot, "")
+	agent.SetModel(newStubModel(func(ctx context.Context, prompt string) (string, error) {
+		return `{"send_to":"dialogue-agent","message":"写一段对白","await_reply":true}`, nil
+	}))
+
+	msg := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	response, err := agent.Process(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "dialogue-agent", response.To)
+	assert.True(t, response.AwaitsReply())
+	assert.Equal(t, msg.ID, response.CorrelationID)
+}
+
+// TestGenericAdvancedAgentStructuredToolResult 测试工具返回JSON时结果解析到Data，否则保留在Content
+func TestGenericAdvancedAgentStructuredToolResult(t *testing.T) {
+	process := func(toolOutput string) *Message {
+		input, err := json.Marshal(toolOutput)
+		require.NoError(t, err)
+		agent := NewGenericAdvancedAgent("world-agent", AgentTypeWorldview, "")
+		agent.SetToolCaller(&staticToolCaller{})
+		agent.SetModel(newStubModel(func(ctx context.Context, prompt string) (string, error) {
+			return `{"tool":"search","input":` + string(input) + `}`, nil
+		}))
+		response, err := agent.Process(context.Background(), NewMessage(MessageTypeRequest, "tester", "world-agent"))
+		require.NoError(t, err)
+		assert.Equal(t, MessageTypeToolResult, response.Type)
+		return response
+	}
+
+	response := process(`{"hits":2,"titles":["北境","南海"]}`)
+	result, ok := response.ToolResult()
+	require.True(t, ok, "JSON结果应解析到Data")
+	assert.Equal(t, map[string]interface{}{
+		"hits":   float64(2),
+		"titles": []interface{}{"北境", "南海"},
+	}, result)
+	assert.Equal(t, `{"hits":2,"titles":["北境","南海"]}`, response.Content, "应保留原始文本")
+
+	response = process("没有找到相关设定")
+	_, ok = response.ToolResult()
+	assert.False(t, ok, "非JSON结果不应写入Data")
+	assert.Equal(t, "没有找到相关设定", response.Content)
+}
+
+// TestGenericAdvancedAgentSessionMemory 测试同一智能体在两个会话写同名键时各自只读到自己的值
+func TestGenericAdvancedAgentSessionMemory(t *testing.T) {
+	agent := NewGenericAdvancedAgent("world-agent", AgentTypeWorldview, "")
+	agent.SetMemoryManager(memory.NewSimpleMemoryStore())
+
+	ctxA := memory.ContextWithSession(context.Background(), "session-a")
+	ctxB := memory.ContextWithSession(context.Background(), "session-b")
+	key := memory.CreateTaggedKey(agent.GetID(), "notes", "hero")
+	require.NoError(t, agent.SaveMemory(ctxA, key, "林舟"))
+	require.NoError(t, agent.SaveMemory(ctxB, key, "沈青"))
+
+	value, err := agent.LoadMemory(ctxA, key)
+	require.NoError(t, err)
+	assert.Equal(t, "林舟", value)
+	value, err = agent.LoadMemory(ctxB, key)
+	require.NoError(t, err)
+	assert.Equal(t, "沈青", value)
+
+	_, err = agent.LoadMemory(context.Background(), key)
+	assert.ErrorIs(t, err, memory.ErrKeyNotFound, "未指定会话时不应读到会话内的记忆")
+
+	memories, err := agent.UseMemory(ctxA, "notes", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hero": "林舟"}, memories)
+	memories, err = agent.UseMemory(ctxB, "notes", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"hero": "沈青"}, memories)
+}
+
+// TestGenericAdvancedAgentHistoryBySession 测试消息历史按 CorrelationID 归入各自会话
+func TestGenericAdvancedAgentHistoryBySession(t *testing.T) {
+	agent := NewGenericAdvancedAgent("world-agent", AgentTypeWorldview, "")
+	agent.SetMemoryManager(memory.NewSimpleMemoryStore())
+	agent.SetModel(newStubModel(func(ctx context.Context, prompt string) (string, error) { return "ok", nil }))
+
+	msg := NewMessage(MessageTypeRequest, "tester", "world-agent")
+	msg.CorrelationID = "session-a"
+	_, err := agent.Process(context.Background(), msg)
+	require.NoError(t, err)
+
+	history, err := agent.UseMemory(memory.ContextWithSession(context.Background(), "session-a"), "history", "")
+	require.NoError(t, err)
+	assert.Contains(t, history, msg.ID)
+
+	history, err = agent.UseMemory(memory.ContextWithSession(context.Background(), "session-b"), "history", "")
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
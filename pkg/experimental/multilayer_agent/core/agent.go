@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"maps"
+	"sync"
 
 	"novelai/pkg/experimental/multilayer_agent/shared/memory"
 	"novelai/pkg/experimental/multilayer_agent/shared/model"
@@ -27,6 +29,19 @@ const (
 	AgentTypeFormatter  AgentType = "formatter"
 )
 
+// builtinAgentTypes 内置的合法智能体类型
+var builtinAgentTypes = []AgentType{
+	AgentTypeStrategy,
+	AgentTypePlanner,
+	AgentTypeEvaluator,
+	AgentTypeWorldview,
+	AgentTypeCharacter,
+	AgentTypePlot,
+	AgentTypeDialogue,
+	AgentTypeBackground,
+	AgentTypeFormatter,
+}
+
 // AgentStatus 定义智能体状态
 type AgentStatus string
 
@@ -111,15 +126,36 @@ type AdvancedAgent interface {
 	LoadMemory(ctx context.Context, key string) (interface{}, error)
 }
 
+// DependencyAware 声明运行依赖的智能体接口（可选）
+// 编排器启动前据此检查工具调用器、记忆管理器是否已配置
+type DependencyAware interface {
+	// RequiresTools 是否需要工具调用器
+	RequiresTools() bool
+
+	// RequiresMemory 是否需要记忆管理器
+	RequiresMemory() bool
+}
+
+// Labeled 带能力标签的智能体接口（可选）
+// 标签比 AgentType 更细，如 genre=scifi，编排器据此按标签筛选和路由
+type Labeled interface {
+	// GetLabels 获取智能体的标签
+	GetLabels() map[string]string
+}
+
 // BaseAgent 智能体基础实现
 // 提供通用功能的默认实现
 type BaseAgent struct {
-	id            string         // 智能体唯一标识
-	agentType     AgentType      // 智能体类型
-	status        AgentStatus    // 当前状态
-	toolCaller    ToolCaller     // 工具调用器（可选）
-	memoryManager memory.Manager // 记忆管理器（可选）
-	llmModel      model.Model    // 语言模型
+	id            string            // 智能体唯一标识
+	agentType     AgentType         // 智能体类型
+	status        AgentStatus       // 当前状态
+	toolCaller    ToolCaller        // 工具调用器（可选）
+	memoryManager memory.Manager    // 记忆管理器（可选）
+	llmModel      model.Model       // 语言模型
+	needTools     bool              // 是否声明需要工具调用器
+	needMemory    bool              // 是否声明需要记忆管理器
+	labelsMutex   sync.RWMutex      // 能力标签的读写锁
+	labels        map[string]string // 能力标签（可选），受 labelsMutex 保护
 }
 
 // NewBaseAgent 创建基础智能体
@@ -172,19 +208,61 @@ func (a *BaseAgent) GetMemoryManager() memory.Manager {
 }
 
 // SaveMemory 实现MemoryEnabledAgent接口
+// ctx 中带有会话ID时，键按会话隔离
 func (a *BaseAgent) SaveMemory(ctx context.Context, key string, value interface{}) error {
 	if a.memoryManager == nil {
 		return nil // 没有记忆管理器时静默忽略
 	}
-	return a.memoryManager.Save(ctx, key, value)
+	return a.memoryManager.Save(ctx, memory.SessionKey(memory.SessionFromContext(ctx), key), value)
 }
 
 // LoadMemory 实现MemoryEnabledAgent接口
+// ctx 中带有会话ID时只能读到该会话下保存的记忆
 func (a *BaseAgent) LoadMemory(ctx context.Context, key string) (interface{}, error) {
 	if a.memoryManager == nil {
 		return nil, nil // 没有记忆管理器时返回nil
 	}
-	return a.memoryManager.Load(ctx, key)
+	return a.memoryManager.Load(ctx, memory.SessionKey(memory.SessionFromContext(ctx), key))
+}
+
+// SetRequirements 声明智能体运行所需的依赖
+func (a *BaseAgent) SetRequirements(needTools, needMemory bool) {
+	a.needTools = needTools
+	a.needMemory = needMemory
+}
+
+// RequiresTools 实现DependencyAware接口
+func (a *BaseAgent) RequiresTools() bool {
+	return a.needTools
+}
+
+// RequiresMemory 实现DependencyAware接口
+func (a *BaseAgent) RequiresMemory() bool {
+	return a.needMemory
+}
+
+// SetLabel 设置单个能力标签
+func (a *BaseAgent) SetLabel(key, value string) {
+	a.labelsMutex.Lock()
+	defer a.labelsMutex.Unlock()
+	if a.labels == nil {
+		a.labels = make(map[string]string)
+	}
+	a.labels[key] = value
+}
+
+// SetLabels 替换全部能力标签
+func (a *BaseAgent) SetLabels(labels map[string]string) {
+	a.labelsMutex.Lock()
+	defer a.labelsMutex.Unlock()
+	a.labels = maps.Clone(labels)
+}
+
+// GetLabels 实现Labeled接口，返回标签副本
+func (a *BaseAgent) GetLabels() map[string]string {
+	a.labelsMutex.RLock()
+	defer a.labelsMutex.RUnlock()
+	return maps.Clone(a.labels)
 }
 
 // GetModel 获取智能体使用的语言模型
@@ -232,7 +310,11 @@ type BaseAdvancedAgent struct {
 // NewBaseAdvancedAgent 创建基础高级智能体
 func NewBaseAdvancedAgent(id string, agentType AgentType) *BaseAdvancedAgent {
 	return &BaseAdvancedAgent{
-		BaseAgent: *NewBaseAgent(id, agentType),
+		BaseAgent: BaseAgent{
+			id:        id,
+			agentType: agentType,
+			status:    AgentStatusIdle,
+		},
 	}
 }
 
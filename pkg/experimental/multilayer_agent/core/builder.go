@@ -0,0 +1,231 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tmc/langchaingo/tools"
+
+	"novelai/pkg/experimental/multilayer_agent/shared/memory"
+	"novelai/pkg/experimental/multilayer_agent/shared/model"
+)
+
+// ErrToolCallerRequired 配置中的智能体声明了工具，但装配时未提供工具调用器
+var ErrToolCallerRequired = errors.New("智能体声明了工具但未提供工具调用器")
+
+// ModelConfig 声明式的模型配置
+type ModelConfig struct {
+	Type        model.ModelType `json:"type"`                  // 模型类型，如 ollama、deepseek
+	Name        string          `json:"name"`                  // 模型名称
+	BaseURL     string          `json:"base_url,omitempty"`    // 模型端点URL
+	APIToken    string          `json:"api_token,omitempty"`   // API令牌
+	Temperature float64         `json:"temperature,omitempty"` // 默认温度
+	MaxTokens   int             `json:"max_tokens,omitempty"`  // 默认最大token数
+}
+
+// options 转换为模型工厂使用的选项
+func (c *ModelConfig) options() model.ModelOptions {
+	return model.ModelOptions{
+		ModelName:          c.Name,
+		BaseURL:            c.BaseURL,
+		APIToken:           c.APIToken,
+		DefaultTemperature: c.Temperature,
+		DefaultMaxTokens:   c.MaxTokens,
+	}
+}
+
+// AgentConfig 声明式的智能体配置
+type AgentConfig struct {
+	ID     string       `json:"id"`               // 智能体ID
+	Type   AgentType    `json:"type"`             // 智能体类型
+	Prompt string       `json:"prompt,omitempty"` // 提示模板，为空时使用该类型的默认提示词
+	Model  *ModelConfig `json:"model,omitempty"`  // 模型配置，为空时使用系统默认模型
+	Tools  []string     `json:"tools,omitempty"`  // 所需工具名称，需由装配时提供的工具调用器提供
+	Memory bool         `json:"memory,omitempty"` // 是否需要记忆
+
+	Labels map[string]string `json:"labels,omitempty"` // 能力标签，如 {"genre": "scifi"}，用于按标签筛选与路由
+}
+
+// OrchestratorSettings 声明式配置中可调整的编排器参数，未设置的项使用默认配置
+type OrchestratorSettings struct {
+	MaxConcurrentAgents int    `json:"max_concurrent_agents,omitempty"` // 最大并发智能体数
+	MessageQueueSize    int    `json:"message_queue_size,omitempty"`    // 消息队列大小
+	ProcessTimeout      string `json:"process_timeout,omitempty"`       // 处理超时时间，如 "30s"
+	MaxHops             int    `json:"max_hops,omitempty"`              // 智能体间最大转发跳数
+
+	AllowedRoutes map[AgentType][]AgentType `json:"allowed_routes,omitempty"` // 按智能体类型设置的通信白名单
+	ToolWhitelist ToolWhitelist             `json:"tool_whitelist,omitempty"` // 按智能体类型设置的可用工具白名单
+}
+
+// SystemConfig 多智能体系统的声明式配置
+type SystemConfig struct {
+	Orchestrator OrchestratorSettings `json:"orchestrator"`            // 编排器参数
+	DefaultModel *ModelConfig         `json:"default_model,omitempty"` // 未单独配置模型的智能体使用的模型
+	MemoryType   memory.MemoryType    `json:"memory_type,omitempty"`   // 记忆存储类型，为空时使用简单内存存储
+	Agents       []AgentConfig        `json:"agents"`                  // 智能体列表
+}
+
+// BuildOptions 装配时注入的运行时依赖
+type BuildOptions struct {
+	ModelFactory model.ModelFactory // 模型工厂，为空时使用默认工厂
+	ToolCaller   ToolCaller         // 工具调用器，声明了工具的智能体必须提供
+}
+
+// LoadSystemConfig 解析JSON格式的声明式配置
+func LoadSystemConfig(data []byte) (*SystemConfig, error) {
+	var cfg SystemConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析系统配置失败: %w", err)
+	}
+	return &cfg, nil
+}
+
+// LoadSystemConfigFile 从文件读取JSON格式的声明式配置
+func LoadSystemConfigFile(path string) (*SystemConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取系统配置文件失败: %w", err)
+	}
+	return LoadSystemConfig(data)
+}
+
+// BuildOrchestratorFromConfig 按声明式配置一次性装配编排器
+// 依次创建各智能体、设置模型、工具与记忆并注册，返回尚未启动的编排器；
+// 任一智能体装配失败时返回错误，错误信息中包含智能体ID
+func BuildOrchestratorFromConfig(cfg *SystemConfig, opts *BuildOptions) (*Orchestrator, error) {
+	if cfg == nil {
+		return nil, errors.New("系统配置不能为空")
+	}
+	if opts == nil {
+		opts = &BuildOptions{}
+	}
+
+	orchestratorConfig, err := cfg.orchestratorConfig()
+	if err != nil {
+		return nil, err
+	}
+	o := NewOrchestrator(orchestratorConfig)
+	if opts.ModelFactory != nil {
+		o.modelFactory = opts.ModelFactory
+	}
+
+	var memoryManager memory.Manager
+	for _, agentCfg := range cfg.Agents {
+		if agentCfg.Memory && memoryManager == nil {
+			memType := cfg.MemoryType
+			if memType == "" {
+				memType = memory.MemoryTypeSimple
+			}
+			memoryManager = memory.NewMemoryManager(memType)
+		}
+
+		// 声明了白名单外的工具视为配置错误，而不是注册时静默过滤
+		for _, name := range agentCfg.Tools {
+			if !orchestratorConfig.ToolWhitelist.Allows(agentCfg.Type, name) {
+				return nil, fmt.Errorf("装配智能体 %s 失败: %w: %s", agentCfg.ID, ErrToolNotAllowed, name)
+			}
+		}
+
+		agent, err := buildAgent(o.modelFactory, cfg.DefaultModel, agentCfg, opts.ToolCaller, memoryManager)
+		if err != nil {
+			return nil, fmt.Errorf("装配智能体 %s 失败: %w", agentCfg.ID, err)
+		}
+		if err := o.RegisterAgent(agent); err != nil {
+			return nil, fmt.Errorf("注册智能体 %s 失败: %w", agentCfg.ID, err)
+		}
+	}
+
+	return o, nil
+}
+
+// orchestratorConfig 在默认配置上叠加声明式配置中的编排器参数
+func (cfg *SystemConfig) orchestratorConfig() (*OrchestratorConfig, error) {
+	config := DefaultOrchestratorConfig()
+	settings := cfg.Orchestrator
+	if settings.MaxConcurrentAgents > 0 {
+		config.MaxConcurrentAgents = settings.MaxConcurrentAgents
+	}
+	if settings.MessageQueueSize > 0 {
+		config.MessageQueueSize = settings.MessageQueueSize
+	}
+	if settings.ProcessTimeout != "" {
+		timeout, err := time.ParseDuration(settings.ProcessTimeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("无效的处理超时时间: %s", settings.ProcessTimeout)
+		}
+		config.ProcessTimeout = timeout
+	}
+	if settings.MaxHops > 0 {
+		config.MaxHops = settings.MaxHops
+	}
+	config.AllowedRoutes = settings.AllowedRoutes
+	config.ToolWhitelist = settings.ToolWhitelist
+	if cfg.DefaultModel != nil {
+		config.DefaultModelType = cfg.DefaultModel.Type
+		config.DefaultModelName = cfg.DefaultModel.Name
+	}
+	return config, nil
+}
+
+// buildAgent 按单个智能体配置创建智能体
+func buildAgent(factory model.ModelFactory, defaultModel *ModelConfig, cfg AgentConfig, caller ToolCaller, memoryManager memory.Manager) (Agent, error) {
+	if cfg.ID == "" {
+		return nil, errors.New("智能体ID不能为空")
+	}
+
+	agent := NewGenericAdvancedAgent(cfg.ID, cfg.Type, cfg.Prompt)
+
+	modelCfg := cfg.Model
+	if modelCfg == nil {
+		modelCfg = defaultModel
+	}
+	// 均未配置时留空，由 RegisterAgent 按编排器默认模型补全
+	if modelCfg != nil {
+		m, err := factory.CreateModel(modelCfg.Type, modelCfg.options())
+		if err != nil {
+			return nil, fmt.Errorf("创建模型失败: %w", err)
+		}
+		agent.SetModel(m)
+	}
+
+	if len(cfg.Tools) > 0 {
+		if caller == nil {
+			return nil, ErrToolCallerRequired
+		}
+		selected, err := selectTools(caller.GetAvailableTools(), cfg.Tools)
+		if err != nil {
+			return nil, err
+		}
+		agent.SetToolCaller(caller)
+		agent.SetAvailableTools(selected)
+	}
+
+	if cfg.Memory {
+		agent.SetMemoryManager(memoryManager)
+	}
+	agent.SetRequirements(len(cfg.Tools) > 0, cfg.Memory)
+	agent.SetLabels(cfg.Labels)
+
+	return agent, nil
+}
+
+// selectTools 按名称从可用工具中挑选智能体所需的工具
+func selectTools(available []tools.Tool, names []string) ([]tools.Tool, error) {
+	byName := make(map[string]tools.Tool, len(available))
+	for _, tool := range available {
+		byName[tool.Name()] = tool
+	}
+
+	selected := make([]tools.Tool, 0, len(names))
+	for _, name := range names {
+		tool, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("工具不存在: %s", name)
+		}
+		selected = append(selected, tool)
+	}
+	return selected, nil
+}
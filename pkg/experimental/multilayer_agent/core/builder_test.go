@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"novelai/pkg/experimental/multilayer_agent/shared/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// recordingModelFactory 记录创建请求并返回桩模型的模型工厂
+type recordingModelFactory struct {
+	created []model.ModelOptions
+}
+
+// CreateModel 实现ModelFactory接口
+func (f *recordingModelFactory) CreateModel(modelType model.ModelType, options model.ModelOptions) (model.Model, error) {
+	if modelType != model.ModelTypeOllama && modelType != model.ModelTypeDeepSeek {
+		return nil, errors.New("不支持的模型类型")
+	}
+	f.created = append(f.created, options)
+	stub := newStubModel(func(ctx context.Context, prompt string) (string, error) { return "ok", nil })
+	stub.Type = modelType
+	stub.Name = options.ModelName
+	return stub, nil
+}
+
+// namedTool 只有名称的测试工具
+type namedTool struct{ name string }
+
+func (t namedTool) Name() string        { return t.name }
+func (t namedTool) Description() string { return "测试工具" + t.name }
+func (t namedTool) Call(ctx context.Context, input string) (string, error) {
+	return input, nil
+}
+
+// staticToolCaller 提供固定工具列表的工具调用器
+type staticToolCaller struct{ tools []tools.Tool }
+
+func (c *staticToolCaller) Call(ctx context.Context, toolName string, input string) (string, error) {
+	return input, nil
+}
+
+func (c *staticToolCaller) GetAvailableTools() []tools.Tool { return c.tools }
+
+const testSystemConfig = `{
+	"orchestrator": {"max_concurrent_agents": 2, "process_timeout": "5s", "max_hops": 4, "allowed_routes": {"worldview": ["plot"]}},
+	"default_model": {"type": "ollama", "name": "mistral"},
+	"agents": [
+		{"id": "world-1", "type": "worldview", "memory": true},
+		{"id": "plot-1", "type": "plot", "prompt": "你负责情节：%s %s %s %s %s", "tools": ["search"]},
+		{"id": "dialogue-1", "type": "dialogue", "model": {"type": "deepseek", "name": "deepseek-chat", "api_token": "k"}, "labels": {"genre": "scifi"}}
+	]
+}`
+
+// TestBuildOrchestratorFromConfig 测试按配置装配出预期数量和类型的智能体
+func TestBuildOrchestratorFromConfig(t *testing.T) {
+	cfg, err := LoadSystemConfig([]byte(testSystemConfig))
+	require.NoError(t, err)
+
+	factory := &recordingModelFactory{}
+	caller := &staticToolCaller{tools: []tools.Tool{namedTool{"search"}, namedTool{"calc"}}}
+	o, err := BuildOrchestratorFromConfig(cfg, &BuildOptions{ModelFactory: factory, ToolCaller: caller})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, o.config.MaxConcurrentAgents)
+	assert.Equal(t, 4, o.config.MaxHops)
+	assert.Equal(t, []AgentType{AgentTypePlot}, o.config.AllowedRoutes[AgentTypeWorldview])
+	assert.Len(t, factory.created, 3, "每个智能体各创建一个模型")
+
+	for id, agentType := range map[string]AgentType{"world-1": AgentTypeWorldview, "plot-1": AgentTypePlot, "dialogue-1": AgentTypeDialogue} {
+		agent, ok := o.GetAgent(id)
+		require.True(t, ok, "智能体 %s 应已注册", id)
+		assert.Equal(t, agentType, agent.GetType())
+	}
+
+	world, _ := o.GetAgent("world-1")
+	assert.NotNil(t, world.(*GenericAdvancedAgent).GetMemoryManager(), "声明记忆的智能体应配置记忆管理器")
+	assert.Equal(t, "mistral", world.GetModel().ModelName(), "未单独配置模型时使用默认模型")
+
+	plot, _ := o.GetAgent("plot-1")
+	plotTools := plot.(*GenericAdvancedAgent).GetAvailableTools()
+	require.Len(t, plotTools, 1)
+	assert.Equal(t, "search", plotTools[0].Name(), "只应提供声明的工具")
+
+	dialogue, _ := o.GetAgent("dialogue-1")
+	assert.Equal(t, model.ModelTypeDeepSeek, dialogue.GetModel().ModelType())
+	assert.Equal(t, []Agent{dialogue}, o.GetAgentsByLabel("genre", "scifi"), "应按配置为智能体打标签")
+
+	// 装配出的依赖满足声明，编排器可以直接启动
+	require.NoError(t, o.Start())
+	require.NoError(t, o.Stop())
+}
+
+// TestBuildOrchestratorFromConfigErrors 测试配置错误时返回带智能体ID的错误
+func TestBuildOrchestratorFromConfigErrors(t *testing.T) {
+	factory := &recordingModelFactory{}
+
+	_, err := BuildOrchestratorFromConfig(&SystemConfig{
+		Agents: []AgentConfig{{ID: "plot-1", Type: AgentTypePlot, Tools: []string{"search"}}},
+	}, &BuildOptions{ModelFactory: factory})
+	assert.ErrorIs(t, err, ErrToolCallerRequired)
+	assert.Contains(t, err.Error(), "plot-1")
+
+	_, err = BuildOrchestratorFromConfig(&SystemConfig{
+		Agents: []AgentConfig{{ID: "plot-1", Type: AgentTypePlot, Tools: []string{"missing"}}},
+	}, &BuildOptions{ModelFactory: factory, ToolCaller: &staticToolCaller{}})
+	assert.ErrorContains(t, err, "missing")
+
+	_, err = BuildOrchestratorFromConfig(&SystemConfig{
+		Agents: []AgentConfig{{ID: "x", Type: AgentType("unknown")}},
+	}, &BuildOptions{ModelFactory: factory})
+	assert.ErrorContains(t, err, "未知的智能体类型")
+
+	_, err = BuildOrchestratorFromConfig(&SystemConfig{
+		Orchestrator: OrchestratorSettings{ProcessTimeout: "abc"},
+	}, nil)
+	assert.Error(t, err)
+
+	_, err = LoadSystemConfig([]byte("{"))
+	assert.Error(t, err)
+}
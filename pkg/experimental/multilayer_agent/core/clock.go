@@ -0,0 +1,21 @@
+package core
+
+import "time"
+
+// Clock 时钟接口
+// 智能体通过 Clock 获取当前时间，测试时可注入可控的假时钟
+type Clock interface {
+	// Now 返回当前时间
+	Now() time.Time
+}
+
+// systemClock 基于系统时间的真实时钟
+type systemClock struct{}
+
+// Now 实现Clock接口
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SystemClock 默认使用的真实时钟
+var SystemClock Clock = systemClock{}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"sync"
+)
+
+// DefaultDedupSize 默认记录的近期消息ID数量
+const DefaultDedupSize = 1000
+
+// MetadataDuplicate 重复投递的消息返回的响应中携带的元数据键
+const MetadataDuplicate = "duplicate"
+
+// dedupEntry 消息ID对应的处理记录
+type dedupEntry struct {
+	done     chan struct{} // 首次处理完成后关闭
+	response *Message      // 首次处理的响应
+}
+
+// messageDeduper 近期处理过的消息ID的有界集合
+// 超出容量时按先进先出淘汰最早的记录；处理失败的消息不记录，允许重试
+type messageDeduper struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*dedupEntry
+	order   []string
+}
+
+// newMessageDeduper 创建消息去重器，size 小于等于0时使用默认值
+func newMessageDeduper(size int) *messageDeduper {
+	if size <= 0 {
+		size = DefaultDedupSize
+	}
+	return &messageDeduper{
+		size:    size,
+		entries: make(map[string]*dedupEntry, size),
+		order:   make([]string, 0, size),
+	}
+}
+
+// dedupKey 返回消息的去重键，由接收方与消息ID组成
+func dedupKey(msg *Message) string {
+	return msg.To + "/" + msg.ID
+}
+
+// begin 登记消息ID
+// 首次出现时返回 (entry, true)，调用方处理完后需调用 finish；
+// 重复出现时返回已有记录与 false
+func (d *messageDeduper) begin(id string) (*dedupEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok := d.entries[id]; ok {
+		return entry, false
+	}
+
+	if len(d.order) >= d.size {
+		oldest := d.order[0]
+		d.order = d.order[1:]
+		delete(d.entries, oldest)
+	}
+	entry := &dedupEntry{done: make(chan struct{})}
+	d.entries[id] = entry
+	d.order = append(d.order, id)
+	return entry, true
+}
+
+// finish 记录消息处理结果并唤醒等待中的重复消息
+// 处理失败时移除记录，后续重试会重新处理
+func (d *messageDeduper) finish(id string, entry *dedupEntry, response *Message, err error) {
+	d.mu.Lock()
+	if err != nil {
+		if current, ok := d.entries[id]; ok && current == entry {
+			delete(d.entries, id)
+			for i, orderedID := range d.order {
+				if orderedID == id {
+					d.order = append(d.order[:i], d.order[i+1:]...)
+					break
+				}
+			}
+		}
+	} else {
+		entry.response = response
+	}
+	d.mu.Unlock()
+	close(entry.done)
+}
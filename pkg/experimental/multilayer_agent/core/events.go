@@ -0,0 +1,102 @@
+package core
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType 编排器事件类型
+type EventType string
+
+const (
+	EventAgentRegistered   EventType = "agent_registered"   // 智能体注册
+	EventAgentUnregistered EventType = "agent_unregistered" // 智能体注销
+	EventMessageProcessed  EventType = "message_processed"  // 消息处理成功
+	EventMessageFailed     EventType = "message_failed"     // 消息处理失败
+	EventStarted           EventType = "orchestrator_started"
+	EventStopped           EventType = "orchestrator_stopped"
+)
+
+// DefaultEventBufferSize 每个订阅者的事件缓冲大小
+const DefaultEventBufferSize = 64
+
+// Event 编排器内部事件
+type Event struct {
+	Type      EventType     // 事件类型
+	AgentID   string        // 相关智能体ID，启停事件为空
+	MessageID string        // 相关消息ID，仅消息事件有值
+	LogID     string        // 处理日志ID，仅在启用处理日志时的消息事件有值
+	Duration  time.Duration // 消息处理耗时，仅消息事件有值
+	Error     error         // 处理错误，仅失败事件有值
+	Timestamp time.Time     // 事件发生时间
+}
+
+// eventBus 事件总线
+// 每个订阅者拥有独立的带缓冲通道，缓冲已满时丢弃该订阅者的新事件，保证慢订阅者不阻塞主流程
+type eventBus struct {
+	mu          sync.RWMutex
+	subscribers map[<-chan Event]chan Event
+	dropped     uint64 // 因订阅者缓冲已满被丢弃的事件数
+}
+
+// newEventBus 创建事件总线
+func newEventBus() *eventBus {
+	return &eventBus{subscribers: make(map[<-chan Event]chan Event)}
+}
+
+// subscribe 新增订阅者
+func (b *eventBus) subscribe(bufferSize int) <-chan Event {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+	ch := make(chan Event, bufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers[ch] = ch
+	return ch
+}
+
+// unsubscribe 移除订阅者并关闭其通道
+func (b *eventBus) unsubscribe(ch <-chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if sub, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(sub)
+	}
+}
+
+// publish 向所有订阅者非阻塞地发布事件
+func (b *eventBus) publish(event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subscribers {
+		select {
+		case sub <- event:
+		default:
+			atomic.AddUint64(&b.dropped, 1)
+		}
+	}
+}
+
+// droppedCount 返回被丢弃的事件数
+func (b *eventBus) droppedCount() uint64 {
+	return atomic.LoadUint64(&b.dropped)
+}
+
+// Subscribe 订阅编排器事件
+// 每个订阅者各收一份事件；订阅者消费过慢导致缓冲已满时，新事件会被丢弃而不会阻塞编排器
+func (o *Orchestrator) Subscribe() <-chan Event {
+	return o.events.subscribe(o.config.EventBufferSize)
+}
+
+// Unsubscribe 取消订阅并关闭对应的事件通道
+func (o *Orchestrator) Unsubscribe(ch <-chan Event) {
+	o.events.unsubscribe(ch)
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectEvents 从订阅通道读取指定数量的事件
+func collectEvents(t *testing.T, ch <-chan Event, n int) []Event {
+	events := make([]Event, 0, n)
+	for len(events) < n {
+		select {
+		case e := <-ch:
+			events = append(events, e)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("等待事件超时，已收到 %d 个: %+v", len(events), events)
+		}
+	}
+	return events
+}
+
+// TestSubscribeEventSequence 测试订阅后能按顺序收到注册、消息处理、启停事件
+func TestSubscribeEventSequence(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+	first := o.Subscribe()
+	second := o.Subscribe()
+
+	require.NoError(t, o.RegisterAgent(newFuncAgent("echo-agent", AgentTypePlot, echoProcess("echo-agent"))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("fail-agent", AgentTypeDialogue, func(ctx context.Context, msg *Message) (*Message, error) {
+		return nil, errors.New("处理失败")
+	})))
+	require.NoError(t, o.Start())
+
+	_, err := sendTestMessage(t, o, "echo-agent", "你好")
+	require.NoError(t, err)
+	_, err = sendTestMessage(t, o, "fail-agent", "你好")
+	require.Error(t, err)
+
+	require.NoError(t, o.UnregisterAgent("fail-agent"))
+	require.NoError(t, o.Stop())
+
+	want := []EventType{
+		EventAgentRegistered,
+		EventAgentRegistered,
+		EventStarted,
+		EventMessageProcessed,
+		EventMessageFailed,
+		EventAgentUnregistered,
+		EventStopped,
+	}
+	for _, ch := range []<-chan Event{first, second} {
+		events := collectEvents(t, ch, len(want))
+		types := make([]EventType, len(events))
+		for i, e := range events {
+			types[i] = e.Type
+			assert.False(t, e.Timestamp.IsZero(), "事件应带时间戳")
+		}
+		assert.Equal(t, want, types, "每个订阅者都应收到完整的事件序列")
+		assert.Equal(t, "echo-agent", events[3].AgentID)
+		assert.NotEmpty(t, events[3].MessageID)
+		assert.Equal(t, "fail-agent", events[4].AgentID)
+		assert.Error(t, events[4].Error)
+	}
+}
+
+// TestSlowSubscriberDoesNotBlock 测试慢订阅者缓冲满后丢弃事件而不阻塞
+func TestSlowSubscriberDoesNotBlock(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+	o.config.EventBufferSize = 1
+	slow := o.Subscribe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 5; i++ {
+			id := string(rune('a' + i))
+			_ = o.RegisterAgent(newFuncAgent(id, AgentTypePlot, echoProcess(id)))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("慢订阅者阻塞了注册流程")
+	}
+
+	assert.Len(t, slow, 1)
+	assert.Equal(t, uint64(4), o.GetStatus()["events_dropped"])
+
+	o.Unsubscribe(slow)
+	<-slow
+	_, open := <-slow
+	assert.False(t, open, "取消订阅后通道应被关闭")
+}
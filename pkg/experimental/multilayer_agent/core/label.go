@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrNoLabeledAgent 没有带指定标签的智能体
+var ErrNoLabeledAgent = errors.New("没有找到带指定标签的智能体")
+
+// agentLabel 读取智能体的标签值，未实现 Labeled 接口时视为无标签
+func agentLabel(agent Agent, key string) (string, bool) {
+	labeled, ok := agent.(Labeled)
+	if !ok {
+		return "", false
+	}
+	value, ok := labeled.GetLabels()[key]
+	return value, ok
+}
+
+// GetAgentsByLabel 获取标签 key 的值为 value 的所有智能体，按ID排序
+func (o *Orchestrator) GetAgentsByLabel(key, value string) []Agent {
+	o.agentMutex.RLock()
+	defer o.agentMutex.RUnlock()
+
+	agents := make([]Agent, 0)
+	for _, agent := range o.agents {
+		if v, ok := agentLabel(agent, key); ok && v == value {
+			agents = append(agents, agent)
+		}
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].GetID() < agents[j].GetID()
+	})
+	return agents
+}
+
+// SendMessageByLabel 按标签路由消息
+// 在标签匹配的智能体中优先选择空闲的一个（均不空闲时选第一个），
+// 以消息副本发给它并返回最终响应，转发与通信白名单规则与 SendMessage 一致
+func (o *Orchestrator) SendMessageByLabel(ctx context.Context, key, value string, msg *Message) (*Message, error) {
+	candidates := o.GetAgentsByLabel(key, value)
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("%w: %s=%s", ErrNoLabeledAgent, key, value)
+	}
+
+	target := candidates[0]
+	for _, agent := range candidates {
+		if agent.GetStatus() == AgentStatusIdle {
+			target = agent
+			break
+		}
+	}
+
+	msgCopy := msg.Clone()
+	msgCopy.To = target.GetID()
+	return o.SendMessage(ctx, msgCopy)
+}
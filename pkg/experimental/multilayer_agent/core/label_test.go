@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// agentIDs 返回智能体ID列表
+func agentIDs(agents []Agent) []string {
+	ids := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		ids = append(ids, agent.GetID())
+	}
+	return ids
+}
+
+// newLabeledAgent 创建带标签的测试智能体
+func newLabeledAgent(id string, agentType AgentType, labels map[string]string) *funcAgent {
+	agent := newFuncAgent(id, agentType, echoProcess(id))
+	agent.SetLabels(labels)
+	return agent
+}
+
+// TestGetAgentsByLabel 测试打标签后按标签精确筛选出目标集合
+func TestGetAgentsByLabel(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	require.NoError(t, o.RegisterAgent(newLabeledAgent("plot-scifi", AgentTypePlot, map[string]string{"genre": "scifi", "tone": "dark"})))
+	require.NoError(t, o.RegisterAgent(newLabeledAgent("dialogue-scifi", AgentTypeDialogue, map[string]string{"genre": "scifi"})))
+	require.NoError(t, o.RegisterAgent(newLabeledAgent("plot-fantasy", AgentTypePlot, map[string]string{"genre": "fantasy"})))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-plain", AgentTypePlot, echoProcess("plot-plain"))))
+
+	assert.Equal(t, []string{"dialogue-scifi", "plot-scifi"}, agentIDs(o.GetAgentsByLabel("genre", "scifi")))
+	assert.Equal(t, []string{"plot-fantasy"}, agentIDs(o.GetAgentsByLabel("genre", "fantasy")))
+	assert.Equal(t, []string{"plot-scifi"}, agentIDs(o.GetAgentsByLabel("tone", "dark")))
+	assert.Empty(t, o.GetAgentsByLabel("genre", "SciFi"), "标签值需精确匹配")
+	assert.Empty(t, o.GetAgentsByLabel("style", ""), "未设置的标签不匹配空值")
+
+	// 标签以副本返回，修改不影响智能体
+	agent, _ := o.GetAgent("plot-fantasy")
+	agent.(Labeled).GetLabels()["genre"] = "scifi"
+	assert.Len(t, o.GetAgentsByLabel("genre", "scifi"), 2)
+
+	// 注销后不再参与筛选
+	require.NoError(t, o.UnregisterAgent("plot-scifi"))
+	assert.Equal(t, []string{"dialogue-scifi"}, agentIDs(o.GetAgentsByLabel("genre", "scifi")))
+}
+
+// TestSendMessageByLabel 测试按标签路由到匹配的智能体，优先选择空闲的
+func TestSendMessageByLabel(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	busy := newLabeledAgent("plot-a", AgentTypePlot, map[string]string{"genre": "scifi"})
+	require.NoError(t, o.RegisterAgent(busy))
+	require.NoError(t, o.RegisterAgent(newLabeledAgent("plot-b", AgentTypePlot, map[string]string{"genre": "scifi"})))
+	require.NoError(t, o.RegisterAgent(newLabeledAgent("plot-c", AgentTypePlot, map[string]string{"genre": "fantasy"})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+	busy.SetStatus(AgentStatusWorking)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg := NewMessage(MessageTypeRequest, "tester", "")
+	msg.Content = "写一段星际航行"
+
+	resp, err := o.SendMessageByLabel(ctx, "genre", "scifi", msg)
+	require.NoError(t, err)
+	assert.Equal(t, "plot-b", resp.From, "应路由到空闲的匹配智能体")
+	assert.Equal(t, "写一段星际航行", resp.Content)
+	assert.Empty(t, msg.To, "不应修改调用方的消息")
+
+	_, err = o.SendMessageByLabel(ctx, "genre", "horror", msg)
+	assert.ErrorIs(t, err, ErrNoLabeledAgent)
+}
+
+// TestAgentLabelsConcurrent 测试并发设置与读取标签时不发生数据竞争
+func TestAgentLabelsConcurrent(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	agent := newLabeledAgent("plot-scifi", AgentTypePlot, map[string]string{"genre": "scifi"})
+	require.NoError(t, o.RegisterAgent(agent))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			agent.SetLabel(fmt.Sprintf("k%d", i), "v")
+			agent.SetLabels(map[string]string{"genre": "scifi"})
+		}(i)
+		go func() {
+			defer wg.Done()
+			_ = agent.GetLabels()
+			_ = o.GetAgentsByLabel("genre", "scifi")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, "scifi", agent.GetLabels()["genre"])
+}
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+// roleForMessage 根据消息类型确定对应的LLM角色
+// 通知映射为 system，响应类与错误消息视为智能体输出映射为 assistant，其余映射为 user
+func roleForMessage(msg *Message) llms.ChatMessageType {
+	switch {
+	case msg.Type == MessageTypeNotification:
+		return llms.ChatMessageTypeSystem
+	case msg.IsResponse() || msg.IsError():
+		return llms.ChatMessageTypeAI
+	default:
+		return llms.ChatMessageTypeHuman
+	}
+}
+
+// ToLLMMessages 将一串会话消息转换为 langchaingo 的消息序列，供 GenerateContent 使用
+// 相邻的同角色消息会合并为一条，保证 user/assistant 角色交替；nil 与空内容的消息被忽略
+func ToLLMMessages(history []*Message) []llms.MessageContent {
+	result := make([]llms.MessageContent, 0, len(history))
+	texts := make([]string, 0, len(history))
+	var currentRole llms.ChatMessageType
+
+	flush := func() {
+		if len(texts) == 0 {
+			return
+		}
+		result = append(result, llms.TextParts(currentRole, strings.Join(texts, "\n\n")))
+		texts = texts[:0]
+	}
+
+	for _, msg := range history {
+		if msg == nil || msg.Content == "" {
+			continue
+		}
+		role := roleForMessage(msg)
+		if role != currentRole {
+			flush()
+			currentRole = role
+		}
+		texts = append(texts, msg.Content)
+	}
+	flush()
+
+	return result
+}
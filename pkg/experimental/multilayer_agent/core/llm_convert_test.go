@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// newHistoryMessage 创建测试用会话消息
+func newHistoryMessage(msgType MessageType, from, content string) *Message {
+	msg := NewMessage(msgType, from, "")
+	msg.Content = content
+	return msg
+}
+
+// TestToLLMMessages 测试消息链映射为交替角色的LLM消息
+func TestToLLMMessages(t *testing.T) {
+	history := []*Message{
+		newHistoryMessage(MessageTypeNotification, "orchestrator", "你是情节智能体"),
+		newHistoryMessage(MessageTypeRequest, "user", "写一个开头"),
+		newHistoryMessage(MessageTypeResponse, "plot-agent", "从前有座山"),
+		newHistoryMessage(MessageTypeQuery, "user", "山上有什么"),
+		newHistoryMessage(MessageTypeCommand, "user", "再详细一点"),
+		nil,
+		newHistoryMessage(MessageTypeRequest, "user", ""),
+		newHistoryMessage(MessageTypeReport, "plot-agent", "山上有座庙"),
+	}
+
+	messages := ToLLMMessages(history)
+	require.Len(t, messages, 5)
+
+	expected := []struct {
+		role llms.ChatMessageType
+		text string
+	}{
+		{llms.ChatMessageTypeSystem, "你是情节智能体"},
+		{llms.ChatMessageTypeHuman, "写一个开头"},
+		{llms.ChatMessageTypeAI, "从前有座山"},
+		{llms.ChatMessageTypeHuman, "山上有什么\n\n再详细一点"},
+		{llms.ChatMessageTypeAI, "山上有座庙"},
+	}
+	for i, want := range expected {
+		assert.Equal(t, want.role, messages[i].Role, "第%d条消息角色错误", i)
+		require.Len(t, messages[i].Parts, 1)
+		assert.Equal(t, llms.TextContent{Text: want.text}, messages[i].Parts[0], "第%d条消息内容错误", i)
+	}
+
+	assert.Empty(t, ToLLMMessages(nil))
+}
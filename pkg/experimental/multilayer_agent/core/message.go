@@ -2,6 +2,7 @@ package core
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 )
 
@@ -57,6 +58,9 @@ type Message struct {
 	// 关联信息
 	CorrelationID string `json:"correlation_id,omitempty"` // 关联ID，用于追踪相关消息
 	ReplyTo       string `json:"reply_to,omitempty"`       // 回复的消息ID
+
+	// 转发信息
+	Hops int `json:"hops,omitempty"` // 转发跳数，每经一次智能体间转发加1
 }
 
 // NewMessage 创建新消息
@@ -130,6 +134,13 @@ func (m *Message) IsResponse() bool {
 	return m.Type == MessageTypeResponse || m.Type == MessageTypeReport || m.Type == MessageTypeToolResult
 }
 
+// IsPriority 判断消息是否应被优先调度
+// 回复消息（响应类型）通常意味着有调用方正在同步等待，高优先级和紧急消息同样优先；
+// 转发消息虽然带 ReplyTo，但仍是请求，不会因此插队
+func (m *Message) IsPriority() bool {
+	return m.Type == MessageTypeResponse || m.Priority == MessagePriorityHigh || m.Priority == MessagePriorityUrgent
+}
+
 // IsError 判断是否为错误消息
 func (m *Message) IsError() bool {
 	return m.Type == MessageTypeError
@@ -149,6 +160,7 @@ func (m *Message) Clone() *Message {
 		Content:       m.Content,
 		CorrelationID: m.CorrelationID,
 		ReplyTo:       m.ReplyTo,
+		Hops:          m.Hops,
 	}
 
 	// 深拷贝Data
@@ -170,6 +182,82 @@ func (m *Message) Clone() *Message {
 	return clone
 }
 
+// Forward 创建转发给其他智能体的消息
+// 复制原消息内容并生成新ID，转发跳数在原消息基础上加1
+func (m *Message) Forward(from, to string) *Message {
+	forwarded := m.Clone()
+	forwarded.ID = generateMessageID()
+	forwarded.Timestamp = time.Now()
+	forwarded.From = from
+	forwarded.To = to
+	forwarded.ReplyTo = m.ID
+	forwarded.Hops = m.Hops + 1
+	return forwarded
+}
+
+// MetadataAwaitReply 标记转发消息需要同步等待回复的元数据键
+const MetadataAwaitReply = "await_reply"
+
+// ForwardAndWait 创建需要同步等待回复的转发消息
+// 编排器会把目标智能体的最终响应作为回复再投给发送方继续处理，
+// 回复与转发消息通过 CorrelationID 关联，未设置时使用原消息ID
+func (m *Message) ForwardAndWait(from, to string) *Message {
+	forwarded := m.Forward(from, to)
+	if forwarded.CorrelationID == "" {
+		forwarded.CorrelationID = m.ID
+	}
+	forwarded.SetMetadata(MetadataAwaitReply, true)
+	return forwarded
+}
+
+// AwaitsReply 判断消息是否需要同步等待回复
+func (m *Message) AwaitsReply() bool {
+	value, ok := m.GetMetadata(MetadataAwaitReply)
+	if !ok {
+		return false
+	}
+	await, _ := value.(bool)
+	return await
+}
+
+// ReplyFor 将目标智能体的响应包装为发给转发方的回复
+// 回复保留响应内容与数据，ReplyTo 指向转发消息，CorrelationID 与转发消息一致
+func (m *Message) ReplyFor(request *Message) *Message {
+	reply := m.Clone()
+	reply.ID = generateMessageID()
+	reply.Type = MessageTypeResponse
+	reply.Timestamp = time.Now()
+	reply.To = request.From
+	reply.ReplyTo = request.ID
+	reply.CorrelationID = request.CorrelationID
+	if reply.Metadata != nil {
+		delete(reply.Metadata, MetadataAwaitReply)
+	}
+	return reply
+}
+
+// DataKeyToolResult 工具结果消息中存放结构化结果的数据键
+const DataKeyToolResult = "result"
+
+// SetToolResult 设置工具返回的结果
+// 原始文本始终保留在 Content 中；结果为JSON对象或数组时，额外把解析后的结构放入 Data[DataKeyToolResult]
+func (m *Message) SetToolResult(raw string) {
+	m.Content = raw
+	trimmed := strings.TrimSpace(raw)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return
+	}
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err == nil {
+		m.SetData(DataKeyToolResult, parsed)
+	}
+}
+
+// ToolResult 获取解析后的结构化工具结果，工具返回的不是JSON时返回false
+func (m *Message) ToolResult() (interface{}, bool) {
+	return m.GetData(DataKeyToolResult)
+}
+
 // ToJSON 将消息转换为JSON字符串
 func (m *Message) ToJSON() (string, error) {
 	data, err := json.Marshal(m)
@@ -211,7 +299,7 @@ func CreateToolCallMessage(from, toolName string, input interface{}) *Message {
 func CreateToolResultMessage(from string, result interface{}, replyTo string) *Message {
 	msg := NewMessage(MessageTypeToolResult, from, "")
 	msg.Subject = "Tool Result"
-	msg.SetData("result", result)
+	msg.SetData(DataKeyToolResult, result)
 	msg.ReplyTo = replyTo
 	return msg
 }
@@ -4,9 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/debug"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"novelai/pkg/experimental/multilayer_agent/shared/memory"
 	"novelai/pkg/experimental/multilayer_agent/shared/model"
 
 	"github.com/cloudwego/hertz/pkg/common/hlog"
@@ -14,14 +19,49 @@ import (
 
 // OrchestratorConfig 编排器配置
 type OrchestratorConfig struct {
-	MaxConcurrentAgents int             // 最大并发智能体数
-	MessageQueueSize    int             // 消息队列大小
-	ProcessTimeout      time.Duration   // 处理超时时间
-	EnableMetrics       bool            // 是否启用指标收集
-	DefaultModelType    model.ModelType // 默认模型类型
-	DefaultModelName    string          // 默认模型名称
+	MaxConcurrentAgents int               // 最大并发智能体数
+	MessageQueueSize    int               // 消息队列大小
+	ProcessTimeout      time.Duration     // 处理超时时间
+	EnableMetrics       bool              // 是否启用指标收集
+	DefaultModelType    model.ModelType   // 默认模型类型
+	DefaultModelName    string            // 默认模型名称
+	MaxHops             int               // 智能体间最大转发跳数，小于等于0时使用默认值
+	AgentConcurrency    map[string]int    // 按智能体ID设置的并发上限，未设置或小于等于0表示不限制
+	DedupSize           int               // 去重记录的近期消息ID数量，小于等于0时使用默认值
+	MaxAgentsPerType    map[AgentType]int // 按智能体类型设置的最大实例数，未设置或小于等于0表示不限制
+	EventBufferSize     int               // 每个事件订阅者的缓冲大小，小于等于0时使用默认值
+
+	// AllowedRoutes 按智能体类型设置的通信白名单（from→可发送的 to 类型列表）
+	// 为空时不限制；发送方类型未出现在表中时该类型不受限制
+	AllowedRoutes map[AgentType][]AgentType
+
+	// ToolWhitelist 按智能体类型设置的可用工具白名单，注册时据此限制智能体的工具
+	// 为空时不限制；类型未出现在表中时该类型不受限制
+	ToolWhitelist ToolWhitelist
+
+	// ProcessLogStore 处理日志存储，每次智能体处理消息后记录原始消息与结果，供 Replay 回放
+	// 为空时不记录处理日志
+	ProcessLogStore memory.Manager
 }
 
+// DefaultMaxHops 默认最大转发跳数
+const DefaultMaxHops = 10
+
+// ErrMaxHopsExceeded 超过最大转发跳数
+var ErrMaxHopsExceeded = errors.New("超过最大转发跳数")
+
+// ErrAgentBusy 智能体并发已满且在处理超时前未能排到
+var ErrAgentBusy = errors.New("智能体繁忙")
+
+// ErrAgentTypeLimit 该类型已注册的智能体实例数达到上限
+var ErrAgentTypeLimit = errors.New("智能体类型实例数已达上限")
+
+// ErrMissingDependency 智能体缺少运行所需的依赖
+var ErrMissingDependency = errors.New("智能体依赖检查未通过")
+
+// ErrCommunicationDenied 通信白名单不允许发送方类型向接收方类型发送消息
+var ErrCommunicationDenied = errors.New("智能体间通信未被允许")
+
 // DefaultOrchestratorConfig 返回默认配置
 func DefaultOrchestratorConfig() *OrchestratorConfig {
 	return &OrchestratorConfig{
@@ -31,24 +71,34 @@ func DefaultOrchestratorConfig() *OrchestratorConfig {
 		EnableMetrics:       true,
 		DefaultModelType:    model.ModelTypeOllama,
 		DefaultModelName:    "mistral",
+		MaxHops:             DefaultMaxHops,
 	}
 }
 
 // Orchestrator 智能体编排器
 // 负责管理和协调多个智能体的工作
 type Orchestrator struct {
-	config       *OrchestratorConfig    // 配置
-	agents       map[string]Agent       // 注册的智能体
-	agentMutex   sync.RWMutex           // 智能体映射的读写锁
-	messageQueue chan *MessageEnvelope  // 消息队列
-	routingTable map[AgentType][]string // 路由表：智能体类型到ID的映射
-	routingMutex sync.RWMutex           // 路由表的读写锁
-	ctx          context.Context        // 上下文
-	cancel       context.CancelFunc     // 取消函数
-	wg           sync.WaitGroup         // 等待组
-	running      bool                   // 运行状态
-	runningMutex sync.RWMutex           // 运行状态的读写锁
-	modelFactory model.ModelFactory     // 模型工厂
+	config       *OrchestratorConfig      // 配置
+	agents       map[string]Agent         // 注册的智能体
+	agentMutex   sync.RWMutex             // 智能体映射的读写锁
+	messageQueue chan *MessageEnvelope    // 消息队列
+	replyQueue   chan *MessageEnvelope    // 优先队列：回复及高优先级消息，工作协程优先消费
+	routingTable map[AgentType][]string   // 路由表：智能体类型到ID的映射
+	routingMutex sync.RWMutex             // 路由表的读写锁
+	ctx          context.Context          // 上下文
+	cancel       context.CancelFunc       // 取消函数
+	wg           sync.WaitGroup           // 等待组
+	running      bool                     // 运行状态
+	runningMutex sync.RWMutex             // 运行状态的读写锁
+	modelFactory model.ModelFactory       // 模型工厂
+	workers      []chan struct{}          // 工作协程的退出信号通道，长度即目标工作协程数
+	workerMutex  sync.Mutex               // 工作协程列表的互斥锁
+	nextWorkerID int                      // 下一个工作协程编号
+	workerActive int32                    // 当前实际存活的工作协程数
+	agentTypes   map[AgentType]struct{}   // 允许注册的智能体类型白名单，受 routingMutex 保护
+	agentSems    map[string]chan struct{} // 按智能体ID的并发信号量，受 agentMutex 保护
+	dedup        *messageDeduper          // 近期处理过的消息ID，用于丢弃重复投递
+	events       *eventBus                // 事件总线，供外部订阅内部事件
 }
 
 // MessageEnvelope 消息信封
@@ -56,6 +106,16 @@ type Orchestrator struct {
 type MessageEnvelope struct {
 	Message    *Message                   // 消息本体
 	ResponseCh chan *MessageProcessResult // 响应通道
+	respondOne sync.Once                  // 保证响应只写入一次
+}
+
+// respond 写入处理结果并关闭响应通道
+// 无论调用多少次都只有第一次生效，避免重复写入或重复关闭
+func (e *MessageEnvelope) respond(result *MessageProcessResult) {
+	e.respondOne.Do(func() {
+		e.ResponseCh <- result
+		close(e.ResponseCh)
+	})
 }
 
 // MessageProcessResult 消息处理结果
@@ -76,11 +136,20 @@ func NewOrchestrator(config *OrchestratorConfig) *Orchestrator {
 		config:       config,
 		agents:       make(map[string]Agent),
 		messageQueue: make(chan *MessageEnvelope, config.MessageQueueSize),
+		replyQueue:   make(chan *MessageEnvelope, config.MessageQueueSize),
 		routingTable: make(map[AgentType][]string),
 		ctx:          ctx,
 		cancel:       cancel,
 		running:      false,
 		modelFactory: model.NewModelFactory(),
+		agentTypes:   make(map[AgentType]struct{}, len(builtinAgentTypes)),
+		agentSems:    make(map[string]chan struct{}),
+		dedup:        newMessageDeduper(config.DedupSize),
+		events:       newEventBus(),
+	}
+
+	for _, agentType := range builtinAgentTypes {
+		orchestrator.agentTypes[agentType] = struct{}{}
 	}
 
 	return orchestrator
@@ -95,6 +164,11 @@ func (o *Orchestrator) RegisterAgent(agent Agent) error {
 	agentID := agent.GetID()
 	agentType := agent.GetType()
 
+	// 校验智能体类型是否在白名单内，避免拼错类型导致永远路由不到
+	if !o.IsValidAgentType(agentType) {
+		return fmt.Errorf("未知的智能体类型: %s", agentType)
+	}
+
 	// 如果智能体没有设置模型，使用默认模型
 	if agent.GetModel() == nil {
 		hlog.Infof("为智能体 %s 设置默认模型 %s:%s", agentID, o.config.DefaultModelType, o.config.DefaultModelName)
@@ -116,13 +190,24 @@ func (o *Orchestrator) RegisterAgent(agent Agent) error {
 		return fmt.Errorf("已存在ID为 %s 的智能体", agentID)
 	}
 
+	o.routingMutex.Lock()
+	defer o.routingMutex.Unlock()
+
+	// 检查该类型的实例数上限，如唯一的 strategy 决策者
+	if limit := o.config.MaxAgentsPerType[agentType]; limit > 0 && len(o.routingTable[agentType]) >= limit {
+		return fmt.Errorf("%w: 类型 %s 最多 %d 个", ErrAgentTypeLimit, agentType, limit)
+	}
+
+	// 通过校验后才按类型白名单限制智能体可用的工具，注册被拒绝时不修改智能体
+	o.applyToolWhitelist(agent)
+
 	// 注册智能体
 	o.agents[agentID] = agent
+	if limit := o.config.AgentConcurrency[agentID]; limit > 0 {
+		o.agentSems[agentID] = make(chan struct{}, limit)
+	}
 
 	// 更新路由表
-	o.routingMutex.Lock()
-	defer o.routingMutex.Unlock()
-
 	if _, exists := o.routingTable[agentType]; !exists {
 		o.routingTable[agentType] = []string{}
 	}
@@ -130,10 +215,47 @@ func (o *Orchestrator) RegisterAgent(agent Agent) error {
 	o.routingTable[agentType] = append(o.routingTable[agentType], agentID)
 
 	hlog.Infof("已注册智能体: ID=%s, 类型=%s", agentID, agentType)
+	o.events.publish(Event{Type: EventAgentRegistered, AgentID: agentID})
+
+	return nil
+}
+
+// RegisterCustomType 将自定义智能体类型加入白名单
+func (o *Orchestrator) RegisterCustomType(agentType AgentType) error {
+	if agentType == "" {
+		return errors.New("智能体类型不能为空")
+	}
+
+	o.routingMutex.Lock()
+	defer o.routingMutex.Unlock()
 
+	o.agentTypes[agentType] = struct{}{}
+	hlog.Infof("已注册自定义智能体类型: %s", agentType)
 	return nil
 }
 
+// IsValidAgentType 判断智能体类型是否允许注册
+func (o *Orchestrator) IsValidAgentType(agentType AgentType) bool {
+	o.routingMutex.RLock()
+	defer o.routingMutex.RUnlock()
+
+	_, ok := o.agentTypes[agentType]
+	return ok
+}
+
+// SetAgentConcurrency 设置指定智能体的并发上限
+// limit 小于等于0表示取消限制；已在处理中的消息不受影响
+func (o *Orchestrator) SetAgentConcurrency(agentID string, limit int) {
+	o.agentMutex.Lock()
+	defer o.agentMutex.Unlock()
+
+	if limit <= 0 {
+		delete(o.agentSems, agentID)
+		return
+	}
+	o.agentSems[agentID] = make(chan struct{}, limit)
+}
+
 // UnregisterAgent 注销智能体
 func (o *Orchestrator) UnregisterAgent(agentID string) error {
 	o.agentMutex.Lock()
@@ -158,13 +280,19 @@ func (o *Orchestrator) UnregisterAgent(agentID string) error {
 
 	// 移除智能体
 	delete(o.agents, agentID)
+	delete(o.agentSems, agentID)
 
 	hlog.Infof("注销智能体成功: ID=%s", agentID)
+	o.events.publish(Event{Type: EventAgentUnregistered, AgentID: agentID})
 	return nil
 }
 
 // Start 启动编排器
 func (o *Orchestrator) Start() error {
+	if err := o.CheckDependencies(); err != nil {
+		return err
+	}
+
 	o.runningMutex.Lock()
 	if o.running {
 		o.runningMutex.Unlock()
@@ -188,15 +316,66 @@ func (o *Orchestrator) Start() error {
 	}
 
 	// 启动消息处理工作池
-	for i := 0; i < o.config.MaxConcurrentAgents; i++ {
-		o.wg.Add(1)
-		go o.messageProcessor(i)
-	}
+	o.workerMutex.Lock()
+	o.addWorkers(o.config.MaxConcurrentAgents)
+	o.workerMutex.Unlock()
 
 	hlog.Info("编排器启动成功")
+	o.events.publish(Event{Type: EventStarted})
 	return nil
 }
 
+// CheckDependencies 检查所有已注册智能体的运行依赖
+// 每个智能体必须有模型；声明需要工具或记忆的智能体必须已配置工具调用器或记忆管理器
+// 返回: 依赖齐全时返回nil，否则返回包装 ErrMissingDependency 的聚合错误，列出所有问题
+func (o *Orchestrator) CheckDependencies() error {
+	o.agentMutex.RLock()
+	agents := make([]Agent, 0, len(o.agents))
+	for _, agent := range o.agents {
+		agents = append(agents, agent)
+	}
+	o.agentMutex.RUnlock()
+
+	// 按ID排序，保证错误信息稳定
+	sort.Slice(agents, func(i, j int) bool { return agents[i].GetID() < agents[j].GetID() })
+
+	var problems []error
+	for _, agent := range agents {
+		problems = append(problems, agentDependencyProblems(agent)...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrMissingDependency, errors.Join(problems...))
+}
+
+// agentDependencyProblems 列出单个智能体缺失的依赖
+func agentDependencyProblems(agent Agent) []error {
+	var problems []error
+	id := agent.GetID()
+	if agent.GetModel() == nil {
+		problems = append(problems, fmt.Errorf("智能体 %s 缺少模型", id))
+	}
+
+	declarer, ok := agent.(DependencyAware)
+	if !ok {
+		return problems
+	}
+	if declarer.RequiresTools() {
+		holder, ok := agent.(interface{ GetToolCaller() ToolCaller })
+		if !ok || holder.GetToolCaller() == nil {
+			problems = append(problems, fmt.Errorf("智能体 %s 缺少工具调用器", id))
+		}
+	}
+	if declarer.RequiresMemory() {
+		holder, ok := agent.(interface{ GetMemoryManager() memory.Manager })
+		if !ok || holder.GetMemoryManager() == nil {
+			problems = append(problems, fmt.Errorf("智能体 %s 缺少记忆管理器", id))
+		}
+	}
+	return problems
+}
+
 // Stop 停止编排器
 func (o *Orchestrator) Stop() error {
 	o.runningMutex.Lock()
@@ -212,10 +391,15 @@ func (o *Orchestrator) Stop() error {
 
 	// 关闭消息队列
 	close(o.messageQueue)
+	close(o.replyQueue)
 
 	// 等待所有工作协程结束
 	o.wg.Wait()
 
+	o.workerMutex.Lock()
+	o.workers = nil
+	o.workerMutex.Unlock()
+
 	// 关闭所有智能体
 	o.agentMutex.RLock()
 	agents := make([]Agent, 0, len(o.agents))
@@ -233,11 +417,102 @@ func (o *Orchestrator) Stop() error {
 	}
 
 	hlog.Info("编排器停止成功")
+	o.events.publish(Event{Type: EventStopped})
 	return nil
 }
 
 // SendMessage 发送消息到指定智能体
+// 智能体的响应若是发往另一个已注册智能体的请求，编排器会继续转发，
+// 直到得到最终响应；转发跳数超过 MaxHops 时中断并返回 ErrMaxHopsExceeded。
+// 转发消息需要同步等待回复（见 Message.ForwardAndWait）时，目标智能体的最终响应
+// 会作为回复再投给转发方继续处理，调用方拿到的是转发方整合后的响应。
+// 消息未携带追踪信息而 ctx 中带有时，会先将 ctx 的追踪上下文注入消息
 func (o *Orchestrator) SendMessage(ctx context.Context, msg *Message) (*Message, error) {
+	maxHops := o.config.MaxHops
+	if maxHops <= 0 {
+		maxHops = DefaultMaxHops
+	}
+	if _, ok := msg.Trace(); !ok {
+		msg.InjectTrace(ctx)
+	}
+	if err := o.checkRoute(msg); err != nil {
+		return nil, err
+	}
+
+	current := msg
+	for {
+		response, err := o.dispatch(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		if !o.shouldForward(response) {
+			return response, nil
+		}
+
+		// 由编排器保证跳数递增，避免智能体未传递计数导致无限互发
+		if response.Hops <= current.Hops {
+			response.Hops = current.Hops + 1
+		}
+		if response.Hops > maxHops {
+			hlog.Warnf("消息转发中断: From=%s, To=%s, Hops=%d", response.From, response.To, response.Hops)
+			return nil, fmt.Errorf("%w: %d", ErrMaxHopsExceeded, maxHops)
+		}
+		if err := o.checkRoute(response); err != nil {
+			return nil, err
+		}
+		if !response.AwaitsReply() {
+			current = response
+			continue
+		}
+
+		// 同步转发：等待目标智能体的最终响应，再作为回复投给转发方
+		result, err := o.SendMessage(ctx, response)
+		if err != nil {
+			return nil, err
+		}
+		reply := result.ReplyFor(response)
+		reply.Hops = max(result.Hops, response.Hops) + 1
+		if reply.Hops > maxHops {
+			hlog.Warnf("消息回复中断: From=%s, To=%s, Hops=%d", reply.From, reply.To, reply.Hops)
+			return nil, fmt.Errorf("%w: %d", ErrMaxHopsExceeded, maxHops)
+		}
+		current = reply
+	}
+}
+
+// checkRoute 按通信白名单校验消息的发送方与接收方
+// 任一方不是已注册的智能体（如外部调用方）时不校验，回复消息沿已允许的请求原路返回，不经过此校验
+func (o *Orchestrator) checkRoute(msg *Message) error {
+	if len(o.config.AllowedRoutes) == 0 {
+		return nil
+	}
+	from, ok := o.GetAgent(msg.From)
+	if !ok {
+		return nil
+	}
+	to, ok := o.GetAgent(msg.To)
+	if !ok {
+		return nil
+	}
+	allowed, restricted := o.config.AllowedRoutes[from.GetType()]
+	if !restricted || slices.Contains(allowed, to.GetType()) {
+		return nil
+	}
+	hlog.Warnf("拒绝智能体间通信: From=%s(%s), To=%s(%s)", msg.From, from.GetType(), msg.To, to.GetType())
+	return fmt.Errorf("%w: %s -> %s", ErrCommunicationDenied, from.GetType(), to.GetType())
+}
+
+// shouldForward 判断响应是否需要继续转发给其他智能体
+func (o *Orchestrator) shouldForward(response *Message) bool {
+	if response == nil || !response.IsRequest() || response.To == "" {
+		return false
+	}
+	_, exists := o.GetAgent(response.To)
+	return exists
+}
+
+// dispatch 将单条消息投递到消息队列并等待处理结果
+func (o *Orchestrator) dispatch(ctx context.Context, msg *Message) (*Message, error) {
 	o.runningMutex.RLock()
 	if !o.running {
 		o.runningMutex.RUnlock()
@@ -251,12 +526,21 @@ func (o *Orchestrator) SendMessage(ctx context.Context, msg *Message) (*Message,
 		ResponseCh: make(chan *MessageProcessResult, 1),
 	}
 
+	// 回复及高优先级消息进入优先队列
+	queue := o.messageQueue
+	if msg.IsPriority() {
+		queue = o.replyQueue
+	}
+
 	// 发送到消息队列
 	select {
-	case o.messageQueue <- envelope:
+	case queue <- envelope:
 		// 等待响应
 		select {
-		case result := <-envelope.ResponseCh:
+		case result, ok := <-envelope.ResponseCh:
+			if !ok || result == nil {
+				return nil, errors.New("消息处理未返回结果")
+			}
 			if result.Error != nil {
 				return nil, result.Error
 			}
@@ -315,14 +599,97 @@ func (o *Orchestrator) BroadcastMessage(ctx context.Context, agentType AgentType
 	return responses, nil
 }
 
+// Scale 运行时调整消息处理工作协程数量
+// 增加时启动新的工作协程；减少时通知多余的工作协程在处理完当前消息后退出，
+// 队列中尚未处理的消息由剩余工作协程继续处理，不会丢失
+func (o *Orchestrator) Scale(n int) error {
+	if n <= 0 {
+		return errors.New("工作协程数量必须大于0")
+	}
+
+	o.runningMutex.RLock()
+	defer o.runningMutex.RUnlock()
+	if !o.running {
+		return errors.New("编排器未在运行")
+	}
+
+	o.workerMutex.Lock()
+	defer o.workerMutex.Unlock()
+
+	current := len(o.workers)
+	switch {
+	case n > current:
+		o.addWorkers(n - current)
+	case n < current:
+		for _, quit := range o.workers[n:] {
+			close(quit)
+		}
+		o.workers = o.workers[:n]
+	}
+
+	hlog.Infof("调整工作协程数量: %d -> %d", current, n)
+	return nil
+}
+
+// WorkerCount 返回当前实际存活的消息处理工作协程数量
+func (o *Orchestrator) WorkerCount() int {
+	return int(atomic.LoadInt32(&o.workerActive))
+}
+
+// addWorkers 启动指定数量的工作协程，调用方需持有 workerMutex
+func (o *Orchestrator) addWorkers(count int) {
+	for i := 0; i < count; i++ {
+		quit := make(chan struct{})
+		o.workers = append(o.workers, quit)
+		o.wg.Add(1)
+		atomic.AddInt32(&o.workerActive, 1)
+		go o.messageProcessor(o.nextWorkerID, quit)
+		o.nextWorkerID++
+	}
+}
+
 // messageProcessor 消息处理器
-func (o *Orchestrator) messageProcessor(id int) {
+// 优先消费优先队列中的消息；两个队列都关闭或收到退出信号时结束
+func (o *Orchestrator) messageProcessor(id int, quit <-chan struct{}) {
 	defer o.wg.Done()
+	defer atomic.AddInt32(&o.workerActive, -1)
 
 	hlog.Infof("消息处理器 %d 启动", id)
 
-	for envelope := range o.messageQueue {
-		o.processMessage(envelope)
+	queue, replyQueue := o.messageQueue, o.replyQueue
+	for queue != nil || replyQueue != nil {
+		// 优先队列有消息时先处理，不参与随机选择
+		select {
+		case <-quit:
+			hlog.Infof("消息处理器 %d 收到退出信号", id)
+			return
+		case envelope, ok := <-replyQueue:
+			if !ok {
+				replyQueue = nil
+				continue
+			}
+			o.processMessage(envelope)
+			continue
+		default:
+		}
+
+		select {
+		case <-quit:
+			hlog.Infof("消息处理器 %d 收到退出信号", id)
+			return
+		case envelope, ok := <-replyQueue:
+			if !ok {
+				replyQueue = nil
+				continue
+			}
+			o.processMessage(envelope)
+		case envelope, ok := <-queue:
+			if !ok {
+				queue = nil
+				continue
+			}
+			o.processMessage(envelope)
+		}
 	}
 
 	hlog.Infof("消息处理器 %d 停止", id)
@@ -332,46 +699,131 @@ func (o *Orchestrator) messageProcessor(id int) {
 func (o *Orchestrator) processMessage(envelope *MessageEnvelope) {
 	msg := envelope.Message
 
+	// 兜底：任何分支遗漏写入响应时，返回错误并关闭响应通道
+	defer envelope.respond(&MessageProcessResult{
+		Error: fmt.Errorf("消息处理异常结束: %s", msg.ID),
+	})
+
 	// 查找目标智能体
 	o.agentMutex.RLock()
 	agent, exists := o.agents[msg.To]
+	sem := o.agentSems[msg.To]
 	o.agentMutex.RUnlock()
 
 	if !exists {
-		envelope.ResponseCh <- &MessageProcessResult{
+		envelope.respond(&MessageProcessResult{
 			Error: fmt.Errorf("目标智能体不存在: %s", msg.To),
-		}
+		})
 		return
 	}
 
-	// 创建处理上下文
+	// 创建处理上下文，消息携带追踪信息时在同一链路下开启新的处理片段
 	processCtx, cancel := context.WithTimeout(o.ctx, o.config.ProcessTimeout)
 	defer cancel()
+	if tc, ok := msg.Trace(); ok {
+		processCtx = ContextWithTrace(processCtx, tc.ChildSpan())
+	}
+
+	// 同一接收方收到相同ID的消息只处理一次，重复投递直接返回首次的结果；
+	// 广播的各份副本ID相同，按接收方区分
+	key := dedupKey(msg)
+	entry, first := o.dedup.begin(key)
+	if !first {
+		o.respondDuplicate(processCtx, envelope, entry)
+		return
+	}
+	var response *Message
+	var err error
+	defer func() { o.dedup.finish(key, entry, response, err) }()
+
+	// 获取智能体的并发信号量，已满时排队等待直到超时
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-processCtx.Done():
+			err = fmt.Errorf("%w: %s", ErrAgentBusy, msg.To)
+			o.events.publish(Event{Type: EventMessageFailed, AgentID: msg.To, MessageID: msg.ID, Error: err})
+			envelope.respond(&MessageProcessResult{
+				Error: err,
+			})
+			return
+		}
+	}
 
 	// 记录处理开始
 	startTime := time.Now()
-	hlog.Infof("开始处理消息: ID=%s, From=%s, To=%s, Type=%s",
-		msg.ID, msg.From, msg.To, msg.Type)
-
-	// 调用智能体处理消息
-	response, err := agent.Process(processCtx, msg)
+	hlog.Infof("开始处理消息: ID=%s, From=%s, To=%s, Type=%s, TraceID=%s",
+		msg.ID, msg.From, msg.To, msg.Type, msg.TraceID())
+
+	// 调用智能体处理消息（panic 会被转换为错误，保证工作协程存活）
+	response, err = o.safeProcess(processCtx, agent, msg)
+	if response != nil {
+		// 智能体新建的响应不会自带追踪信息，由编排器延续到响应上，保证转发后链路不断
+		if _, ok := response.Trace(); !ok {
+			response.InjectTrace(processCtx)
+		}
+	}
 
 	// 记录处理结果
 	duration := time.Since(startTime)
+	logID := o.recordProcessLog(processCtx, msg, response, err, startTime)
 	if err != nil {
 		hlog.Errorf("处理消息失败: ID=%s, Error=%v, Duration=%v",
 			msg.ID, err, duration)
-		envelope.ResponseCh <- &MessageProcessResult{
+		o.events.publish(Event{Type: EventMessageFailed, AgentID: msg.To, MessageID: msg.ID, LogID: logID, Duration: duration, Error: err})
+		envelope.respond(&MessageProcessResult{
 			Error: err,
-		}
+		})
 	} else {
 		hlog.Infof("处理消息成功: ID=%s, Duration=%v", msg.ID, duration)
-		envelope.ResponseCh <- &MessageProcessResult{
+		o.events.publish(Event{Type: EventMessageProcessed, AgentID: msg.To, MessageID: msg.ID, LogID: logID, Duration: duration})
+		envelope.respond(&MessageProcessResult{
 			Message: response,
-		}
+		})
 	}
 }
 
+// respondDuplicate 响应重复投递的消息
+// 首次处理仍在进行时等待其完成；返回首次响应的副本，并在元数据中标记为重复
+func (o *Orchestrator) respondDuplicate(ctx context.Context, envelope *MessageEnvelope, entry *dedupEntry) {
+	msg := envelope.Message
+	hlog.Warnf("丢弃重复消息: ID=%s, From=%s, To=%s", msg.ID, msg.From, msg.To)
+
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		envelope.respond(&MessageProcessResult{Error: ctx.Err()})
+		return
+	}
+
+	if entry.response == nil {
+		// 首次处理失败或无响应，本次投递不再重复调用智能体
+		envelope.respond(&MessageProcessResult{
+			Error: fmt.Errorf("重复消息且首次处理未得到响应: %s", msg.ID),
+		})
+		return
+	}
+	response := entry.response.Clone()
+	response.SetMetadata(MetadataDuplicate, true)
+	envelope.respond(&MessageProcessResult{Message: response})
+}
+
+// safeProcess 调用智能体处理消息并捕获 panic
+// agent.Process 内部 panic 时记录堆栈，并将其转换为普通错误返回
+func (o *Orchestrator) safeProcess(ctx context.Context, agent Agent, msg *Message) (response *Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			hlog.Errorf("智能体处理消息发生panic: AgentID=%s, MessageID=%s, Panic=%v\n%s",
+				agent.GetID(), msg.ID, r, debug.Stack())
+			response = nil
+			err = fmt.Errorf("智能体 %s 处理消息时发生panic: %v", agent.GetID(), r)
+		}
+	}()
+
+	return agent.Process(ctx, msg)
+}
+
 // GetAgent 获取指定ID的智能体
 func (o *Orchestrator) GetAgent(agentID string) (Agent, bool) {
 	o.agentMutex.RLock()
@@ -414,7 +866,10 @@ func (o *Orchestrator) GetStatus() map[string]interface{} {
 		"running":        running,
 		"agent_count":    agentCount,
 		"queue_size":     len(o.messageQueue),
+		"reply_queue":    len(o.replyQueue),
 		"queue_capacity": o.config.MessageQueueSize,
+		"worker_count":   o.WorkerCount(),
+		"events_dropped": o.events.droppedCount(),
 	}
 
 	// 统计各类型智能体数量
@@ -0,0 +1,655 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"novelai/pkg/experimental/multilayer_agent/shared/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcAgent 测试用智能体，处理逻辑由 processFunc 决定
+type funcAgent struct {
+	*BaseAgent
+	processFunc func(ctx context.Context, msg *Message) (*Message, error)
+}
+
+// newFuncAgent 创建测试用智能体，并预置一个无需联网的模型
+func newFuncAgent(id string, agentType AgentType, fn func(ctx context.Context, msg *Message) (*Message, error)) *funcAgent {
+	agent := &funcAgent{
+		BaseAgent:   NewBaseAgent(id, agentType),
+		processFunc: fn,
+	}
+	agent.SetModel(&model.ModelWrapper{Type: model.ModelTypeOllama, Name: "mock"})
+	return agent
+}
+
+// Process 实现Agent接口
+func (a *funcAgent) Process(ctx context.Context, msg *Message) (*Message, error) {
+	return a.processFunc(ctx, msg)
+}
+
+// echoProcess 原样回复消息内容
+func echoProcess(agentID string) func(ctx context.Context, msg *Message) (*Message, error) {
+	return func(ctx context.Context, msg *Message) (*Message, error) {
+		response := NewMessage(MessageTypeResponse, agentID, msg.From)
+		response.Content = msg.Content
+		response.ReplyTo = msg.ID
+		return response, nil
+	}
+}
+
+// newTestOrchestrator 创建测试用编排器
+func newTestOrchestrator(t *testing.T, workers int) *Orchestrator {
+	config := DefaultOrchestratorConfig()
+	config.MaxConcurrentAgents = workers
+	config.MessageQueueSize = 100
+	config.ProcessTimeout = 5 * time.Second
+	return NewOrchestrator(config)
+}
+
+// sendTestMessage 向指定智能体发送一条请求消息
+func sendTestMessage(t *testing.T, o *Orchestrator, to, content string) (*Message, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msg := NewMessage(MessageTypeRequest, "tester", to)
+	msg.Content = content
+	return o.SendMessage(ctx, msg)
+}
+
+// TestProcessMessagePanicRecovery 测试智能体panic时工作协程存活
+func TestProcessMessagePanicRecovery(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+
+	panicAgent := newFuncAgent("panic-agent", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		panic("boom")
+	})
+	require.NoError(t, o.RegisterAgent(panicAgent))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("echo-agent", AgentTypeDialogue, echoProcess("echo-agent"))))
+
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	// panic 被转换为错误响应
+	resp, err := sendTestMessage(t, o, "panic-agent", "hello")
+	assert.Nil(t, resp)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "panic")
+	assert.Contains(t, err.Error(), "boom")
+
+	// 唯一的工作协程仍能处理后续消息
+	resp, err = sendTestMessage(t, o, "echo-agent", "still alive")
+	require.NoError(t, err)
+	assert.Equal(t, "still alive", resp.Content)
+
+	resp, err = sendTestMessage(t, o, "panic-agent", "again")
+	assert.Nil(t, resp)
+	assert.Error(t, err)
+}
+
+// TestOrchestratorScale 测试运行时调整工作协程数量
+func TestOrchestratorScale(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+
+	var inFlight int32
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	blockingAgent := newFuncAgent("blocking-agent", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		atomic.AddInt32(&inFlight, 1)
+		started <- struct{}{}
+		<-release
+		return echoProcess("blocking-agent")(ctx, msg)
+	})
+	require.NoError(t, o.RegisterAgent(blockingAgent))
+
+	assert.Error(t, o.Scale(2), "未启动时不允许调整")
+	require.NoError(t, o.Start())
+	defer o.Stop()
+	assert.Equal(t, 1, o.WorkerCount())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sendTestMessage(t, o, "blocking-agent", "work")
+			assert.NoError(t, err)
+		}()
+	}
+
+	// 单个工作协程时只有一条消息在处理
+	<-started
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inFlight))
+
+	// 扩容后剩余消息被并发处理
+	require.NoError(t, o.Scale(3))
+	<-started
+	<-started
+	assert.Equal(t, int32(3), atomic.LoadInt32(&inFlight))
+	assert.Equal(t, 3, o.WorkerCount())
+
+	close(release)
+	wg.Wait()
+
+	// 缩容后多余工作协程退出，剩余工作协程继续处理消息
+	require.NoError(t, o.Scale(1))
+	assert.Eventually(t, func() bool { return o.WorkerCount() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Error(t, o.Scale(0))
+
+	resp, err := sendTestMessage(t, o, "blocking-agent", "after scale down")
+	require.NoError(t, err)
+	assert.Equal(t, "after scale down", resp.Content)
+}
+
+// TestRegisterAgentTypeValidation 测试注册智能体时校验类型合法性
+func TestRegisterAgentTypeValidation(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+
+	// 内置类型注册成功
+	assert.NoError(t, o.RegisterAgent(newFuncAgent("character-agent", AgentTypeCharacter, echoProcess("character-agent"))))
+
+	// 拼错或未注册的自定义类型被拒
+	err := o.RegisterAgent(newFuncAgent("typo-agent", AgentType("charactr"), echoProcess("typo-agent")))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "charactr")
+	_, exists := o.GetAgent("typo-agent")
+	assert.False(t, exists)
+
+	// 显式扩展白名单后可以注册
+	require.NoError(t, o.RegisterCustomType("translator"))
+	assert.True(t, o.IsValidAgentType("translator"))
+	assert.NoError(t, o.RegisterAgent(newFuncAgent("translator-agent", AgentType("translator"), echoProcess("translator-agent"))))
+	assert.Error(t, o.RegisterCustomType(""))
+}
+
+// TestMessageEnvelopeRespondOnce 测试响应只写入一次且通道被关闭
+func TestMessageEnvelopeRespondOnce(t *testing.T) {
+	envelope := &MessageEnvelope{ResponseCh: make(chan *MessageProcessResult, 1)}
+
+	envelope.respond(&MessageProcessResult{Message: NewMessage(MessageTypeResponse, "a", "b")})
+	assert.NotPanics(t, func() {
+		envelope.respond(&MessageProcessResult{Error: assert.AnError})
+	})
+
+	result, ok := <-envelope.ResponseCh
+	require.True(t, ok)
+	assert.NoError(t, result.Error)
+	_, ok = <-envelope.ResponseCh
+	assert.False(t, ok, "响应通道应已关闭")
+}
+
+// TestSendMessageNoLeak 测试大量并发发送且部分目标不存在时无协程泄露
+func TestSendMessageNoLeak(t *testing.T) {
+	o := newTestOrchestrator(t, 4)
+	require.NoError(t, o.RegisterAgent(newFuncAgent("echo-agent", AgentTypeDialogue, echoProcess("echo-agent"))))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	baseline := runtime.NumGoroutine()
+
+	var wg sync.WaitGroup
+	var failed int32
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			target := "echo-agent"
+			if i%2 == 0 {
+				target = "missing-agent"
+			}
+			_, err := sendTestMessage(t, o, target, "ping")
+			if err != nil {
+				atomic.AddInt32(&failed, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(100), atomic.LoadInt32(&failed), "目标不存在的消息应全部返回错误")
+	// 轮询等待协程退出（不使用 Eventually，其自身会额外启动协程）
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline, "并发发送结束后不应残留协程")
+}
+
+// forwardProcess 将收到的消息转发给指定智能体
+func forwardProcess(agentID, to string, calls *int32) func(ctx context.Context, msg *Message) (*Message, error) {
+	return func(ctx context.Context, msg *Message) (*Message, error) {
+		atomic.AddInt32(calls, 1)
+		return msg.Forward(agentID, to), nil
+	}
+}
+
+// TestSendMessageMaxHops 测试智能体互发时在 MaxHops 处被切断
+func TestSendMessageMaxHops(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	o.config.MaxHops = 5
+
+	var calls int32
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-a", AgentTypePlot, forwardProcess("agent-a", "agent-b", &calls))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-b", AgentTypeDialogue, forwardProcess("agent-b", "agent-a", &calls))))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	resp, err := sendTestMessage(t, o, "agent-a", "你先说")
+	assert.Nil(t, resp)
+	require.ErrorIs(t, err, ErrMaxHopsExceeded)
+	// 跳数 0~5 的消息各处理一次，第6跳被切断
+	assert.Equal(t, int32(6), atomic.LoadInt32(&calls))
+}
+
+// TestSendMessageForward 测试转发链得到最终响应并传递跳数
+func TestSendMessageForward(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+
+	var calls int32
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-a", AgentTypePlot, forwardProcess("agent-a", "agent-c", &calls))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-c", AgentTypeDialogue, func(ctx context.Context, msg *Message) (*Message, error) {
+		response, err := echoProcess("agent-c")(ctx, msg)
+		response.Hops = msg.Hops
+		return response, err
+	})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	resp, err := sendTestMessage(t, o, "agent-a", "转给C")
+	require.NoError(t, err)
+	assert.Equal(t, "agent-c", resp.From)
+	assert.Equal(t, "转给C", resp.Content)
+	assert.Equal(t, 1, resp.Hops)
+	assert.Equal(t, 1, resp.Clone().Hops, "Clone 应传递跳数")
+}
+
+// TestAgentConcurrencyLimit 测试按智能体的并发上限使消息串行处理
+func TestAgentConcurrencyLimit(t *testing.T) {
+	config := DefaultOrchestratorConfig()
+	config.MaxConcurrentAgents = 4
+	config.MessageQueueSize = 100
+	config.ProcessTimeout = 5 * time.Second
+	config.AgentConcurrency = map[string]int{"plot-agent": 1}
+	o := NewOrchestrator(config)
+
+	var inFlight, maxInFlight int32
+	slowAgent := func(agentID string) func(ctx context.Context, msg *Message) (*Message, error) {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			current := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+			for {
+				peak := atomic.LoadInt32(&maxInFlight)
+				if current <= peak || atomic.CompareAndSwapInt32(&maxInFlight, peak, current) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			return echoProcess(agentID)(ctx, msg)
+		}
+	}
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-agent", AgentTypePlot, slowAgent("plot-agent"))))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	// 两条同时到达的消息被串行处理
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sendTestMessage(t, o, "plot-agent", "heavy")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxInFlight))
+
+	// 取消限制后可以并发处理
+	o.SetAgentConcurrency("plot-agent", 0)
+	atomic.StoreInt32(&maxInFlight, 0)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sendTestMessage(t, o, "plot-agent", "heavy")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInFlight))
+}
+
+// TestAgentConcurrencyBusy 测试并发已满且等待超时时返回繁忙错误
+func TestAgentConcurrencyBusy(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	o.config.ProcessTimeout = 100 * time.Millisecond
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-agent", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		started <- struct{}{}
+		<-release
+		return echoProcess("plot-agent")(ctx, msg)
+	})))
+	o.SetAgentConcurrency("plot-agent", 1)
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = sendTestMessage(t, o, "plot-agent", "first")
+	}()
+	<-started
+
+	_, err := sendTestMessage(t, o, "plot-agent", "second")
+	assert.ErrorIs(t, err, ErrAgentBusy)
+
+	close(release)
+	<-done
+}
+
+// TestReplyMessagePriority 测试同时有新请求和回复消息时回复被优先处理
+func TestReplyMessagePriority(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+
+	var mu sync.Mutex
+	var order []string
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-agent", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		if msg.Content == "block" {
+			started <- struct{}{}
+			<-release
+		}
+		mu.Lock()
+		order = append(order, msg.Content)
+		mu.Unlock()
+		return echoProcess("plot-agent")(ctx, msg)
+	})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	// 占住唯一的工作协程
+	var wg sync.WaitGroup
+	send := func(msg *Message) {
+		defer wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err := o.SendMessage(ctx, msg)
+		assert.NoError(t, err)
+	}
+	blockMsg := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	blockMsg.Content = "block"
+	wg.Add(1)
+	go send(blockMsg)
+	<-started
+
+	// 先到达新请求，后到达回复消息
+	request := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	request.Content = "request"
+	wg.Add(1)
+	go send(request)
+	require.Eventually(t, func() bool { return len(o.messageQueue) == 1 }, time.Second, 5*time.Millisecond)
+
+	reply := NewMessage(MessageTypeResponse, "tester", "plot-agent")
+	reply.Content = "reply"
+	reply.ReplyTo = "tool-call-1"
+	wg.Add(1)
+	go send(reply)
+	require.Eventually(t, func() bool { return len(o.replyQueue) == 1 }, time.Second, 5*time.Millisecond)
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"block", "reply", "request"}, order)
+}
+
+// TestForwardedRequestNotPriority 测试转发的请求虽带 ReplyTo 也不进入优先队列，回复消息才优先
+func TestForwardedRequestNotPriority(t *testing.T) {
+	request := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	forwarded := request.Forward("plot-agent", "character-agent")
+	assert.NotEmpty(t, forwarded.ReplyTo)
+	assert.False(t, forwarded.IsPriority(), "转发的请求不应插队")
+	assert.False(t, request.ForwardAndWait("plot-agent", "character-agent").IsPriority())
+
+	response := NewMessage(MessageTypeResponse, "character-agent", "plot-agent")
+	assert.True(t, response.ReplyFor(forwarded).IsPriority(), "回复消息应被优先调度")
+
+	urgent := request.Forward("plot-agent", "character-agent")
+	urgent.Priority = MessagePriorityUrgent
+	assert.True(t, urgent.IsPriority(), "紧急消息仍然优先")
+}
+
+// TestSendMessageDedup 测试相同ID的消息重复投递时不再调用智能体
+func TestSendMessageDedup(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+
+	var calls int32
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-agent", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		atomic.AddInt32(&calls, 1)
+		return echoProcess("plot-agent")(ctx, msg)
+	})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msg := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	msg.Content = "只处理一次"
+	first, err := o.SendMessage(ctx, msg)
+	require.NoError(t, err)
+	_, duplicated := first.GetMetadata(MetadataDuplicate)
+	assert.False(t, duplicated)
+
+	// 相同ID再次投递，返回首次结果且不触发 Process
+	second, err := o.SendMessage(ctx, msg.Clone())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, "只处理一次", second.Content)
+	duplicate, _ := second.GetMetadata(MetadataDuplicate)
+	assert.Equal(t, true, duplicate)
+
+	// 不同ID正常处理
+	_, err = sendTestMessage(t, o, "plot-agent", "新消息")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// TestSendMessageDedupRetryAfterError 测试处理失败的消息重试时重新处理
+func TestSendMessageDedupRetryAfterError(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+
+	var calls int32
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-agent", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, assert.AnError
+		}
+		return echoProcess("plot-agent")(ctx, msg)
+	})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	msg := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	_, err := o.SendMessage(ctx, msg)
+	require.Error(t, err)
+	_, err = o.SendMessage(ctx, msg)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// TestMessageDeduperEviction 测试去重集合有界，超出容量淘汰最早的记录
+func TestMessageDeduperEviction(t *testing.T) {
+	d := newMessageDeduper(2)
+	for _, id := range []string{"a", "b", "c"} {
+		entry, first := d.begin(id)
+		require.True(t, first)
+		d.finish(id, entry, NewMessage(MessageTypeResponse, "x", "y"), nil)
+	}
+	_, first := d.begin("b")
+	assert.False(t, first, "b 仍在集合中")
+	_, first = d.begin("a")
+	assert.True(t, first, "a 已被淘汰")
+}
+
+// TestMaxAgentsPerType 测试按类型限制实例数
+func TestMaxAgentsPerType(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+	o.config.MaxAgentsPerType = map[AgentType]int{AgentTypeStrategy: 1}
+
+	require.NoError(t, o.RegisterAgent(newFuncAgent("strategy-1", AgentTypeStrategy, echoProcess("strategy-1"))))
+	err := o.RegisterAgent(newFuncAgent("strategy-2", AgentTypeStrategy, echoProcess("strategy-2")))
+	assert.ErrorIs(t, err, ErrAgentTypeLimit)
+	_, exists := o.GetAgent("strategy-2")
+	assert.False(t, exists, "超限的智能体不应被注册")
+
+	// 未配置上限的类型可注册多个
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("worldview-%d", i)
+		require.NoError(t, o.RegisterAgent(newFuncAgent(id, AgentTypeWorldview, echoProcess(id))))
+	}
+
+	// 注销后腾出名额
+	require.NoError(t, o.UnregisterAgent("strategy-1"))
+	assert.NoError(t, o.RegisterAgent(newFuncAgent("strategy-2", AgentTypeStrategy, echoProcess("strategy-2"))))
+}
+
+// TestStartDependencyCheck 测试缺少依赖的智能体导致启动失败并列出其ID
+func TestStartDependencyCheck(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+
+	ok := newFuncAgent("ok-agent", AgentTypePlot, echoProcess("ok-agent"))
+	require.NoError(t, o.RegisterAgent(ok))
+
+	needTools := newFuncAgent("tool-agent", AgentTypeCharacter, echoProcess("tool-agent"))
+	needTools.SetRequirements(true, false)
+	require.NoError(t, o.RegisterAgent(needTools))
+
+	needMemory := newFuncAgent("memory-agent", AgentTypeDialogue, echoProcess("memory-agent"))
+	needMemory.SetRequirements(false, true)
+	require.NoError(t, o.RegisterAgent(needMemory))
+
+	err := o.Start()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrMissingDependency)
+	assert.Contains(t, err.Error(), "tool-agent")
+	assert.Contains(t, err.Error(), "memory-agent")
+	assert.NotContains(t, err.Error(), "ok-agent")
+	assert.Equal(t, false, o.GetStatus()["running"], "依赖检查失败时不应进入运行状态")
+	assert.Error(t, o.Stop())
+
+	// 补齐依赖后可正常启动
+	needTools.SetRequirements(false, false)
+	needMemory.SetRequirements(false, false)
+	require.NoError(t, o.Start())
+	require.NoError(t, o.Stop())
+}
+
+// TestSendMessageRequestReply 测试同步转发：A 转发给 B，B 的结果回给 A 整合后再返回给调用方
+func TestSendMessageRequestReply(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+
+	var replyToA *Message
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-a", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		if msg.Type == MessageTypeResponse {
+			replyToA = msg
+			response := NewMessage(MessageTypeResponse, "agent-a", "tester")
+			response.Content = "A整合: " + msg.Content
+			response.CorrelationID = msg.CorrelationID
+			return response, nil
+		}
+		return msg.ForwardAndWait("agent-a", "agent-b"), nil
+	})))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-b", AgentTypeDialogue, func(ctx context.Context, msg *Message) (*Message, error) {
+		response := NewMessage(MessageTypeResponse, "agent-b", msg.From)
+		response.Content = "B的结果(" + msg.Content + ")"
+		return response, nil
+	})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msg := NewMessage(MessageTypeRequest, "tester", "agent-a")
+	msg.Content = "查询"
+	resp, err := o.SendMessage(ctx, msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "agent-a", resp.From, "最终响应应来自转发方")
+	assert.Equal(t, "A整合: B的结果(查询)", resp.Content)
+	assert.Equal(t, msg.ID, resp.CorrelationID, "回复链路通过 CorrelationID 关联到原始请求")
+
+	require.NotNil(t, replyToA)
+	assert.Equal(t, "agent-b", replyToA.From)
+	assert.Equal(t, "agent-a", replyToA.To)
+	assert.False(t, replyToA.AwaitsReply())
+	assert.Equal(t, 2, replyToA.Hops)
+}
+
+// TestSendMessageRequestReplyMaxHops 测试互相同步转发时受最大跳数限制
+func TestSendMessageRequestReplyMaxHops(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	o.config.MaxHops = 3
+
+	loop := func(id, to string) func(ctx context.Context, msg *Message) (*Message, error) {
+		return func(ctx context.Context, msg *Message) (*Message, error) {
+			return msg.ForwardAndWait(id, to), nil
+		}
+	}
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-a", AgentTypePlot, loop("agent-a", "agent-b"))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-b", AgentTypeDialogue, loop("agent-b", "agent-a"))))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	_, err := sendTestMessage(t, o, "agent-a", "循环")
+	assert.ErrorIs(t, err, ErrMaxHopsExceeded)
+}
+
+// TestSendMessageAllowedRoutes 测试通信白名单拒绝未允许方向的转发，其他方向正常
+func TestSendMessageAllowedRoutes(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	o.config.AllowedRoutes = map[AgentType][]AgentType{
+		AgentTypeWorldview: {AgentTypePlot},
+	}
+
+	var calls int32
+	require.NoError(t, o.RegisterAgent(newFuncAgent("world-to-strategy", AgentTypeWorldview, forwardProcess("world-to-strategy", "strategy-1", &calls))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("world-to-plot", AgentTypeWorldview, forwardProcess("world-to-plot", "plot-1", &calls))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("strategy-to-world", AgentTypeStrategy, forwardProcess("strategy-to-world", "world-to-plot", &calls))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("strategy-1", AgentTypeStrategy, echoProcess("strategy-1"))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-1", AgentTypePlot, echoProcess("plot-1"))))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	_, err := sendTestMessage(t, o, "world-to-strategy", "请求决策")
+	assert.ErrorIs(t, err, ErrCommunicationDenied, "worldview 不允许发给 strategy")
+
+	resp, err := sendTestMessage(t, o, "world-to-plot", "交给情节")
+	require.NoError(t, err)
+	assert.Equal(t, "plot-1", resp.From)
+
+	// 未出现在白名单中的类型不受限制
+	resp, err = sendTestMessage(t, o, "strategy-to-world", "下发设定")
+	require.NoError(t, err)
+	assert.Equal(t, "plot-1", resp.From)
+	assert.Equal(t, "下发设定", resp.Content)
+
+	// 智能体直接发送的消息同样校验
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = o.SendMessage(ctx, NewMessage(MessageTypeRequest, "world-to-plot", "strategy-1"))
+	assert.ErrorIs(t, err, ErrCommunicationDenied)
+}
@@ -0,0 +1,42 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// OutputProcessor 模型输出后处理器
+// 接收模型的原始输出，返回处理后的内容；多个处理器按注册顺序依次应用
+type OutputProcessor func(string) string
+
+// preamblePattern 匹配模型开头的客套话，如“好的，以下是……：”“当然！下面为您……：”
+var preamblePattern = regexp.MustCompile(`^\s*(好的|当然|没问题|以下是|下面是)[^\n：:]{0,40}[：:]\s*`)
+
+// StripPreamble 去掉模型输出开头的客套话
+func StripPreamble(s string) string {
+	return preamblePattern.ReplaceAllString(s, "")
+}
+
+// TrimOutput 去掉输出首尾的空白
+func TrimOutput(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// TruncateOutput 返回把输出截断到 maxRunes 个字符的处理器，maxRunes 小于等于0时不截断
+func TruncateOutput(maxRunes int) OutputProcessor {
+	return func(s string) string {
+		if maxRunes <= 0 || utf8.RuneCountInString(s) <= maxRunes {
+			return s
+		}
+		return string([]rune(s)[:maxRunes])
+	}
+}
+
+// applyOutputProcessors 依次应用处理器链
+func applyOutputProcessors(s string, processors []OutputProcessor) string {
+	for _, process := range processors {
+		s = process(s)
+	}
+	return s
+}
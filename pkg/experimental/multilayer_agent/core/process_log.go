@@ -0,0 +1,143 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"novelai/pkg/experimental/multilayer_agent/shared/memory"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// processLogKeyPrefix 处理日志在存储中的键前缀
+const processLogKeyPrefix = "process_log|"
+
+// MetadataReplayOf 回放消息携带的元数据键，值为被回放的处理日志ID
+const MetadataReplayOf = "replay_of"
+
+// ErrProcessLogDisabled 未配置处理日志存储
+var ErrProcessLogDisabled = errors.New("未启用处理日志")
+
+// ErrProcessLogNotFound 处理日志不存在
+var ErrProcessLogNotFound = errors.New("处理日志不存在")
+
+// ProcessLog 一次消息处理的记录
+// ID 由接收方与消息ID组成，同一接收方对同一消息只处理一次，因此唯一
+type ProcessLog struct {
+	ID        string        `json:"id"`                 // 日志ID
+	AgentID   string        `json:"agent_id"`           // 处理消息的智能体ID
+	Message   *Message      `json:"message"`            // 智能体收到的原始消息
+	Response  *Message      `json:"response,omitempty"` // 智能体的响应，处理失败时为空
+	Error     string        `json:"error,omitempty"`    // 处理错误
+	Duration  time.Duration `json:"duration"`           // 处理耗时
+	Timestamp time.Time     `json:"timestamp"`          // 处理开始时间
+}
+
+// processLogID 返回消息对应的处理日志ID
+func processLogID(msg *Message) string {
+	return dedupKey(msg)
+}
+
+// recordProcessLog 持久化一次处理记录，未配置存储时不做任何事
+// 存储失败只记录告警，不影响消息处理结果
+func (o *Orchestrator) recordProcessLog(ctx context.Context, msg, response *Message, processErr error, start time.Time) string {
+	store := o.config.ProcessLogStore
+	if store == nil {
+		return ""
+	}
+
+	entry := ProcessLog{
+		ID:        processLogID(msg),
+		AgentID:   msg.To,
+		Message:   msg.Clone(),
+		Duration:  time.Since(start),
+		Timestamp: start,
+	}
+	if response != nil {
+		entry.Response = response.Clone()
+	}
+	if processErr != nil {
+		entry.Error = processErr.Error()
+	}
+
+	// 以JSON保存，便于换成持久化的存储实现
+	data, err := json.Marshal(entry)
+	if err != nil {
+		hlog.Warnf("序列化处理日志失败: ID=%s, Error=%v", entry.ID, err)
+		return ""
+	}
+	if err := store.Save(context.WithoutCancel(ctx), processLogKeyPrefix+entry.ID, string(data)); err != nil {
+		hlog.Warnf("保存处理日志失败: ID=%s, Error=%v", entry.ID, err)
+		return ""
+	}
+	return entry.ID
+}
+
+// GetProcessLog 读取处理日志
+func (o *Orchestrator) GetProcessLog(ctx context.Context, logID string) (*ProcessLog, error) {
+	store := o.config.ProcessLogStore
+	if store == nil {
+		return nil, ErrProcessLogDisabled
+	}
+
+	value, err := store.Load(ctx, processLogKeyPrefix+logID)
+	if err != nil {
+		if errors.Is(err, memory.ErrKeyNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrProcessLogNotFound, logID)
+		}
+		return nil, err
+	}
+	data, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("处理日志格式错误: %s", logID)
+	}
+
+	var entry ProcessLog
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		return nil, fmt.Errorf("解析处理日志失败: %w", err)
+	}
+	if entry.Message == nil {
+		return nil, fmt.Errorf("处理日志缺少原始消息: %s", logID)
+	}
+	return &entry, nil
+}
+
+// ListProcessLogs 列出全部处理日志ID
+func (o *Orchestrator) ListProcessLogs(ctx context.Context) ([]string, error) {
+	store := o.config.ProcessLogStore
+	if store == nil {
+		return nil, ErrProcessLogDisabled
+	}
+
+	keys, err := store.List(ctx, processLogKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(keys))
+	for _, key := range keys {
+		ids = append(ids, strings.TrimPrefix(key, processLogKeyPrefix))
+	}
+	return ids, nil
+}
+
+// Replay 用处理日志记录的原始消息重新投递给当前的同一智能体，返回新的处理结果
+// 回放消息使用新的消息ID以绕过去重，并在元数据 MetadataReplayOf 中记录来源日志；
+// 回放只投递给原接收方，不继续转发，便于与日志中的原响应对比
+func (o *Orchestrator) Replay(ctx context.Context, logID string) (*Message, error) {
+	entry, err := o.GetProcessLog(ctx, logID)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := entry.Message.Clone()
+	msg.ID = generateMessageID()
+	msg.Timestamp = time.Now()
+	msg.SetMetadata(MetadataReplayOf, logID)
+
+	hlog.Infof("回放消息: LogID=%s, To=%s, NewID=%s", logID, msg.To, msg.ID)
+	return o.dispatch(ctx, msg)
+}
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"novelai/pkg/experimental/multilayer_agent/shared/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplayProcessLog 测试记录处理日志后 Replay 用原输入再次触发对应智能体的 Process
+func TestReplayProcessLog(t *testing.T) {
+	config := DefaultOrchestratorConfig()
+	config.MaxConcurrentAgents = 2
+	config.ProcessTimeout = 5 * time.Second
+	config.ProcessLogStore = memory.NewSimpleMemoryStore()
+	o := NewOrchestrator(config)
+
+	var mu sync.Mutex
+	var received []*Message
+	version := "v1"
+	agent := newFuncAgent("plot-agent", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, msg.Clone())
+		response := NewMessage(MessageTypeResponse, "plot-agent", msg.From)
+		response.Content = version + ":" + msg.Content
+		response.ReplyTo = msg.ID
+		return response, nil
+	})
+	require.NoError(t, o.RegisterAgent(agent))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	events := o.Subscribe()
+	defer o.Unsubscribe(events)
+
+	msg := NewMessage(MessageTypeRequest, "tester", "plot-agent")
+	msg.Content = "主角离开北境"
+	msg.SetData("chapter", 3)
+	first, err := o.SendMessage(context.Background(), msg)
+	require.NoError(t, err)
+	assert.Equal(t, "v1:主角离开北境", first.Content)
+
+	// 处理事件携带日志ID
+	var logID string
+	select {
+	case event := <-events:
+		require.Equal(t, EventMessageProcessed, event.Type)
+		logID = event.LogID
+	case <-time.After(time.Second):
+		t.Fatal("未收到处理事件")
+	}
+	require.NotEmpty(t, logID)
+	ids, err := o.ListProcessLogs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{logID}, ids)
+
+	// 智能体行为变化后回放，拿到新的结果
+	mu.Lock()
+	version = "v2"
+	mu.Unlock()
+	replayed, err := o.Replay(context.Background(), logID)
+	require.NoError(t, err)
+	assert.Equal(t, "v2:主角离开北境", replayed.Content)
+
+	mu.Lock()
+	require.Len(t, received, 2)
+	original, replay := received[0], received[1]
+	mu.Unlock()
+	assert.NotEqual(t, original.ID, replay.ID, "回放应使用新的消息ID以绕过去重")
+	assert.Equal(t, original.Content, replay.Content)
+	assert.Equal(t, original.From, replay.From)
+	chapter, _ := replay.GetData("chapter")
+	assert.EqualValues(t, 3, chapter)
+	replayOf, _ := replay.GetMetadata(MetadataReplayOf)
+	assert.Equal(t, logID, replayOf)
+
+	// 原日志保留首次结果，便于与回放结果对比
+	entry, err := o.GetProcessLog(context.Background(), logID)
+	require.NoError(t, err)
+	assert.Equal(t, "plot-agent", entry.AgentID)
+	assert.Equal(t, msg.ID, entry.Message.ID)
+	require.NotNil(t, entry.Response)
+	assert.Equal(t, "v1:主角离开北境", entry.Response.Content)
+
+	ids, err = o.ListProcessLogs(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, ids, 2, "回放本身也应记录处理日志")
+}
+
+// TestReplayErrors 测试未启用处理日志或日志不存在时返回错误
+func TestReplayErrors(t *testing.T) {
+	o := newTestOrchestrator(t, 1)
+	_, err := o.Replay(context.Background(), "plot-agent/msg")
+	assert.ErrorIs(t, err, ErrProcessLogDisabled)
+
+	config := DefaultOrchestratorConfig()
+	config.ProcessLogStore = memory.NewSimpleMemoryStore()
+	o = NewOrchestrator(config)
+	_, err = o.Replay(context.Background(), "plot-agent/missing")
+	assert.ErrorIs(t, err, ErrProcessLogNotFound)
+}
@@ -0,0 +1,50 @@
+package core
+
+// promptHeader 默认提示模板的开头，占位符为智能体类型
+const promptHeader = "你是一个智能体，类型为%s。"
+
+// promptBody 默认提示模板的通用部分，说明智能体的能力与输出格式
+// 占位符依次为：消息类型、消息来源、消息主题、消息内容
+const promptBody = `你可以：
+1. 处理用户消息并直接回复
+2. 调用可用工具完成任务
+3. 向其他智能体发送消息
+
+消息类型: %s
+消息来源: %s
+消息主题: %s
+
+消息内容:
+%s
+
+如需调用工具，请使用格式：
+{"tool":"工具名称","input":"参数"}
+
+如需发送消息给其他智能体，请使用格式：
+{"send_to":"目标智能体ID","message":"消息内容"}
+如需等待对方回复后再继续处理，请加上 "await_reply":true`
+
+// rolePrompts 各智能体类型的角色说明，不能包含格式化占位符
+var rolePrompts = map[AgentType]string{
+	AgentTypeWorldview: "你负责构建小说的世界观：地理、历史、势力、力量体系与运行法则。" +
+		"新增设定必须与已有设定自洽，避免前后矛盾；描述具体可感，留出供剧情展开的空间。",
+	AgentTypeCharacter: "你负责塑造小说角色：姓名、外貌、性格、动机、成长弧线与人物关系。" +
+		"角色的言行要符合其背景和世界观设定，主要角色需有鲜明的欲望与内在冲突。",
+	AgentTypePlot: "你负责设计小说情节：主线、支线、冲突、转折与节奏。" +
+		"情节推进要有因果，伏笔要有回收，每个场景都应推动故事或塑造人物。",
+	AgentTypeDialogue: "你负责撰写角色对话。对话要贴合角色身份、性格与当下情绪，" +
+		"通过潜台词传递信息，避免所有角色说话腔调雷同。",
+	AgentTypeBackground: "你负责描写故事背景：时代、地点与关键事件。" +
+		"背景描写要服务于情节与氛围，细节与世界观保持一致。",
+}
+
+// DefaultPromptFor 返回指定智能体类型的默认提示模板
+// 模板包含5个占位符，依次为：智能体类型、消息类型、消息来源、消息主题、消息内容；
+// 没有专门角色说明的类型返回通用模板
+func DefaultPromptFor(agentType AgentType) string {
+	role, ok := rolePrompts[agentType]
+	if !ok {
+		return promptHeader + promptBody
+	}
+	return promptHeader + role + "\n" + promptBody
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// DefaultToolCacheTTL 工具结果缓存的默认有效期
+const DefaultToolCacheTTL = 5 * time.Minute
+
+// toolSessionKey 工具调用会话在 context 中的键
+type toolSessionKey struct{}
+
+// ContextWithToolSession 将工具调用会话写入 context，同一会话内的工具结果可以复用
+func ContextWithToolSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, toolSessionKey{}, sessionID)
+}
+
+// ToolSessionFromContext 从 context 中读取工具调用会话，不存在时返回空字符串
+func ToolSessionFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(toolSessionKey{}).(string)
+	return sessionID
+}
+
+// toolSessionID 返回消息所属的工具调用会话：优先使用 CorrelationID，其次为追踪ID，最后为消息ID
+func toolSessionID(msg *Message) string {
+	if msg.CorrelationID != "" {
+		return msg.CorrelationID
+	}
+	if traceID := msg.TraceID(); traceID != "" {
+		return traceID
+	}
+	return msg.ID
+}
+
+// toolCacheEntry 缓存的工具结果
+type toolCacheEntry struct {
+	result   string
+	expireAt time.Time
+}
+
+// CachingToolCaller 为只读工具提供短期结果缓存的工具调用器
+// 同一会话内以相同输入调用已开启缓存的工具时直接返回缓存结果，不再调用底层工具；
+// ctx 中没有会话或调用失败时不缓存。可并发使用
+type CachingToolCaller struct {
+	caller ToolCaller
+	ttl    time.Duration
+	clock  Clock
+
+	mu        sync.Mutex
+	cacheable map[string]bool
+	entries   map[string]toolCacheEntry
+}
+
+// NewCachingToolCaller 创建带结果缓存的工具调用器
+// caller: 底层工具调用器，ttl: 缓存有效期（小于等于0时使用 DefaultToolCacheTTL），cacheableTools: 开启缓存的工具名称
+func NewCachingToolCaller(caller ToolCaller, ttl time.Duration, cacheableTools ...string) *CachingToolCaller {
+	if ttl <= 0 {
+		ttl = DefaultToolCacheTTL
+	}
+	c := &CachingToolCaller{
+		caller:    caller,
+		ttl:       ttl,
+		clock:     SystemClock,
+		cacheable: make(map[string]bool, len(cacheableTools)),
+		entries:   make(map[string]toolCacheEntry),
+	}
+	for _, name := range cacheableTools {
+		c.cacheable[name] = true
+	}
+	return c
+}
+
+// SetClock 设置缓存过期判断使用的时钟，传入nil时恢复为系统时钟
+func (c *CachingToolCaller) SetClock(clock Clock) {
+	if clock == nil {
+		clock = SystemClock
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clock
+}
+
+// SetCacheable 设置工具是否开启缓存，关闭时清除该工具已有的缓存
+func (c *CachingToolCaller) SetCacheable(toolName string, cacheable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cacheable {
+		c.cacheable[toolName] = true
+		return
+	}
+	delete(c.cacheable, toolName)
+	c.entries = make(map[string]toolCacheEntry)
+}
+
+// Call 实现ToolCaller接口
+func (c *CachingToolCaller) Call(ctx context.Context, toolName string, input string) (string, error) {
+	sessionID := ToolSessionFromContext(ctx)
+
+	c.mu.Lock()
+	cacheable := sessionID != "" && c.cacheable[toolName]
+	key := toolCacheKey(sessionID, toolName, input)
+	if cacheable {
+		if entry, ok := c.entries[key]; ok && c.clock.Now().Before(entry.expireAt) {
+			c.mu.Unlock()
+			return entry.result, nil
+		}
+	}
+	c.mu.Unlock()
+
+	result, err := c.caller.Call(ctx, toolName, input)
+	if err != nil || !cacheable {
+		return result, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.clock.Now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expireAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = toolCacheEntry{result: result, expireAt: now.Add(c.ttl)}
+	return result, nil
+}
+
+// GetAvailableTools 实现ToolCaller接口
+func (c *CachingToolCaller) GetAvailableTools() []tools.Tool {
+	return c.caller.GetAvailableTools()
+}
+
+// toolCacheKey 由会话、工具名称与输入哈希组成缓存键
+func toolCacheKey(sessionID, toolName, input string) string {
+	sum := sha256.Sum256([]byte(input))
+	return sessionID + "\x00" + toolName + "\x00" + hex.EncodeToString(sum[:])
+}
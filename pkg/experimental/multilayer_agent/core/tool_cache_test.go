@@ -0,0 +1,77 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// countingToolCaller 记录调用次数的工具调用器
+type countingToolCaller struct {
+	calls int32
+}
+
+func (c *countingToolCaller) Call(ctx context.Context, toolName string, input string) (string, error) {
+	n := atomic.AddInt32(&c.calls, 1)
+	return fmt.Sprintf("%s:%s:%d", toolName, input, n), nil
+}
+
+func (c *countingToolCaller) GetAvailableTools() []tools.Tool {
+	return []tools.Tool{namedTool{"search"}}
+}
+
+// TestCachingToolCaller 测试同一会话内相同参数的调用命中缓存
+func TestCachingToolCaller(t *testing.T) {
+	base := &countingToolCaller{}
+	caller := NewCachingToolCaller(base, time.Minute, "search")
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)}
+	caller.SetClock(clock)
+
+	ctx := ContextWithToolSession(context.Background(), "task-1")
+	first, err := caller.Call(ctx, "search", "星海帝国")
+	require.NoError(t, err)
+	second, err := caller.Call(ctx, "search", "星海帝国")
+	require.NoError(t, err)
+	assert.Equal(t, first, second, "第二次调用应返回缓存结果")
+	assert.EqualValues(t, 1, atomic.LoadInt32(&base.calls), "底层工具只应被调用一次")
+
+	// 不同输入、不同会话、未开启缓存的工具与无会话的调用都不命中缓存
+	_, _ = caller.Call(ctx, "search", "仙侠九州")
+	_, _ = caller.Call(ContextWithToolSession(context.Background(), "task-2"), "search", "星海帝国")
+	_, _ = caller.Call(ctx, "write", "星海帝国")
+	_, _ = caller.Call(ctx, "write", "星海帝国")
+	_, _ = caller.Call(context.Background(), "search", "星海帝国")
+	assert.EqualValues(t, 6, atomic.LoadInt32(&base.calls))
+
+	// 过期后重新调用底层工具
+	clock.Advance(time.Minute)
+	_, _ = caller.Call(ctx, "search", "星海帝国")
+	assert.EqualValues(t, 7, atomic.LoadInt32(&base.calls))
+
+	assert.Len(t, caller.GetAvailableTools(), 1)
+}
+
+// TestGenericAdvancedAgentToolCache 测试智能体在同一 CorrelationID 下重复调用工具时命中缓存
+func TestGenericAdvancedAgentToolCache(t *testing.T) {
+	base := &countingToolCaller{}
+	agent := NewGenericAdvancedAgent("world-agent", AgentTypeWorldview, "")
+	agent.SetToolCaller(NewCachingToolCaller(base, 0, "search"))
+	agent.SetModel(newStubModel(func(ctx context.Context, prompt string) (string, error) {
+		return `{"tool":"search","input":"星海帝国"}`, nil
+	}))
+
+	for i := 0; i < 2; i++ {
+		msg := NewMessage(MessageTypeRequest, "tester", "world-agent")
+		msg.CorrelationID = "task-1"
+		response, err := agent.Process(context.Background(), msg)
+		require.NoError(t, err)
+		assert.Equal(t, "search:星海帝国:1", response.Content)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&base.calls))
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/tmc/langchaingo/tools"
+)
+
+// ErrToolNotAllowed 工具不在该智能体类型的工具白名单内
+var ErrToolNotAllowed = errors.New("智能体类型无权使用该工具")
+
+// ToolWhitelist 按智能体类型配置的可用工具白名单（类型→工具名称列表）
+// 类型未出现在表中时不受限制；出现但列表为空时该类型不能使用任何工具
+type ToolWhitelist map[AgentType][]string
+
+// Restricts 判断该类型是否配置了白名单
+func (w ToolWhitelist) Restricts(agentType AgentType) bool {
+	_, ok := w[agentType]
+	return ok
+}
+
+// Allows 判断该类型能否使用指定工具
+func (w ToolWhitelist) Allows(agentType AgentType, toolName string) bool {
+	allowed, ok := w[agentType]
+	return !ok || slices.Contains(allowed, toolName)
+}
+
+// Filter 过滤出该类型可用的工具，类型不受限制时原样返回
+func (w ToolWhitelist) Filter(agentType AgentType, available []tools.Tool) []tools.Tool {
+	if !w.Restricts(agentType) {
+		return available
+	}
+	filtered := make([]tools.Tool, 0, len(available))
+	for _, tool := range available {
+		if w.Allows(agentType, tool.Name()) {
+			filtered = append(filtered, tool)
+		}
+	}
+	return filtered
+}
+
+// whitelistedToolCaller 按智能体类型白名单限制工具的调用器
+type whitelistedToolCaller struct {
+	caller    ToolCaller
+	agentType AgentType
+	whitelist ToolWhitelist
+}
+
+// NewWhitelistedToolCaller 包装工具调用器，只暴露并允许调用该类型白名单内的工具
+// 类型未配置白名单时直接返回原调用器
+func NewWhitelistedToolCaller(caller ToolCaller, agentType AgentType, whitelist ToolWhitelist) ToolCaller {
+	if caller == nil || !whitelist.Restricts(agentType) {
+		return caller
+	}
+	return &whitelistedToolCaller{caller: caller, agentType: agentType, whitelist: whitelist}
+}
+
+// Call 实现ToolCaller接口，白名单外的工具返回 ErrToolNotAllowed
+func (c *whitelistedToolCaller) Call(ctx context.Context, toolName string, input string) (string, error) {
+	if !c.whitelist.Allows(c.agentType, toolName) {
+		return "", fmt.Errorf("%w: %s 不能使用 %s", ErrToolNotAllowed, c.agentType, toolName)
+	}
+	return c.caller.Call(ctx, toolName, input)
+}
+
+// GetAvailableTools 实现ToolCaller接口，只返回白名单内的工具
+func (c *whitelistedToolCaller) GetAvailableTools() []tools.Tool {
+	return c.whitelist.Filter(c.agentType, c.caller.GetAvailableTools())
+}
+
+// applyToolWhitelist 注册时按类型白名单限制智能体的工具
+// 包装其工具调用器，并过滤显式设置的可用工具列表
+func (o *Orchestrator) applyToolWhitelist(agent Agent) {
+	whitelist := o.config.ToolWhitelist
+	agentType := agent.GetType()
+	if !whitelist.Restricts(agentType) {
+		return
+	}
+
+	if a, ok := agent.(interface {
+		GetToolCaller() ToolCaller
+		SetToolCaller(caller ToolCaller)
+	}); ok && a.GetToolCaller() != nil {
+		a.SetToolCaller(NewWhitelistedToolCaller(a.GetToolCaller(), agentType, whitelist))
+	}
+	if a, ok := agent.(interface {
+		GetAvailableTools() []tools.Tool
+		SetAvailableTools(tools []tools.Tool)
+	}); ok {
+		a.SetAvailableTools(whitelist.Filter(agentType, a.GetAvailableTools()))
+	}
+}
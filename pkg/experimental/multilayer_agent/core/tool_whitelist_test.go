@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/tools"
+)
+
+// toolNames 返回工具名称列表
+func toolNames(list []tools.Tool) []string {
+	names := make([]string, 0, len(list))
+	for _, tool := range list {
+		names = append(names, tool.Name())
+	}
+	return names
+}
+
+// TestToolWhitelist 测试为某类型配置白名单后其可用工具只含白名单内的，其他类型不受影响
+func TestToolWhitelist(t *testing.T) {
+	config := DefaultOrchestratorConfig()
+	config.ToolWhitelist = ToolWhitelist{AgentTypeWorldview: {"search"}}
+	o := NewOrchestrator(config)
+
+	caller := &staticToolCaller{tools: []tools.Tool{namedTool{"search"}, namedTool{"update_db"}, namedTool{"calc"}}}
+	world := NewGenericAdvancedAgent("world-1", AgentTypeWorldview, "")
+	world.SetModel(newStubModel(nil))
+	world.SetToolCaller(caller)
+	plot := NewGenericAdvancedAgent("plot-1", AgentTypePlot, "")
+	plot.SetModel(newStubModel(nil))
+	plot.SetToolCaller(caller)
+	require.NoError(t, o.RegisterAgent(world))
+	require.NoError(t, o.RegisterAgent(plot))
+
+	assert.Equal(t, []string{"search"}, toolNames(world.GetAvailableTools()))
+	assert.Equal(t, []string{"search"}, toolNames(world.GetToolCaller().GetAvailableTools()))
+	assert.Equal(t, []string{"search", "update_db", "calc"}, toolNames(plot.GetAvailableTools()))
+
+	// 白名单外的工具即使绕过列表直接调用也会被拒绝
+	_, err := world.CallTool(context.Background(), "update_db", "DROP TABLE")
+	assert.ErrorIs(t, err, ErrToolNotAllowed)
+	result, err := world.CallTool(context.Background(), "search", "北境")
+	require.NoError(t, err)
+	assert.Equal(t, "北境", result)
+	_, err = plot.CallTool(context.Background(), "update_db", "x")
+	assert.NoError(t, err)
+
+	// 显式设置的工具列表同样按白名单过滤
+	explicit := NewGenericAdvancedAgent("world-2", AgentTypeWorldview, "")
+	explicit.SetModel(newStubModel(nil))
+	explicit.SetAvailableTools([]tools.Tool{namedTool{"calc"}, namedTool{"search"}})
+	require.NoError(t, o.RegisterAgent(explicit))
+	assert.Equal(t, []string{"search"}, toolNames(explicit.GetAvailableTools()))
+
+	// 注册被拒绝的智能体不应被修改
+	duplicate := NewGenericAdvancedAgent("world-1", AgentTypeWorldview, "")
+	duplicate.SetModel(newStubModel(nil))
+	duplicate.SetToolCaller(caller)
+	require.Error(t, o.RegisterAgent(duplicate))
+	assert.Same(t, caller, duplicate.GetToolCaller())
+	assert.Equal(t, []string{"search", "update_db", "calc"}, toolNames(duplicate.GetAvailableTools()))
+}
+
+// TestBuildOrchestratorToolWhitelist 测试声明式配置中智能体声明了白名单外的工具时装配失败
+func TestBuildOrchestratorToolWhitelist(t *testing.T) {
+	cfg, err := LoadSystemConfig([]byte(`{
+		"orchestrator": {"tool_whitelist": {"worldview": ["search"]}},
+		"agents": [
+			{"id": "world-1", "type": "worldview", "tools": ["search"]},
+			{"id": "plot-1", "type": "plot", "tools": ["search", "calc"]}
+		]
+	}`))
+	require.NoError(t, err)
+	caller := &staticToolCaller{tools: []tools.Tool{namedTool{"search"}, namedTool{"calc"}}}
+	o, err := BuildOrchestratorFromConfig(cfg, &BuildOptions{ModelFactory: &recordingModelFactory{}, ToolCaller: caller})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"search"}, o.config.ToolWhitelist[AgentTypeWorldview])
+
+	cfg.Agents = append(cfg.Agents, AgentConfig{ID: "world-2", Type: AgentTypeWorldview, Tools: []string{"calc"}})
+	_, err = BuildOrchestratorFromConfig(cfg, &BuildOptions{ModelFactory: &recordingModelFactory{}, ToolCaller: caller})
+	assert.ErrorIs(t, err, ErrToolNotAllowed)
+	assert.ErrorContains(t, err, "world-2")
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// 追踪上下文在消息元数据中的约定键
+const (
+	MetadataTraceID = "trace_id" // 链路追踪ID，整条调用链保持不变
+	MetadataSpanID  = "span_id"  // 产生该消息的处理片段ID
+)
+
+// TraceContext 链路追踪上下文
+// TraceID 标识整条调用链；SpanID 标识当前处理片段，ParentSpanID 为上游片段
+type TraceContext struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+}
+
+// NewTraceContext 创建一条新链路的根追踪上下文
+func NewTraceContext() TraceContext {
+	return TraceContext{TraceID: randomHex(16), SpanID: randomHex(8)}
+}
+
+// IsValid 判断追踪上下文是否有效
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != ""
+}
+
+// ChildSpan 在同一链路下派生子片段
+func (tc TraceContext) ChildSpan() TraceContext {
+	return TraceContext{TraceID: tc.TraceID, SpanID: randomHex(8), ParentSpanID: tc.SpanID}
+}
+
+// traceContextKey 追踪上下文在 context 中的键
+type traceContextKey struct{}
+
+// ContextWithTrace 将追踪上下文写入 context
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceFromContext 从 context 中读取追踪上下文
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok && tc.IsValid()
+}
+
+// InjectTrace 将 ctx 中的追踪上下文写入消息元数据，ctx 中没有追踪信息时不做修改
+func (m *Message) InjectTrace(ctx context.Context) {
+	if tc, ok := TraceFromContext(ctx); ok {
+		m.setTrace(tc)
+	}
+}
+
+// ExtractTrace 返回携带消息追踪上下文的 ctx，消息没有追踪信息时原样返回
+func (m *Message) ExtractTrace(ctx context.Context) context.Context {
+	if tc, ok := m.Trace(); ok {
+		return ContextWithTrace(ctx, tc)
+	}
+	return ctx
+}
+
+// Trace 读取消息元数据中的追踪上下文
+func (m *Message) Trace() (TraceContext, bool) {
+	traceID, _ := m.metadataString(MetadataTraceID)
+	if traceID == "" {
+		return TraceContext{}, false
+	}
+	spanID, _ := m.metadataString(MetadataSpanID)
+	return TraceContext{TraceID: traceID, SpanID: spanID}, true
+}
+
+// TraceID 返回消息的链路追踪ID，没有时返回空字符串
+func (m *Message) TraceID() string {
+	tc, _ := m.Trace()
+	return tc.TraceID
+}
+
+// setTrace 将追踪上下文写入消息元数据
+func (m *Message) setTrace(tc TraceContext) {
+	m.SetMetadata(MetadataTraceID, tc.TraceID)
+	m.SetMetadata(MetadataSpanID, tc.SpanID)
+}
+
+// metadataString 读取字符串类型的元数据
+func (m *Message) metadataString(key string) (string, bool) {
+	value, ok := m.GetMetadata(key)
+	if !ok {
+		return "", false
+	}
+	s, ok := value.(string)
+	return s, ok
+}
+
+// randomHex 生成指定字节数的随机十六进制串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTraceContextRoundTrip 测试追踪上下文在 ctx 与消息元数据之间注入和提取
+func TestTraceContextRoundTrip(t *testing.T) {
+	tc := NewTraceContext()
+	require.True(t, tc.IsValid())
+
+	msg := NewMessage(MessageTypeRequest, "tester", "agent-a")
+	_, ok := msg.Trace()
+	assert.False(t, ok, "新消息不应带追踪信息")
+
+	msg.InjectTrace(ContextWithTrace(context.Background(), tc))
+	assert.Equal(t, tc.TraceID, msg.TraceID())
+
+	extracted, ok := TraceFromContext(msg.ExtractTrace(context.Background()))
+	require.True(t, ok)
+	assert.Equal(t, tc.TraceID, extracted.TraceID)
+	assert.Equal(t, tc.SpanID, extracted.SpanID)
+
+	child := tc.ChildSpan()
+	assert.Equal(t, tc.TraceID, child.TraceID)
+	assert.Equal(t, tc.SpanID, child.ParentSpanID)
+	assert.NotEqual(t, tc.SpanID, child.SpanID)
+
+	assert.Equal(t, tc.TraceID, msg.Forward("agent-a", "agent-b").TraceID(), "转发消息应保留追踪ID")
+}
+
+// TestTracePropagation 测试带追踪ID的消息经处理和转发后追踪ID保持一致
+func TestTracePropagation(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+
+	var mu sync.Mutex
+	seen := map[string]TraceContext{}
+	record := func(agentID string, ctx context.Context) {
+		tc, _ := TraceFromContext(ctx)
+		mu.Lock()
+		seen[agentID] = tc
+		mu.Unlock()
+	}
+
+	// agent-a 新建一条发往 agent-c 的请求，不手动传递追踪信息
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-a", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		record("agent-a", ctx)
+		next := NewMessage(MessageTypeRequest, "agent-a", "agent-c")
+		next.Content = msg.Content
+		return next, nil
+	})))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-c", AgentTypeDialogue, func(ctx context.Context, msg *Message) (*Message, error) {
+		record("agent-c", ctx)
+		return echoProcess("agent-c")(ctx, msg)
+	})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	root := NewTraceContext()
+	ctx, cancel := context.WithTimeout(ContextWithTrace(context.Background(), root), 5*time.Second)
+	defer cancel()
+
+	msg := NewMessage(MessageTypeRequest, "tester", "agent-a")
+	msg.Content = "追踪"
+	resp, err := o.SendMessage(ctx, msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, root.TraceID, msg.TraceID(), "发送时应从 ctx 注入追踪信息")
+	assert.Equal(t, root.TraceID, resp.TraceID(), "最终响应应延续追踪ID")
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, seen, 2)
+	assert.Equal(t, root.TraceID, seen["agent-a"].TraceID)
+	assert.Equal(t, root.TraceID, seen["agent-c"].TraceID)
+	assert.Equal(t, root.SpanID, seen["agent-a"].ParentSpanID, "第一跳的父片段应为发送方片段")
+	assert.Equal(t, seen["agent-a"].SpanID, seen["agent-c"].ParentSpanID, "转发后的父片段应为上一跳的处理片段")
+}
+
+// TestTracePropagationBroadcast 测试广播消息时追踪ID传递到每个接收方
+func TestTracePropagationBroadcast(t *testing.T) {
+	o := newTestOrchestrator(t, 2)
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-a", AgentTypePlot, echoProcess("agent-a"))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("agent-b", AgentTypePlot, echoProcess("agent-b"))))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	root := NewTraceContext()
+	msg := NewMessage(MessageTypeRequest, "tester", "")
+	msg.InjectTrace(ContextWithTrace(context.Background(), root))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	responses, err := o.BroadcastMessage(ctx, AgentTypePlot, msg)
+	require.NoError(t, err)
+	require.Len(t, responses, 2)
+	for _, resp := range responses {
+		assert.Equal(t, root.TraceID, resp.TraceID())
+	}
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// ErrNoVoteCandidates 投票时没有收到任何有效方案
+var ErrNoVoteCandidates = errors.New("没有可供投票的方案")
+
+// ErrInvalidVoteChoice 评判结果不在候选方案范围内
+var ErrInvalidVoteChoice = errors.New("评判结果无效")
+
+// VoteJudge 评判函数，从候选方案中选出最佳方案，返回其下标
+type VoteJudge func(ctx context.Context, candidates []*Message) (int, error)
+
+// VoteResult 投票结果
+type VoteResult struct {
+	Winner     *Message   // 胜出的方案
+	Candidates []*Message // 参与投票的全部方案，按发送方ID排序
+}
+
+// Vote 将消息广播给指定类型的所有智能体，收集各自方案后由评判函数选出最佳
+// 发送失败或返回错误消息的智能体不参与投票；部分智能体失败时只记录日志，
+// 没有任何有效方案时返回 ErrNoVoteCandidates
+func (o *Orchestrator) Vote(ctx context.Context, agentType AgentType, msg *Message, judge VoteJudge) (*VoteResult, error) {
+	if judge == nil {
+		return nil, errors.New("评判函数不能为空")
+	}
+
+	responses, err := o.BroadcastMessage(ctx, agentType, msg)
+	if err != nil {
+		if len(responses) == 0 {
+			return nil, err
+		}
+		hlog.CtxWarnf(ctx, "投票时部分智能体未返回方案: %v", err)
+	}
+
+	candidates := make([]*Message, 0, len(responses))
+	for _, resp := range responses {
+		if resp != nil && !resp.IsError() {
+			candidates = append(candidates, resp)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoVoteCandidates
+	}
+	// 广播响应的顺序不确定，按发送方排序使评判结果可复现
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].From < candidates[j].From })
+
+	choice, err := judge(ctx, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("评判方案失败: %w", err)
+	}
+	if choice < 0 || choice >= len(candidates) {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidVoteChoice, choice)
+	}
+	return &VoteResult{Winner: candidates[choice], Candidates: candidates}, nil
+}
+
+// votePromptTemplate 评判智能体的提示内容，参数为编号后的方案列表
+const votePromptTemplate = `下面是针对同一问题的多个方案，请选出最佳的一个。
+%s
+只输出最佳方案的编号。`
+
+// voteChoicePattern 评判智能体回复中的方案编号
+var voteChoicePattern = regexp.MustCompile(`\d+`)
+
+// AgentJudge 返回交给评判智能体选择方案的评判函数
+// 方案按从1开始的编号发给评判智能体，回复中的第一个数字作为所选编号
+func (o *Orchestrator) AgentJudge(evaluatorID string) VoteJudge {
+	return func(ctx context.Context, candidates []*Message) (int, error) {
+		var b strings.Builder
+		for i, candidate := range candidates {
+			fmt.Fprintf(&b, "方案%d（来自%s）：%s\n", i+1, candidate.From, candidate.Content)
+		}
+
+		request := NewMessage(MessageTypeRequest, "vote", evaluatorID)
+		request.Subject = "方案评判"
+		request.Content = fmt.Sprintf(votePromptTemplate, b.String())
+		resp, err := o.SendMessage(ctx, request)
+		if err != nil {
+			return 0, err
+		}
+
+		match := voteChoicePattern.FindString(resp.Content)
+		if match == "" {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidVoteChoice, resp.Content)
+		}
+		n, err := strconv.Atoi(match)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidVoteChoice, match)
+		}
+		return n - 1, nil
+	}
+}
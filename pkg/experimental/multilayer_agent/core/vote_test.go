@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replyProcess 固定回复指定内容
+func replyProcess(agentID, content string) func(ctx context.Context, msg *Message) (*Message, error) {
+	return func(ctx context.Context, msg *Message) (*Message, error) {
+		response := NewMessage(MessageTypeResponse, agentID, msg.From)
+		response.Content = content
+		response.ReplyTo = msg.ID
+		return response, nil
+	}
+}
+
+// newVoteOrchestrator 创建注册了三个情节智能体的编排器，各自返回不同方案
+func newVoteOrchestrator(t *testing.T) *Orchestrator {
+	o := newTestOrchestrator(t, 4)
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-1", AgentTypePlot, replyProcess("plot-1", "主角离开北境"))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-2", AgentTypePlot, replyProcess("plot-2", "主角留守要塞并揭开叛徒身份"))))
+	require.NoError(t, o.RegisterAgent(newFuncAgent("plot-3", AgentTypePlot, func(ctx context.Context, msg *Message) (*Message, error) {
+		return nil, errors.New("模型不可用")
+	})))
+	return o
+}
+
+// TestVoteWithJudge 测试多个智能体返回不同方案时按评判规则选出预期的一个
+func TestVoteWithJudge(t *testing.T) {
+	o := newVoteOrchestrator(t)
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	// 评判规则：选内容最长的方案
+	longest := func(ctx context.Context, candidates []*Message) (int, error) {
+		best := 0
+		for i, c := range candidates {
+			if len(c.Content) > len(candidates[best].Content) {
+				best = i
+			}
+		}
+		return best, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := o.Vote(ctx, AgentTypePlot, NewMessage(MessageTypeRequest, "tester", ""), longest)
+	require.NoError(t, err)
+	assert.Equal(t, "plot-2", result.Winner.From)
+	require.Len(t, result.Candidates, 2, "失败的智能体不参与投票")
+	assert.Equal(t, "plot-1", result.Candidates[0].From, "候选方案按发送方排序")
+
+	_, err = o.Vote(ctx, AgentTypePlot, NewMessage(MessageTypeRequest, "tester", ""), func(ctx context.Context, candidates []*Message) (int, error) {
+		return len(candidates), nil
+	})
+	assert.ErrorIs(t, err, ErrInvalidVoteChoice)
+
+	_, err = o.Vote(ctx, AgentTypeDialogue, NewMessage(MessageTypeRequest, "tester", ""), longest)
+	assert.Error(t, err, "没有该类型的智能体时应返回错误")
+}
+
+// TestVoteWithEvaluatorAgent 测试交给评判智能体选出方案
+func TestVoteWithEvaluatorAgent(t *testing.T) {
+	o := newVoteOrchestrator(t)
+	var prompt string
+	require.NoError(t, o.RegisterAgent(newFuncAgent("judge-1", AgentTypeEvaluator, func(ctx context.Context, msg *Message) (*Message, error) {
+		prompt = msg.Content
+		return replyProcess("judge-1", "最佳方案是 1")(ctx, msg)
+	})))
+	require.NoError(t, o.Start())
+	defer o.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := o.Vote(ctx, AgentTypePlot, NewMessage(MessageTypeRequest, "tester", ""), o.AgentJudge("judge-1"))
+	require.NoError(t, err)
+	assert.Equal(t, "plot-1", result.Winner.From)
+	assert.Contains(t, prompt, "方案2（来自plot-2）：主角留守要塞并揭开叛徒身份")
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"strings"
+)
+
+// sessionKeyPrefix 会话命名空间键的前缀
+const sessionKeyPrefix = "session|"
+
+// sessionContextKey 会话ID在 context 中的键
+type sessionContextKey struct{}
+
+// ContextWithSession 将会话ID写入 context
+// 同一智能体在不同会话下读写的记忆互不可见
+func ContextWithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, sessionID)
+}
+
+// SessionFromContext 从 context 中读取会话ID，不存在时返回空字符串
+func SessionFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionContextKey{}).(string)
+	return sessionID
+}
+
+// SessionKey 为键加上会话命名空间
+// sessionID: 会话ID，为空时原样返回键；不应包含 "|"
+// key: 原始键名，通常由 CreateTaggedKey 生成
+// 返回: 格式化的键名 "session|sessionID|key"
+func SessionKey(sessionID, key string) string {
+	if sessionID == "" {
+		return key
+	}
+	return sessionKeyPrefix + sessionID + "|" + key
+}
+
+// SplitSessionKey 从带会话命名空间的键中拆出会话ID和原始键
+// 不带会话命名空间的键返回空会话ID和原键
+func SplitSessionKey(key string) (string, string) {
+	rest, ok := strings.CutPrefix(key, sessionKeyPrefix)
+	if !ok {
+		return "", key
+	}
+	sessionID, inner, ok := strings.Cut(rest, "|")
+	if !ok {
+		return "", key
+	}
+	return sessionID, inner
+}
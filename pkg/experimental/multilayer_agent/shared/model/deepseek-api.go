@@ -2,9 +2,13 @@
 package model
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -12,6 +16,24 @@ import (
 	"github.com/tmc/langchaingo/llms"
 )
 
+// DefaultDeepSeekBaseURL 默认的DeepSeek API端点
+const DefaultDeepSeekBaseURL = "https://api.deepseek.com/v1"
+
+// deepSeekRequestTimeout DeepSeek请求超时时间，适用于复杂生成任务
+const deepSeekRequestTimeout = 120 * time.Second
+
+// newPooledHTTPClient 创建带连接池的HTTP客户端
+func newPooledHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 100
+	transport.MaxIdleConnsPerHost = 20
+	transport.IdleConnTimeout = 90 * time.Second
+	return &http.Client{
+		Timeout:   deepSeekRequestTimeout,
+		Transport: transport,
+	}
+}
+
 // DeepSeekModel 实现了基于DeepSeek API的Model接口
 // 提供云端高性能模型服务，支持结构化输出和高级推理能力
 type DeepSeekModel struct {
@@ -22,10 +44,27 @@ type DeepSeekModel struct {
 	options    ModelOptions
 }
 
+// ErrVisionNotSupported 消息包含图像，但模型不支持图像输入
+var ErrVisionNotSupported = errors.New("模型不支持图像输入")
+
 // DeepSeekMessage 定义了DeepSeek API的消息格式
+// Content 为纯文本时是字符串，包含图像时是 []DeepSeekContentPart
 type DeepSeekMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// DeepSeekContentPart 多模态消息中的一段内容
+type DeepSeekContentPart struct {
+	Type     string            `json:"type"`                // 内容类型：text 或 image_url
+	Text     string            `json:"text,omitempty"`      // 文本内容
+	ImageURL *DeepSeekImageURL `json:"image_url,omitempty"` // 图像内容
+}
+
+// DeepSeekImageURL 图像内容，URL 可以是网络地址或 base64 data URL
+type DeepSeekImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"`
 }
 
 // DeepSeekRequestBody 定义了DeepSeek API的请求体
@@ -79,12 +118,13 @@ func NewDeepSeekModel(options ModelOptions) (Model, error) {
 
 	baseURL := options.BaseURL
 	if baseURL == "" {
-		baseURL = "https://api.deepseek.com/v1" // 默认API端点
+		baseURL = DefaultDeepSeekBaseURL // 默认API端点
 	}
 
-	// 创建HTTP客户端
-	httpClient := &http.Client{
-		Timeout: 120 * time.Second, // 设置较长的超时时间，适用于复杂生成任务
+	// 优先使用注入的HTTP客户端，否则单独创建
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = newPooledHTTPClient()
 	}
 
 	// 确定模型特性和限制
@@ -105,6 +145,10 @@ func NewDeepSeekModel(options ModelOptions) (Model, error) {
 		tokenLimit = 8192
 	}
 
+	if err := options.Validate(tokenLimit); err != nil {
+		return nil, err
+	}
+
 	// 创建基础ModelWrapper
 	wrapper := &ModelWrapper{
 		BaseModel:        nil, // DeepSeek模型不直接使用LangChain Go的基础模型
@@ -179,37 +223,9 @@ func (m *DeepSeekModel) GenerateContent(ctx context.Context, messages []llms.Mes
 	}
 
 	// 将LangChain消息转换为DeepSeek API消息格式
-	deepseekMessages := []DeepSeekMessage{}
-	for _, msg := range messages {
-		content := ""
-		
-		// 处理多模态内容
-		for _, part := range msg.Parts {
-			switch v := part.(type) {
-			case llms.TextContent:
-				content += v.Text
-			// 如果需要支持图像等其他内容，这里可以扩展
-			default:
-				if m.options.Debug {
-					fmt.Printf("不支持的内容类型: %T\n", part)
-				}
-			}
-		}
-
-		role := "user"
-		switch msg.Role {
-		case llms.ChatMessageTypeAI:
-			role = "assistant"
-		case llms.ChatMessageTypeSystem:
-			role = "system"
-		case llms.ChatMessageTypeHuman:
-			role = "user"
-		}
-
-		deepseekMessages = append(deepseekMessages, DeepSeekMessage{
-			Role:    role,
-			Content: content,
-		})
+	deepseekMessages, err := m.convertMessages(messages)
+	if err != nil {
+		return nil, err
 	}
 
 	// 发送请求
@@ -245,51 +261,116 @@ func (m *DeepSeekModel) GenerateContent(ctx context.Context, messages []llms.Mes
 	return contentResponse, nil
 }
 
+// convertMessages 将LangChain消息转换为DeepSeek API消息格式
+// 只含文本的消息合并为字符串内容；包含图像（ImageURLContent 或 BinaryContent）时转换为多模态内容，
+// 此时模型必须支持图像输入，否则返回 ErrVisionNotSupported
+func (m *DeepSeekModel) convertMessages(messages []llms.MessageContent) ([]DeepSeekMessage, error) {
+	deepseekMessages := make([]DeepSeekMessage, 0, len(messages))
+	for _, msg := range messages {
+		var text strings.Builder
+		parts := make([]DeepSeekContentPart, 0, len(msg.Parts))
+		hasImage := false
+
+		for _, part := range msg.Parts {
+			switch v := part.(type) {
+			case llms.TextContent:
+				text.WriteString(v.Text)
+				parts = append(parts, DeepSeekContentPart{Type: "text", Text: v.Text})
+			case llms.ImageURLContent:
+				hasImage = true
+				parts = append(parts, DeepSeekContentPart{Type: "image_url", ImageURL: &DeepSeekImageURL{URL: v.URL, Detail: v.Detail}})
+			case llms.BinaryContent:
+				if !strings.HasPrefix(v.MIMEType, "image/") {
+					return nil, fmt.Errorf("不支持的二进制内容类型: %s", v.MIMEType)
+				}
+				hasImage = true
+				dataURL := "data:" + v.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(v.Data)
+				parts = append(parts, DeepSeekContentPart{Type: "image_url", ImageURL: &DeepSeekImageURL{URL: dataURL}})
+			default:
+				if m.options.Debug {
+					fmt.Printf("不支持的内容类型: %T\n", part)
+				}
+			}
+		}
+		if hasImage && !m.SupportsVision() {
+			return nil, fmt.Errorf("%w: %s", ErrVisionNotSupported, m.Name)
+		}
+
+		role := "user"
+		switch msg.Role {
+		case llms.ChatMessageTypeAI:
+			role = "assistant"
+		case llms.ChatMessageTypeSystem:
+			role = "system"
+		case llms.ChatMessageTypeHuman:
+			role = "user"
+		}
+
+		var content interface{} = text.String()
+		if hasImage {
+			content = parts
+		}
+		deepseekMessages = append(deepseekMessages, DeepSeekMessage{Role: role, Content: content})
+	}
+	return deepseekMessages, nil
+}
+
+// buildRequestBody 构建DeepSeek API请求体，调用选项未设置的参数使用模型默认值
+func (m *DeepSeekModel) buildRequestBody(messages []DeepSeekMessage, callOptions *llms.CallOptions) *DeepSeekRequestBody {
+	body := &DeepSeekRequestBody{
+		Model:       m.Name,
+		Messages:    messages,
+		Temperature: m.options.DefaultTemperature,
+		MaxTokens:   m.options.DefaultMaxTokens,
+		TopP:        callOptions.TopP,
+	}
+	if callOptions.Model != "" {
+		body.Model = callOptions.Model
+	}
+	if callOptions.Temperature > 0 {
+		body.Temperature = callOptions.Temperature
+	}
+	if callOptions.MaxTokens > 0 {
+		body.MaxTokens = callOptions.MaxTokens
+	}
+	return body
+}
+
 // sendRequest 发送请求到DeepSeek API并解析响应
 func (m *DeepSeekModel) sendRequest(ctx context.Context, messages []DeepSeekMessage, callOptions *llms.CallOptions) (*DeepSeekResponse, error) {
-	// 暂时使用模拟响应，实际实现需要发送HTTP请求到DeepSeek API
-	// 这里仅为示例，实际使用时应移除此代码
-	mockResponse := &DeepSeekResponse{
-		ID:        "mock-response-id",
-		Object:    "chat.completion",
-		Created:   time.Now().Unix(),
-		Model:     m.Name,
-		Choices: []struct {
-			Index        int `json:"index"`
-			Message      struct {
-				Role    string `json:"role"`
-				Content string `json:"content"`
-			} `json:"message"`
-			FinishReason string `json:"finish_reason"`
-		}{
-			{
-				Index: 0,
-				Message: struct {
-					Role    string `json:"role"`
-					Content string `json:"content"`
-				}{
-					Role:    "assistant",
-					Content: "这是一个模拟的DeepSeek API响应。在实际实现中，这里应该是模型生成的真实内容。",
-				},
-				FinishReason: "stop",
-			},
-		},
-		Usage: struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		}{
-			PromptTokens:     100,
-			CompletionTokens: 50,
-			TotalTokens:      150,
-		},
-	}
-
-	// TODO: 实现实际的DeepSeek API请求逻辑
-	// 1. 构建请求体
-	// 2. 发送HTTP请求
-	// 3. 解析响应
-	// 4. 处理错误
-
-	return mockResponse, nil
+	// 构建请求体
+	reqBody, err := json.Marshal(m.buildRequestBody(messages, callOptions))
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %w", err)
+	}
+	if m.options.Debug {
+		fmt.Printf("[DeepSeek请求] 请求体长度: %d字节\n", len(reqBody))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(m.baseURL, "/")+"/chat/completions", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DeepSeek API返回错误状态码 %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var response DeepSeekResponse
+	if err := json.Unmarshal(respBody, &response); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &response, nil
 }
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// newTestDeepSeekModel 创建测试用DeepSeek模型
+func newTestDeepSeekModel(t *testing.T, name string) *DeepSeekModel {
+	m, err := NewDeepSeekModel(ModelOptions{APIToken: "k", ModelName: name})
+	require.NoError(t, err)
+	return m.(*DeepSeekModel)
+}
+
+// TestDeepSeekConvertMessagesVision 测试vision模型带图像part时请求体含图像内容
+func TestDeepSeekConvertMessagesVision(t *testing.T) {
+	m := newTestDeepSeekModel(t, "deepseek-vl-7b-chat")
+	messages, err := m.convertMessages([]llms.MessageContent{
+		{Role: llms.ChatMessageTypeSystem, Parts: []llms.ContentPart{llms.TextContent{Text: "你是插画师"}}},
+		{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{
+			llms.TextContent{Text: "描述这张封面"},
+			llms.ImageURLContent{URL: "https://example.com/cover.png", Detail: "high"},
+			llms.BinaryContent{MIMEType: "image/png", Data: []byte("png")},
+		}},
+	})
+	require.NoError(t, err)
+
+	body, err := json.Marshal(m.buildRequestBody(messages, &llms.CallOptions{}))
+	require.NoError(t, err)
+	var decoded struct {
+		Messages []struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	require.Len(t, decoded.Messages, 2)
+	assert.JSONEq(t, `"你是插画师"`, string(decoded.Messages[0].Content), "纯文本消息保持字符串内容")
+	assert.JSONEq(t, `[
+		{"type":"text","text":"描述这张封面"},
+		{"type":"image_url","image_url":{"url":"https://example.com/cover.png","detail":"high"}},
+		{"type":"image_url","image_url":{"url":"data:image/png;base64,cG5n"}}
+	]`, string(decoded.Messages[1].Content))
+}
+
+// TestDeepSeekConvertMessagesNonVision 测试非vision模型收到图像part时报错
+func TestDeepSeekConvertMessagesNonVision(t *testing.T) {
+	m := newTestDeepSeekModel(t, "deepseek-chat")
+	_, err := m.GenerateContent(context.Background(), []llms.MessageContent{
+		{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{
+			llms.TextContent{Text: "描述这张封面"},
+			llms.ImageURLContent{URL: "https://example.com/cover.png"},
+		}},
+	})
+	assert.ErrorIs(t, err, ErrVisionNotSupported)
+
+	// 纯文本消息不受影响
+	messages, err := m.convertMessages([]llms.MessageContent{llms.TextParts(llms.ChatMessageTypeHuman, "你好")})
+	require.NoError(t, err)
+	assert.Equal(t, "你好", messages[0].Content)
+}
+
+// TestDeepSeekGenerateContentSendsImages 测试图像内容经HTTP请求发送到API，并解析真实响应
+func TestDeepSeekGenerateContentSendsImages(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"id":"r1","model":"deepseek-vl-7b-chat","choices":[{"index":0,"message":{"role":"assistant","content":"一座浮空城"},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":5,"total_tokens":17}}`)
+	}))
+	defer server.Close()
+
+	m, err := NewDeepSeekModel(ModelOptions{APIToken: "k", ModelName: "deepseek-vl-7b-chat", BaseURL: server.URL, HTTPClient: server.Client()})
+	require.NoError(t, err)
+	resp, err := m.GenerateContent(context.Background(), []llms.MessageContent{
+		{Role: llms.ChatMessageTypeHuman, Parts: []llms.ContentPart{
+			llms.TextContent{Text: "描述这张封面"},
+			llms.ImageURLContent{URL: "https://example.com/cover.png"},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/chat/completions", gotPath)
+	assert.Equal(t, "Bearer k", gotAuth)
+	var decoded struct {
+		Model    string `json:"model"`
+		Messages []struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(gotBody, &decoded))
+	assert.Equal(t, "deepseek-vl-7b-chat", decoded.Model)
+	require.Len(t, decoded.Messages, 1)
+	assert.JSONEq(t, `[
+		{"type":"text","text":"描述这张封面"},
+		{"type":"image_url","image_url":{"url":"https://example.com/cover.png"}}
+	]`, string(decoded.Messages[0].Content))
+
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "一座浮空城", resp.Choices[0].Content)
+	assert.Equal(t, 17, resp.Choices[0].GenerationInfo["total_tokens"])
+}
+
+// TestDeepSeekCallErrorStatus 测试API返回非200状态码时返回错误
+func TestDeepSeekCallErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	m, err := NewDeepSeekModel(ModelOptions{APIToken: "k", BaseURL: server.URL, HTTPClient: server.Client()})
+	require.NoError(t, err)
+	_, err = m.Call(context.Background(), "你好")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
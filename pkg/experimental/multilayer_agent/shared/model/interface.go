@@ -5,6 +5,8 @@ package model
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/tmc/langchaingo/llms"
 )
@@ -66,8 +68,15 @@ type ModelOptions struct {
 	DefaultTopP        float64
 	DefaultTopK        int
 
+	// 生成参数不合法时的处理策略，为空时按 ValidationReject 处理
+	Validation ValidationPolicy
+
 	// 调试模式
 	Debug bool
+
+	// HTTP客户端，为空时由工厂按 BaseURL 提供共享客户端
+	// 鉴权信息按模型实例在请求头中设置，共享客户端不会混用不同模型的 APIToken
+	HTTPClient *http.Client
 }
 
 // ModelFactory 提供创建模型实例的工厂接口
@@ -77,11 +86,33 @@ type ModelFactory interface {
 }
 
 // DefaultModelFactory 是ModelFactory的默认实现
-type DefaultModelFactory struct{}
+// 按 BaseURL 复用带连接池的共享 HTTP 客户端，避免频繁创建模型时浪费连接
+type DefaultModelFactory struct {
+	clientMutex sync.Mutex
+	httpClients map[string]*http.Client
+}
 
 // NewModelFactory 创建一个新的模型工厂实例
 func NewModelFactory() ModelFactory {
-	return &DefaultModelFactory{}
+	return &DefaultModelFactory{
+		httpClients: make(map[string]*http.Client),
+	}
+}
+
+// sharedHTTPClient 返回指定 BaseURL 的共享 HTTP 客户端，不存在时创建
+func (f *DefaultModelFactory) sharedHTTPClient(baseURL string) *http.Client {
+	f.clientMutex.Lock()
+	defer f.clientMutex.Unlock()
+
+	if f.httpClients == nil {
+		f.httpClients = make(map[string]*http.Client)
+	}
+	if client, ok := f.httpClients[baseURL]; ok {
+		return client
+	}
+	client := newPooledHTTPClient()
+	f.httpClients[baseURL] = client
+	return client
 }
 
 // CreateModel 创建指定类型和配置的模型实例
@@ -90,6 +121,13 @@ func (f *DefaultModelFactory) CreateModel(modelType ModelType, options ModelOpti
 	case ModelTypeOllama:
 		return NewOllamaModel(options)
 	case ModelTypeDeepSeek:
+		if options.HTTPClient == nil {
+			baseURL := options.BaseURL
+			if baseURL == "" {
+				baseURL = DefaultDeepSeekBaseURL
+			}
+			options.HTTPClient = f.sharedHTTPClient(baseURL)
+		}
 		return NewDeepSeekModel(options)
 	case ModelTypeOpenAI:
 		// 尚未实现
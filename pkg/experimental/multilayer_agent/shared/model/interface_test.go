@@ -2,9 +2,11 @@ package model
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -140,3 +142,35 @@ func TestDefaultModelFactory(t *testing.T) {
 	// 注意：由于Ollama和DeepSeek模型创建依赖外部服务，
 	// 这里不进行实际创建测试，应该在集成测试中进行
 }
+
+// TestDefaultModelFactorySharedHTTPClient 测试多次创建DeepSeek模型复用同一底层transport
+func TestDefaultModelFactorySharedHTTPClient(t *testing.T) {
+	factory := NewModelFactory()
+
+	create := func(token, baseURL string) *DeepSeekModel {
+		m, err := factory.CreateModel(ModelTypeDeepSeek, ModelOptions{APIToken: token, BaseURL: baseURL})
+		require.NoError(t, err)
+		return m.(*DeepSeekModel)
+	}
+
+	first := create("token-a", "")
+	second := create("token-b", "")
+	third := create("token-c", DefaultDeepSeekBaseURL)
+
+	// 同一 BaseURL 复用同一客户端与 transport，鉴权各自独立
+	assert.Same(t, first.httpClient, second.httpClient)
+	assert.Same(t, first.httpClient.Transport, second.httpClient.Transport)
+	assert.Same(t, first.httpClient, third.httpClient, "默认端点与显式默认端点应复用")
+	assert.Equal(t, "token-a", first.apiKey)
+	assert.Equal(t, "token-b", second.apiKey)
+
+	// 不同 BaseURL 使用不同客户端
+	other := create("token-a", "https://example.com/v1")
+	assert.NotSame(t, first.httpClient, other.httpClient)
+
+	// 显式注入的客户端优先
+	injected := &http.Client{}
+	m, err := factory.CreateModel(ModelTypeDeepSeek, ModelOptions{APIToken: "token-d", HTTPClient: injected})
+	require.NoError(t, err)
+	assert.Same(t, injected, m.(*DeepSeekModel).httpClient)
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetStructuredOutputRepair 测试结构化输出解析失败时自动修复
+func TestGetStructuredOutputRepair(t *testing.T) {
+	model := &utilsTestModel{
+		ModelWrapper: &ModelWrapper{Type: ModelTypeOllama, Name: "test-model"},
+		mockResponse: `{'name': '测试', 'age': 20, 'is_valid': true,`,
+		supportsJSON: true,
+	}
+
+	var result testStruct
+	err := GetStructuredOutput(context.Background(), model, "测试提示词", &result)
+	require.NoError(t, err)
+	assert.Equal(t, testStruct{Name: "测试", Age: 20, IsValid: true}, result)
+}
@@ -66,6 +66,10 @@ func NewOllamaModel(options ModelOptions) (Model, error) {
 		tokenLimit = 32768
 	}
 
+	if err := options.Validate(tokenLimit); err != nil {
+		return nil, err
+	}
+
 	// 创建基础ModelWrapper
 	wrapper := &ModelWrapper{
 		BaseModel:        client,
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 生成参数的合法范围
+const (
+	// MinTemperature 温度下限
+	MinTemperature = 0.0
+	// MaxTemperature 温度上限
+	MaxTemperature = 2.0
+)
+
+// ValidationPolicy 定义了生成参数不合法时的处理策略
+type ValidationPolicy string
+
+const (
+	// ValidationReject 参数不合法时返回错误（默认策略）
+	ValidationReject ValidationPolicy = "reject"
+	// ValidationClamp 参数不合法时钳制到合法范围
+	ValidationClamp ValidationPolicy = "clamp"
+)
+
+var (
+	// ErrInvalidTemperature 温度超出合法范围
+	ErrInvalidTemperature = errors.New("温度参数不合法")
+	// ErrInvalidMaxTokens 最大token数超出合法范围
+	ErrInvalidMaxTokens = errors.New("最大token数不合法")
+)
+
+// Validate 按模型的token上限校验默认生成参数
+// 温度需在 [MinTemperature, MaxTemperature] 内，最大token数为0表示不限制，否则需在 (0, tokenLimit] 内；
+// 按 Validation 策略返回错误或将参数钳制到合法范围
+func (o *ModelOptions) Validate(tokenLimit int) error {
+	clamp := o.Validation == ValidationClamp
+
+	if o.DefaultTemperature < MinTemperature || o.DefaultTemperature > MaxTemperature {
+		if !clamp {
+			return fmt.Errorf("%w: %v 不在 [%v, %v] 范围内", ErrInvalidTemperature, o.DefaultTemperature, MinTemperature, MaxTemperature)
+		}
+		o.DefaultTemperature = min(max(o.DefaultTemperature, MinTemperature), MaxTemperature)
+	}
+
+	switch {
+	case o.DefaultMaxTokens < 0:
+		if !clamp {
+			return fmt.Errorf("%w: %d 必须大于0", ErrInvalidMaxTokens, o.DefaultMaxTokens)
+		}
+		o.DefaultMaxTokens = 0
+	case tokenLimit > 0 && o.DefaultMaxTokens > tokenLimit:
+		if !clamp {
+			return fmt.Errorf("%w: %d 超过模型上限 %d", ErrInvalidMaxTokens, o.DefaultMaxTokens, tokenLimit)
+		}
+		o.DefaultMaxTokens = tokenLimit
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestModelOptionsValidate 测试生成参数的校验与钳制
+func TestModelOptionsValidate(t *testing.T) {
+	t.Run("合法参数应通过且不被修改", func(t *testing.T) {
+		for _, opts := range []ModelOptions{
+			{DefaultTemperature: 0, DefaultMaxTokens: 0},
+			{DefaultTemperature: 0.7, DefaultMaxTokens: 1024},
+			{DefaultTemperature: 2, DefaultMaxTokens: 8192},
+		} {
+			got := opts
+			require.NoError(t, got.Validate(8192))
+			assert.Equal(t, opts, got)
+		}
+	})
+
+	t.Run("非法参数默认应被拒绝", func(t *testing.T) {
+		opts := ModelOptions{DefaultTemperature: 2.5}
+		assert.ErrorIs(t, opts.Validate(8192), ErrInvalidTemperature)
+
+		opts = ModelOptions{DefaultTemperature: -0.1}
+		assert.ErrorIs(t, opts.Validate(8192), ErrInvalidTemperature)
+
+		opts = ModelOptions{DefaultMaxTokens: -1}
+		assert.ErrorIs(t, opts.Validate(8192), ErrInvalidMaxTokens)
+
+		opts = ModelOptions{DefaultMaxTokens: 10000}
+		err := opts.Validate(8192)
+		assert.ErrorIs(t, err, ErrInvalidMaxTokens)
+		assert.Contains(t, err.Error(), "8192")
+	})
+
+	t.Run("钳制策略应将参数修正到合法范围", func(t *testing.T) {
+		opts := ModelOptions{DefaultTemperature: 3, DefaultMaxTokens: 10000, Validation: ValidationClamp}
+		require.NoError(t, opts.Validate(8192))
+		assert.Equal(t, MaxTemperature, opts.DefaultTemperature)
+		assert.Equal(t, 8192, opts.DefaultMaxTokens)
+
+		opts = ModelOptions{DefaultTemperature: -1, DefaultMaxTokens: -5, Validation: ValidationClamp}
+		require.NoError(t, opts.Validate(8192))
+		assert.Equal(t, MinTemperature, opts.DefaultTemperature)
+		assert.Equal(t, 0, opts.DefaultMaxTokens)
+	})
+}
+
+// TestNewDeepSeekModelValidatesOptions 测试创建模型时按模型上限校验生成参数
+func TestNewDeepSeekModelValidatesOptions(t *testing.T) {
+	_, err := NewDeepSeekModel(ModelOptions{APIToken: "k", ModelName: "deepseek-llm-67b", DefaultMaxTokens: 8192})
+	assert.ErrorIs(t, err, ErrInvalidMaxTokens)
+
+	m, err := NewDeepSeekModel(ModelOptions{
+		APIToken:           "k",
+		ModelName:          "deepseek-llm-67b",
+		DefaultTemperature: 5,
+		DefaultMaxTokens:   8192,
+		Validation:         ValidationClamp,
+	})
+	require.NoError(t, err)
+	ds := m.(*DeepSeekModel)
+	assert.Equal(t, MaxTemperature, ds.options.DefaultTemperature)
+	assert.Equal(t, 4096, ds.options.DefaultMaxTokens)
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"strings"
 
+	"novelai/pkg/utils/jsonrepair"
+
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/tmc/langchaingo/llms"
 )
@@ -32,6 +34,12 @@ func GetStructuredOutput(ctx context.Context, model Model, prompt string, output
 	// 解析JSON响应到目标结构
 	err = json.Unmarshal([]byte(cleanResponse), outputType)
 	if err != nil {
+		// 尝试一次宽松修复（尾逗号、单引号、未闭合等）后再解析
+		repaired := jsonrepair.Repair(response)
+		if repairErr := json.Unmarshal([]byte(repaired), outputType); repairErr == nil {
+			hlog.Warnf("JSON响应经修复后解析成功, 原始响应: %s", response)
+			return nil
+		}
 		hlog.Errorf("解析JSON响应失败: %v, 原始响应: %s, 清理后: %s", err, response, cleanResponse)
 		return fmt.Errorf("解析JSON响应失败: %w", err)
 	}
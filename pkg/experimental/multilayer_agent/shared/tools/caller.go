@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"unicode/utf8"
 	
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 )
@@ -29,6 +30,8 @@ type ToolResponse struct {
 	Error    string `json:"error,omitempty"`
 	// Success 调用是否成功
 	Success  bool   `json:"success"`
+	// Truncated 结果是否因超出输出大小限制而被截断
+	Truncated bool  `json:"truncated,omitempty"`
 }
 
 // ToolCaller 处理工具调用请求
@@ -36,6 +39,10 @@ type ToolResponse struct {
 type ToolCaller struct {
 	// registry 工具注册表，包含所有可用工具
 	registry *ToolRegistry
+	// MaxInputBytes 工具输入的最大字节数，超限的调用直接拒绝，0 表示不限制
+	MaxInputBytes int
+	// MaxOutputBytes 工具输出的最大字节数，超限的结果被截断，0 表示不限制
+	MaxOutputBytes int
 }
 
 // NewToolCaller 创建新的工具调用处理器
@@ -85,6 +92,15 @@ func (c *ToolCaller) CallTool(ctx context.Context, req ToolRequest) (*ToolRespon
 		}
 	}
 	
+	// 输入超限时在调用前拒绝
+	if c.MaxInputBytes > 0 && len(input) > c.MaxInputBytes {
+		return &ToolResponse{
+			ToolName: req.ToolName,
+			Error:    fmt.Sprintf("工具输入超出大小限制: %d > %d 字节", len(input), c.MaxInputBytes),
+			Success:  false,
+		}, nil
+	}
+	
 	// 创建适配器并调用工具
 	adapter := NewLangChainAdapter(tool)
 	result, err := adapter.Call(ctx, input)
@@ -97,14 +113,35 @@ func (c *ToolCaller) CallTool(ctx context.Context, req ToolRequest) (*ToolRespon
 		}, nil
 	}
 	
+	// 输出超限时截断并标记
+	truncated := false
+	if c.MaxOutputBytes > 0 && len(result) > c.MaxOutputBytes {
+		hlog.CtxWarnf(ctx, "工具 %s 输出超出大小限制，已截断: %d > %d 字节", req.ToolName, len(result), c.MaxOutputBytes)
+		result = truncateUTF8(result, c.MaxOutputBytes)
+		truncated = true
+	}
+	
 	// 返回成功响应
 	return &ToolResponse{
-		ToolName: req.ToolName,
-		Result:   result,
-		Success:  true,
+		ToolName:  req.ToolName,
+		Result:    result,
+		Success:   true,
+		Truncated: truncated,
 	}, nil
 }
 
+// truncateUTF8 将字符串截断到不超过 maxBytes 字节，且不切断多字节字符
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
 // CallToolFromJSON 从JSON字符串执行工具调用
 // 参数:
 //   - ctx: 上下文，包含调用相关信息
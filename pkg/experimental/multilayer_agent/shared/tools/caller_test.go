@@ -251,3 +251,58 @@ func TestCallToolFromJSON(t *testing.T) {
 		assert.Contains(t, resp.Error, "工具不存在")
 	})
 }
+
+// TestCallToolSizeLimits 测试工具输入输出大小限制
+func TestCallToolSizeLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("超大输入应在调用前被拒绝", func(t *testing.T) {
+		registry := NewToolRegistry()
+		err := registry.RegisterTool(&mockTool{name: "echo", description: "回显", callResult: "ok"})
+		assert.NoError(t, err)
+		caller := NewToolCaller(registry)
+		caller.MaxInputBytes = 8
+
+		resp, err := caller.CallTool(ctx, ToolRequest{
+			ToolName: "echo",
+			Input:    json.RawMessage(`"这是一段很长的输入"`),
+		})
+
+		assert.NoError(t, err)
+		assert.False(t, resp.Success)
+		assert.Contains(t, resp.Error, "超出大小限制")
+		assert.Empty(t, resp.Result)
+	})
+
+	t.Run("超大输出应被截断并标记", func(t *testing.T) {
+		registry := NewToolRegistry()
+		err := registry.RegisterTool(&mockTool{name: "big", description: "大输出", callResult: "中文输出结果"})
+		assert.NoError(t, err)
+		caller := NewToolCaller(registry)
+		caller.MaxOutputBytes = 7
+
+		resp, err := caller.CallTool(ctx, ToolRequest{ToolName: "big"})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Success)
+		assert.True(t, resp.Truncated)
+		// 截断不切断多字节字符
+		assert.Equal(t, "中文", resp.Result)
+	})
+
+	t.Run("未超限时不标记截断", func(t *testing.T) {
+		registry := NewToolRegistry()
+		err := registry.RegisterTool(&mockTool{name: "small", description: "小输出", callResult: "ok"})
+		assert.NoError(t, err)
+		caller := NewToolCaller(registry)
+		caller.MaxInputBytes = 100
+		caller.MaxOutputBytes = 100
+
+		resp, err := caller.CallTool(ctx, ToolRequest{ToolName: "small", Input: json.RawMessage(`"hi"`)})
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Success)
+		assert.False(t, resp.Truncated)
+		assert.Equal(t, "ok", resp.Result)
+	})
+}
@@ -50,6 +50,9 @@ type ExampleTool struct {
 	
 	// 自定义配置选项
 	config *ExampleToolConfig
+	
+	// executor 工具执行函数，为空时使用 execute，便于测试注入
+	executor func(ctx context.Context, params *ExampleToolParams) (string, error)
 }
 
 // ExampleToolConfig 定义了工具的配置选项
@@ -138,8 +141,14 @@ func (t *ExampleTool) Call(ctx context.Context, input string) (string, error) {
 		return "", fmt.Errorf("解析输入失败: %w", err)
 	}
 	
-	// 执行工具逻辑
-	result, err := t.execute(ctx, params)
+	// 执行工具逻辑，可重试错误按 MaxRetries 退避重试
+	executor := t.executor
+	if executor == nil {
+		executor = t.execute
+	}
+	result, err := retryableCall(ctx, t.config.MaxRetries, func(ctx context.Context) (string, error) {
+		return executor(ctx, params)
+	})
 	if err != nil {
 		if t.config.Verbose {
 			hlog.CtxErrorf(ctx, "示例工具执行失败: %v", err)
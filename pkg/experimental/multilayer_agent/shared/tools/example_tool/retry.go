@@ -0,0 +1,70 @@
+// Package example_tool 提供了一个langchaingo Tool接口的完整示例实现
+package example_tool
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// retryBaseDelay 重试退避的基础等待时间，第 n 次重试等待 retryBaseDelay * 2^(n-1)
+var retryBaseDelay = 100 * time.Millisecond
+
+// RetryableError 可重试错误
+// 工具执行函数返回该类型的错误时，retryableCall 会按配置退避重试
+type RetryableError struct {
+	Err error
+}
+
+// Error 实现error接口
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap 返回被包装的原始错误
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable 将错误标记为可重试
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+// IsRetryable 判断错误是否可重试
+func IsRetryable(err error) bool {
+	var retryableErr *RetryableError
+	return errors.As(err, &retryableErr)
+}
+
+// retryableCall 执行函数并在返回可重试错误时按指数退避重试
+// 参数:
+//   - ctx: 上下文，取消时停止重试
+//   - maxRetries: 最大重试次数（不含首次调用），小于0按0处理
+//   - fn: 实际执行函数
+//
+// 返回:
+//   - string: 执行结果
+//   - error: 不可重试错误、重试耗尽后的最后一次错误或上下文错误
+func retryableCall(ctx context.Context, maxRetries int, fn func(ctx context.Context) (string, error)) (string, error) {
+	delay := retryBaseDelay
+	for attempt := 0; ; attempt++ {
+		result, err := fn(ctx)
+		if err == nil || !IsRetryable(err) || attempt >= maxRetries {
+			return result, err
+		}
+
+		hlog.CtxWarnf(ctx, "执行失败，%v 后进行第 %d 次重试: %v", delay, attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
@@ -0,0 +1,77 @@
+// Package example_tool 提供了一个langchaingo Tool接口的完整示例实现
+package example_tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestRetryableCall 测试前两次失败后重试成功
+func TestRetryableCall(t *testing.T) {
+	originalDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = originalDelay }()
+
+	tool := NewExampleTool(nil).(*ExampleTool)
+	calls := 0
+	tool.executor = func(ctx context.Context, params *ExampleToolParams) (string, error) {
+		calls++
+		if calls <= 2 {
+			return "", Retryable(errors.New("临时故障"))
+		}
+		return tool.execute(ctx, params)
+	}
+
+	result, err := tool.Call(context.Background(), "")
+	if err != nil {
+		t.Fatalf("重试后应成功，实际错误: %v", err)
+	}
+	if result == "" {
+		t.Error("工具返回结果不应为空")
+	}
+	if calls != 3 {
+		t.Errorf("期望共调用3次，实际为%d次", calls)
+	}
+}
+
+// TestRetryableCallExhausted 测试重试耗尽与不可重试错误
+func TestRetryableCallExhausted(t *testing.T) {
+	originalDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = originalDelay }()
+
+	// 重试次数耗尽后返回最后一次错误
+	calls := 0
+	_, err := retryableCall(context.Background(), 2, func(ctx context.Context) (string, error) {
+		calls++
+		return "", Retryable(errors.New("一直失败"))
+	})
+	if !IsRetryable(err) {
+		t.Errorf("期望返回可重试错误，实际为%v", err)
+	}
+	if calls != 3 {
+		t.Errorf("期望首次调用加2次重试共3次，实际为%d次", calls)
+	}
+
+	// 不可重试错误立即返回
+	calls = 0
+	_, err = retryableCall(context.Background(), 2, func(ctx context.Context) (string, error) {
+		calls++
+		return "", errors.New("参数错误")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("不可重试错误应立即返回，调用次数为%d，错误为%v", calls, err)
+	}
+
+	// 上下文取消时停止重试
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = retryableCall(ctx, 5, func(ctx context.Context) (string, error) {
+		return "", Retryable(errors.New("临时故障"))
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("期望上下文取消错误，实际为%v", err)
+	}
+}
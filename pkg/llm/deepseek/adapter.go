@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"text/template"
 )
 
 // Adapter 提供了简化的DeepSeek API接口
@@ -153,6 +154,43 @@ func (a *Adapter) ChatWithMessages(ctx context.Context, model string, messages [
 	return text, nil
 }
 
+// ChatWithTemplate 使用 text/template 渲染用户提示后进行聊天（非流式）
+// data 为模板数据，模板引用不存在的字段时返回错误
+func (a *Adapter) ChatWithTemplate(ctx context.Context, model, tmpl string, data interface{}, maxTokens int) (string, error) {
+	return a.ChatWithSystemTemplate(ctx, model, "", tmpl, data, maxTokens)
+}
+
+// ChatWithSystemTemplate 使用系统提示和模板渲染的用户提示进行聊天（非流式）
+// systemPrompt 为空时只发送用户消息
+func (a *Adapter) ChatWithSystemTemplate(ctx context.Context, model, systemPrompt, tmpl string, data interface{}, maxTokens int) (string, error) {
+	userPrompt, err := renderTemplate(tmpl, data)
+	if err != nil {
+		return "", err
+	}
+
+	msgBuilder := NewMessageBuilder()
+	if systemPrompt != "" {
+		msgBuilder.AddSystemMessage(systemPrompt)
+	}
+	msgBuilder.AddUserMessage(userPrompt)
+
+	return a.ChatWithMessages(ctx, model, msgBuilder.Messages(), maxTokens)
+}
+
+// renderTemplate 渲染 text/template 模板
+func renderTemplate(tmpl string, data interface{}) (string, error) {
+	t, err := template.New("prompt").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("解析模板失败: %w", err)
+	}
+
+	var builder strings.Builder
+	if err := t.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("渲染模板失败: %w", err)
+	}
+	return builder.String(), nil
+}
+
 // ChatWithSystemStream 使用系统提示进行流式聊天
 func (a *Adapter) ChatWithSystemStream(ctx context.Context, model, systemPrompt, userPrompt string, maxTokens int) (string, error) {
 	// 构建消息
@@ -175,6 +213,29 @@ func (a *Adapter) ChatWithSystemStream(ctx context.Context, model, systemPrompt,
 	return a.readChatCompletionStream(stream)
 }
 
+// ChatWithSystemStreamCallback 使用系统提示进行流式聊天，每收到一个增量就回调 onChunk
+// onChunk 返回错误时立即中止流，并返回已累积的内容和该错误
+func (a *Adapter) ChatWithSystemStreamCallback(ctx context.Context, model, systemPrompt, userPrompt string, maxTokens int, onChunk func(delta string) error) (string, error) {
+	// 构建消息
+	msgBuilder := NewMessageBuilder()
+	msgBuilder.AddSystemMessage(systemPrompt)
+	msgBuilder.AddUserMessage(userPrompt)
+
+	// 创建请求
+	req := msgBuilder.CreateChatRequest(model, maxTokens)
+	req.Stream = true
+
+	// 发送流式请求
+	stream, err := a.client.ChatCompletionStream(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	// 逐块读取并回调
+	return a.readChatCompletionStreamWithCallback(stream, onChunk)
+}
+
 // ChatWithMessagesStream 使用消息列表进行流式聊天
 func (a *Adapter) ChatWithMessagesStream(ctx context.Context, model string, messages []Message, maxTokens int) (string, error) {
 	// 创建请求
@@ -224,6 +285,12 @@ func (a *Adapter) readCompletionStream(stream *StreamReader) (string, error) {
 
 // readChatCompletionStream 从流式响应中读取聊天完成内容
 func (a *Adapter) readChatCompletionStream(stream *StreamReader) (string, error) {
+	return a.readChatCompletionStreamWithCallback(stream, nil)
+}
+
+// readChatCompletionStreamWithCallback 从流式响应中读取聊天完成内容，每个非空增量都会回调 onChunk
+// onChunk 为 nil 时只累积内容
+func (a *Adapter) readChatCompletionStreamWithCallback(stream *StreamReader, onChunk func(delta string) error) (string, error) {
 	var fullText strings.Builder
 
 	for {
@@ -241,6 +308,11 @@ func (a *Adapter) readChatCompletionStream(stream *StreamReader) (string, error)
 				if delta, ok := choice["delta"].(map[string]interface{}); ok {
 					if content, ok := delta["content"].(string); ok {
 						fullText.WriteString(content)
+						if onChunk != nil && content != "" {
+							if err := onChunk(content); err != nil {
+								return fullText.String(), fmt.Errorf("流式回调中止: %w", err)
+							}
+						}
 					}
 				}
 			}
@@ -5,6 +5,8 @@ import "novelai/pkg/constants"
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -282,3 +284,203 @@ func TestAdapter_ErrorHandling(t *testing.T) {
 		t.Errorf("期望返回错误，但没有获得错误")
 	}
 }
+
+// TestAdapter_EndUser 测试终端用户标识透传到请求体
+func TestAdapter_EndUser(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	adapter, err := NewAdapterWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+
+	// 设置终端用户后请求体带 user 字段
+	ctx := WithEndUser(context.Background(), "user-42")
+	if _, err := adapter.ChatWithSystem(ctx, constants.DeepSeekChat, "系统", "你好", 10); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if body["user"] != "user-42" {
+		t.Errorf("期望请求体user为'user-42'，实际为%v", body["user"])
+	}
+
+	// 未设置时请求体不出现 user 字段
+	if _, err := adapter.ChatWithSystem(context.Background(), constants.DeepSeekChat, "系统", "你好", 10); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if _, exists := body["user"]; exists {
+		t.Errorf("未设置终端用户时请求体不应包含user字段，实际为%v", body["user"])
+	}
+}
+
+// TestAdapter_Seed 测试随机种子透传到请求体
+func TestAdapter_Seed(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"},"text":"ok"}]}`))
+	}))
+	defer server.Close()
+
+	adapter, err := NewAdapterWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+
+	// 通过上下文设置 seed 后请求体带 seed 字段，0 也应透传
+	ctx := WithSeed(context.Background(), 0)
+	if _, err := adapter.ChatWithSystem(ctx, constants.DeepSeekChat, "系统", "你好", 10); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if seed, exists := body["seed"]; !exists || seed != float64(0) {
+		t.Errorf("期望请求体seed为0，实际为%v", body["seed"])
+	}
+
+	// 请求显式设置的 seed 优先于上下文
+	seed := 42
+	if _, err := adapter.Client().Completion(WithSeed(context.Background(), 7), &CompletionRequest{
+		Model: constants.DeepSeekChat, Prompt: "你好", Seed: &seed,
+	}); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if body["seed"] != float64(42) {
+		t.Errorf("期望请求体seed为42，实际为%v", body["seed"])
+	}
+
+	// 未设置时请求体不出现 seed 字段
+	if _, err := adapter.ChatWithSystem(context.Background(), constants.DeepSeekChat, "系统", "你好", 10); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if _, exists := body["seed"]; exists {
+		t.Errorf("未设置seed时请求体不应包含seed字段，实际为%v", body["seed"])
+	}
+}
+
+// TestAdapter_ChatWithTemplate 测试模板渲染后作为用户消息发送
+func TestAdapter_ChatWithTemplate(t *testing.T) {
+	var req ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req = ChatRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	}))
+	defer server.Close()
+
+	adapter, err := NewAdapterWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+
+	data := map[string]interface{}{"Theme": "赛博朋克", "Count": 3}
+	ctx := context.Background()
+
+	// 仅用户消息
+	if _, err := adapter.ChatWithTemplate(ctx, constants.DeepSeekChat, "生成{{.Count}}个{{.Theme}}世界观", data, 100); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if len(req.Messages) != 1 || req.Messages[0].Role != constants.RoleUser {
+		t.Fatalf("期望只有一条用户消息，实际为%+v", req.Messages)
+	}
+	if req.Messages[0].Content != "生成3个赛博朋克世界观" {
+		t.Errorf("模板渲染结果错误: %s", req.Messages[0].Content)
+	}
+
+	// 带系统提示
+	if _, err := adapter.ChatWithSystemTemplate(ctx, constants.DeepSeekChat, "你是小说助手", "主题: {{.Theme}}", data, 100); err != nil {
+		t.Fatalf("发送请求失败: %v", err)
+	}
+	if len(req.Messages) != 2 || req.Messages[0].Content != "你是小说助手" || req.Messages[1].Content != "主题: 赛博朋克" {
+		t.Errorf("请求消息错误: %+v", req.Messages)
+	}
+
+	// 模板字段缺失时返回错误且不发送请求
+	if _, err := adapter.ChatWithTemplate(ctx, constants.DeepSeekChat, "{{.Missing}}", data, 100); err == nil {
+		t.Errorf("期望模板缺失字段时返回错误")
+	}
+}
+
+// chatStreamSSE 模拟的聊天流式响应
+const chatStreamSSE = `data: {"id":"chatcmpl-123","choices":[{"delta":{"role":"assistant"}}]}
+
+data: {"id":"chatcmpl-123","choices":[{"delta":{"content":"这是"}}]}
+
+data: {"id":"chatcmpl-123","choices":[{"delta":{"content":"一个"}}]}
+
+data: {"id":"chatcmpl-123","choices":[{"delta":{"content":"流式"}}]}
+
+data: {"id":"chatcmpl-123","choices":[{"delta":{"content":"测试"}}]}
+
+data: [DONE]
+`
+
+// TestAdapter_ChatWithSystemStreamCallback 测试流式聊天逐块回调
+func TestAdapter_ChatWithSystemStreamCallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte(chatStreamSSE))
+	}))
+	defer server.Close()
+
+	adapter, err := NewAdapterWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+	ctx := context.Background()
+
+	// 回调按顺序收到各分片
+	var chunks []string
+	result, err := adapter.ChatWithSystemStreamCallback(ctx, constants.DeepSeekChat, "你是小说助手", "你好", 100, func(delta string) error {
+		chunks = append(chunks, delta)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("流式聊天失败: %v", err)
+	}
+	expected := []string{"这是", "一个", "流式", "测试"}
+	if len(chunks) != len(expected) {
+		t.Fatalf("期望收到%d个分片，实际为%v", len(expected), chunks)
+	}
+	for i, chunk := range expected {
+		if chunks[i] != chunk {
+			t.Errorf("第%d个分片期望为'%s'，实际为'%s'", i+1, chunk, chunks[i])
+		}
+	}
+	if result != "这是一个流式测试" {
+		t.Errorf("期望完整结果为'这是一个流式测试'，实际为'%s'", result)
+	}
+
+	// 回调返回错误时提前停止
+	errStop := errors.New("用户取消")
+	chunks = nil
+	result, err = adapter.ChatWithSystemStreamCallback(ctx, constants.DeepSeekChat, "你是小说助手", "你好", 100, func(delta string) error {
+		chunks = append(chunks, delta)
+		if len(chunks) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("期望返回回调错误，实际为%v", err)
+	}
+	if len(chunks) != 2 {
+		t.Errorf("期望回调2次后停止，实际回调%d次", len(chunks))
+	}
+	if result != "这是一个" {
+		t.Errorf("期望返回已累积内容'这是一个'，实际为'%s'", result)
+	}
+}
@@ -0,0 +1,65 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"fmt"
+)
+
+// APIError 服务端返回的结构化错误
+// 流式请求中途出错时，服务端会以 `data: {"error":{...}}` 帧代替正常数据返回
+type APIError struct {
+	// Message 是错误描述
+	Message string
+
+	// Type 是错误类型，如 server_error、invalid_request_error
+	Type string
+
+	// Code 是错误码，服务端返回数字时转为字符串
+	Code string
+
+	// Param 是与错误相关的请求参数
+	Param string
+}
+
+// Error 实现error接口
+func (e *APIError) Error() string {
+	msg := "API错误: " + e.Message
+	if e.Type != "" {
+		msg += fmt.Sprintf(" (类型: %s)", e.Type)
+	}
+	if e.Code != "" {
+		msg += fmt.Sprintf(" (错误码: %s)", e.Code)
+	}
+	return msg
+}
+
+// parseAPIError 从响应中提取 error 字段，不含 error 字段时返回 nil
+// error 字段可能是对象，也可能只是一个错误描述字符串
+func parseAPIError(response map[string]interface{}) *APIError {
+	raw, ok := response["error"]
+	if !ok || raw == nil {
+		return nil
+	}
+
+	switch v := raw.(type) {
+	case string:
+		return &APIError{Message: v}
+	case map[string]interface{}:
+		apiErr := &APIError{}
+		if msg, ok := v["message"].(string); ok {
+			apiErr.Message = msg
+		}
+		if typ, ok := v["type"].(string); ok {
+			apiErr.Type = typ
+		}
+		if param, ok := v["param"].(string); ok {
+			apiErr.Param = param
+		}
+		if code, ok := v["code"]; ok && code != nil {
+			apiErr.Code = fmt.Sprint(code)
+		}
+		return apiErr
+	default:
+		return &APIError{Message: fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,138 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBreakerThreshold 是启用熔断时建议的连续失败次数，默认配置不启用熔断
+	DefaultBreakerThreshold = 5
+
+	// DefaultBreakerCooldown 是熔断打开后的默认冷却时间
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，请求未发送直接失败
+var ErrCircuitOpen = errors.New("DeepSeek 服务暂不可用，已熔断")
+
+// BreakerState 熔断器状态
+type BreakerState string
+
+// 熔断器状态常量
+const (
+	BreakerClosed   BreakerState = "closed"    // 正常放行
+	BreakerOpen     BreakerState = "open"      // 快速失败
+	BreakerHalfOpen BreakerState = "half_open" // 冷却结束，放行一个试探请求
+)
+
+// circuitBreaker 按连续失败次数熔断的熔断器
+// 连续失败达到阈值后打开，冷却期内所有请求快速失败；冷却结束后进入半开状态只放行一个试探请求，
+// 试探成功则关闭，失败则重新打开。为 nil 时表示未启用，所有方法均放行
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	state    BreakerState
+	failures int       // 连续失败次数
+	openedAt time.Time // 最近一次打开的时间
+	probing  bool      // 半开状态下是否已有试探请求在进行
+}
+
+// newCircuitBreaker 创建熔断器，threshold 小于等于0时返回 nil 表示不启用
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		return nil
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     BreakerClosed,
+	}
+}
+
+// allow 判断请求能否发送，熔断打开时返回 ErrCircuitOpen
+// 返回 nil 的调用方必须在请求结束后调用 onSuccess、onFailure 或 onCancel 之一
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+	}
+	switch b.state {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// onSuccess 记录一次成功，重置连续失败次数并关闭熔断
+func (b *circuitBreaker) onSuccess() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.state = BreakerClosed
+}
+
+// onFailure 记录一次失败，半开试探失败或连续失败达到阈值时打开熔断
+func (b *circuitBreaker) onFailure() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+	b.probing = false
+}
+
+// onCancel 请求结果不计入成败（如调用方取消或被限流），半开状态下允许下一个请求继续试探
+func (b *circuitBreaker) onCancel() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// currentState 返回熔断器当前状态，未启用时始终为 BreakerClosed
+func (b *circuitBreaker) currentState() BreakerState {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,149 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"novelai/pkg/constants"
+)
+
+// TestClient_CircuitBreaker 测试连续失败触发熔断后快速失败，冷却后试探恢复
+func TestClient_CircuitBreaker(t *testing.T) {
+	var hits int32
+	var healthy atomic.Bool
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+	defer server.Close()
+
+	client, err := NewClientWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL).WithCircuitBreaker(3, time.Minute))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	chat := func() error {
+		_, err := client.ChatCompletion(ctx, &ChatRequest{
+			Model:    constants.DeepSeekChat,
+			Messages: []Message{{Role: constants.RoleUser, Content: "你好"}},
+		})
+		return err
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := chat(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("第%d次请求期望返回服务端错误，实际为%v", i, err)
+		}
+	}
+	if client.BreakerState() != BreakerOpen {
+		t.Fatalf("期望连续失败3次后熔断打开，实际为%s", client.BreakerState())
+	}
+
+	// 冷却期内快速失败，不再发出请求
+	healthy.Store(true)
+	if err := chat(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("期望熔断期间返回ErrCircuitOpen，实际为%v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("期望熔断期间不发请求，实际请求%d次", got)
+	}
+
+	// 冷却结束后半开试探成功，熔断关闭
+	now = now.Add(time.Minute)
+	if client.BreakerState() != BreakerHalfOpen {
+		t.Errorf("期望冷却结束后进入半开状态，实际为%s", client.BreakerState())
+	}
+	if err := chat(); err != nil {
+		t.Fatalf("期望冷却后请求恢复，实际为%v", err)
+	}
+	if client.BreakerState() != BreakerClosed {
+		t.Errorf("期望试探成功后熔断关闭，实际为%s", client.BreakerState())
+	}
+}
+
+// TestCircuitBreaker_HalfOpenFailure 测试半开试探失败后重新打开，且同一时刻只放行一个试探
+func TestCircuitBreaker_HalfOpenFailure(t *testing.T) {
+	breaker := newCircuitBreaker(2, time.Second)
+	now := time.Now()
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := breaker.allow(); err != nil {
+			t.Fatalf("熔断前应放行，实际为%v", err)
+		}
+		breaker.onFailure()
+	}
+	if err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("期望熔断打开，实际为%v", err)
+	}
+
+	now = now.Add(time.Second)
+	if err := breaker.allow(); err != nil {
+		t.Fatalf("冷却后应放行试探请求，实际为%v", err)
+	}
+	if err := breaker.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("试探进行中应拒绝其他请求，实际为%v", err)
+	}
+	breaker.onFailure()
+	if breaker.currentState() != BreakerOpen {
+		t.Errorf("期望试探失败后重新打开，实际为%s", breaker.currentState())
+	}
+
+	if newCircuitBreaker(0, time.Second) != nil {
+		t.Error("阈值为0时不应启用熔断")
+	}
+}
+
+// TestClient_CircuitBreakerIgnoresRateLimit 测试 429 交给密钥轮换处理，不计入熔断失败
+func TestClient_CircuitBreakerIgnoresRateLimit(t *testing.T) {
+	var hits int32
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	defer server.Close()
+
+	client, err := NewClientWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL).WithCircuitBreaker(2, time.Minute))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	for i := 0; i < 4; i++ {
+		_, err := client.ChatCompletion(context.Background(), &ChatRequest{
+			Model:    constants.DeepSeekChat,
+			Messages: []Message{{Role: constants.RoleUser, Content: "你好"}},
+		})
+		if err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("第%d次请求期望返回限流错误，实际为%v", i, err)
+		}
+	}
+	if client.BreakerState() != BreakerClosed {
+		t.Errorf("期望限流不触发熔断，实际为%s", client.BreakerState())
+	}
+	if got := atomic.LoadInt32(&hits); got != 4 {
+		t.Errorf("期望每次请求都发出，实际请求%d次", got)
+	}
+}
+
+// TestDefaultConfig_BreakerDisabled 测试默认配置不启用熔断
+func TestDefaultConfig_BreakerDisabled(t *testing.T) {
+	client, err := NewClientWithConfig(DefaultConfig("test-api-key"))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	if client.breaker != nil {
+		t.Error("默认配置不应启用熔断")
+	}
+}
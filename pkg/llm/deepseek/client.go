@@ -6,21 +6,33 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 	
 	"github.com/openai/openai-go"
 )
 
 // Client 是DeepSeek API的客户端
+// 创建后除加锁保护的熔断器与密钥轮换状态外不再修改任何内部状态，可被多个 goroutine 安全地并发复用；
+// 注意各方法会补全传入的请求体，请求体本身不应在 goroutine 间共享
 type Client struct {
 	// config 是客户端配置
 	config *Config
 	
 	// openaiClient 是OpenAI官方SDK的客户端实例
 	openaiClient *openai.Client
+	
+	// breaker 是熔断器，为 nil 时不启用熔断
+	breaker *circuitBreaker
+
+	// keys 是请求使用的API密钥选择器
+	keys *keyRing
 }
 
 // NewClient 创建一个新的DeepSeek客户端
@@ -34,11 +46,55 @@ func NewClient(apiKey string) (*Client, error) {
 	return &Client{
 		config:       config,
 		openaiClient: openaiClient,
+		breaker:      newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+		keys:         newKeyRing(config.APIKeys, config.APIKey, config.KeyCooldown),
 	}, nil
 }
 
+// endUserKey 终端用户标识在上下文中的键
+type endUserKey struct{}
+
+// WithEndUser 在上下文中附加终端用户标识
+// 请求未显式设置 User 字段时，客户端会从上下文中读取并透传给 API
+func WithEndUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, endUserKey{}, user)
+}
+
+// EndUserFromContext 从上下文中读取终端用户标识，不存在时返回空字符串
+func EndUserFromContext(ctx context.Context) string {
+	user, _ := ctx.Value(endUserKey{}).(string)
+	return user
+}
+
+// seedKey 上下文中随机种子的键
+type seedKey struct{}
+
+// WithSeed 在上下文中附加随机种子
+// 请求未显式设置 Seed 字段时，客户端会从上下文中读取并透传给 API，
+// 便于 Adapter 的高层方法在不改变签名的情况下可选地带上 seed
+func WithSeed(ctx context.Context, seed int) context.Context {
+	return context.WithValue(ctx, seedKey{}, seed)
+}
+
+// SeedFromContext 从上下文中读取随机种子，不存在时返回nil
+func SeedFromContext(ctx context.Context) *int {
+	seed, ok := ctx.Value(seedKey{}).(int)
+	if !ok {
+		return nil
+	}
+	return &seed
+}
+
 // NewClientWithConfig 使用指定配置创建客户端
+// 客户端持有配置的副本，创建后调用方再修改 config 不会影响已创建的客户端
 func NewClientWithConfig(config *Config) (*Client, error) {
+	configCopy := *config
+	configCopy.APIKeys = append([]string(nil), config.APIKeys...)
+	config = &configCopy
+	if len(config.APIKeys) > 0 {
+		// SDK 客户端只支持单个密钥，使用第一个
+		config.APIKey = config.APIKeys[0]
+	}
 	openaiClient, err := config.CreateClient()
 	if err != nil {
 		return nil, fmt.Errorf("创建客户端失败: %w", err)
@@ -47,13 +103,15 @@ func NewClientWithConfig(config *Config) (*Client, error) {
 	return &Client{
 		config:       config,
 		openaiClient: openaiClient,
+		breaker:      newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown),
+		keys:         newKeyRing(config.APIKeys, config.APIKey, config.KeyCooldown),
 	}, nil
 }
 
 // Models 获取可用模型列表
 func (c *Client) Models(ctx context.Context) ([]string, error) {
 	// 使用直接的 API 调用获取模型列表
-	url := fmt.Sprintf("%s/models", c.config.BaseURL)
+	url := c.config.endpoint(c.config.ModelsPath, DefaultModelsPath)
 	response, err := c.sendJSONRequest(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("获取模型列表失败: %w", err)
@@ -78,13 +136,23 @@ func (c *Client) Models(ctx context.Context) ([]string, error) {
 func (c *Client) Completion(ctx context.Context, request *CompletionRequest) (map[string]interface{}, error) {
 	// 确保不是流式请求
 	request.Stream = false
+	if request.User == "" {
+		request.User = EndUserFromContext(ctx)
+	}
+	if request.Seed == nil {
+		request.Seed = SeedFromContext(ctx)
+	}
+	if err := precheckCompletionRequest(request); err != nil {
+		return nil, err
+	}
 	
-	// 拼接 beta 路径，保证 completions 只用 beta
-	url := fmt.Sprintf("%s/beta/completions", strings.TrimRight(c.config.BaseURL, "/"))
+	// 默认走 beta 路径，completions 只在 beta 下提供
+	url := c.config.endpoint(c.config.CompletionsPath, DefaultCompletionsPath)
 	response, err := c.sendJSONRequest(ctx, http.MethodPost, url, request)
 	if err != nil {
 		return nil, fmt.Errorf("文本生成请求失败: %w", err)
 	}
+	c.recordUsage(ctx, request.Model, response)
 	
 	return response, nil
 }
@@ -93,13 +161,23 @@ func (c *Client) Completion(ctx context.Context, request *CompletionRequest) (ma
 func (c *Client) ChatCompletion(ctx context.Context, request *ChatRequest) (map[string]interface{}, error) {
 	// 确保不是流式请求
 	request.Stream = false
+	if request.User == "" {
+		request.User = EndUserFromContext(ctx)
+	}
+	if request.Seed == nil {
+		request.Seed = SeedFromContext(ctx)
+	}
+	if err := precheckChatRequest(request); err != nil {
+		return nil, err
+	}
 	
-	// 拼接 v1 路径，chat 只用 v1
-	url := fmt.Sprintf("%s/v1/chat/completions", strings.TrimRight(c.config.BaseURL, "/"))
+	// 默认走 v1 路径
+	url := c.config.endpoint(c.config.ChatPath, DefaultChatPath)
 	response, err := c.sendJSONRequest(ctx, http.MethodPost, url, request)
 	if err != nil {
 		return nil, fmt.Errorf("聊天请求失败: %w", err)
 	}
+	c.recordUsage(ctx, request.Model, response)
 	
 	return response, nil
 }
@@ -108,30 +186,68 @@ func (c *Client) ChatCompletion(ctx context.Context, request *ChatRequest) (map[
 func (c *Client) CompletionStream(ctx context.Context, request *CompletionRequest) (*StreamReader, error) {
 	// 确保是流式请求
 	request.Stream = true
+	if request.User == "" {
+		request.User = EndUserFromContext(ctx)
+	}
+	if request.Seed == nil {
+		request.Seed = SeedFromContext(ctx)
+	}
+	if err := precheckCompletionRequest(request); err != nil {
+		return nil, err
+	}
 	
-	// 拼接 beta 路径，保证 completions stream 只用 beta
-	url := fmt.Sprintf("%s/beta/completions", strings.TrimRight(c.config.BaseURL, "/"))
+	// 默认走 beta 路径，completions stream 只在 beta 下提供
+	url := c.config.endpoint(c.config.CompletionsPath, DefaultCompletionsPath)
 	resp, err := c.sendStreamRequest(ctx, url, request)
 	if err != nil {
 		return nil, fmt.Errorf("流式文本生成请求失败: %w", err)
 	}
 	
-	return NewStreamReader(resp.Body), nil
+	return NewStreamReaderWithContext(ctx, resp.Body).SetIdleTimeout(c.config.StreamIdleTimeout), nil
 }
 
 // ChatCompletionStream 发送流式聊天完成请求
 func (c *Client) ChatCompletionStream(ctx context.Context, request *ChatRequest) (*StreamReader, error) {
 	// 确保是流式请求
 	request.Stream = true
+	if request.User == "" {
+		request.User = EndUserFromContext(ctx)
+	}
+	if request.Seed == nil {
+		request.Seed = SeedFromContext(ctx)
+	}
+	if err := precheckChatRequest(request); err != nil {
+		return nil, err
+	}
 	
-	// 拼接 v1 路径，chat stream 只用 v1
-	url := fmt.Sprintf("%s/v1/chat/completions", strings.TrimRight(c.config.BaseURL, "/"))
+	// 默认走 v1 路径
+	url := c.config.endpoint(c.config.ChatPath, DefaultChatPath)
 	resp, err := c.sendStreamRequest(ctx, url, request)
 	if err != nil {
 		return nil, fmt.Errorf("流式聊天请求失败: %w", err)
 	}
 	
-	return NewStreamReader(resp.Body), nil
+	return NewStreamReaderWithContext(ctx, resp.Body).SetIdleTimeout(c.config.StreamIdleTimeout), nil
+}
+
+// BreakerState 返回熔断器当前状态，未启用熔断时始终为 BreakerClosed
+func (c *Client) BreakerState() BreakerState {
+	return c.breaker.currentState()
+}
+
+// recordResult 按请求结果更新熔断器：网络错误与 5xx 计为失败；
+// 429 由密钥轮换处理，与调用方取消一样不计入成败
+func (c *Client) recordResult(ctx context.Context, resp *http.Response, err error) {
+	switch {
+	case ctx.Err() != nil:
+		c.breaker.onCancel()
+	case err == nil && resp.StatusCode == http.StatusTooManyRequests:
+		c.breaker.onCancel()
+	case err != nil || resp.StatusCode >= 500:
+		c.breaker.onFailure()
+	default:
+		c.breaker.onSuccess()
+	}
 }
 
 // sendJSONRequest 发送JSON请求并解析响应
@@ -150,15 +266,21 @@ func (c *Client) sendJSONRequest(ctx context.Context, method, url string, body i
 	
 	// 设置请求头
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
+	apiKey := c.keys.pick()
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
 	req.Header.Set("User-Agent", c.config.UserAgent)
 	
 	if c.config.OrgID != "" {
 		req.Header.Set("OpenAI-Organization", c.config.OrgID)
 	}
 	
-	// 发送请求
+	// 发送请求，熔断打开时直接失败
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
 	resp, err := c.config.HTTPClient.Do(req)
+	c.recordResult(ctx, resp, err)
+	c.recordKeyResult(apiKey, resp)
 	if err != nil {
 		return nil, fmt.Errorf("发送HTTP请求失败: %w", err)
 	}
@@ -204,7 +326,8 @@ func (c *Client) sendStreamRequest(ctx context.Context, url string, body interfa
 	
 	// 设置请求头
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.APIKey))
+	apiKey := c.keys.pick()
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
 	req.Header.Set("User-Agent", c.config.UserAgent)
 	req.Header.Set("Accept", "text/event-stream")
 	
@@ -212,8 +335,13 @@ func (c *Client) sendStreamRequest(ctx context.Context, url string, body interfa
 		req.Header.Set("OpenAI-Organization", c.config.OrgID)
 	}
 	
-	// 发送请求
+	// 发送请求，熔断打开时直接失败
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
 	resp, err := c.config.HTTPClient.Do(req)
+	c.recordResult(ctx, resp, err)
+	c.recordKeyResult(apiKey, resp)
 	if err != nil {
 		return nil, fmt.Errorf("发送HTTP请求失败: %w", err)
 	}
@@ -243,6 +371,41 @@ type StreamReader struct {
 	
 	// body 是HTTP响应体
 	body io.ReadCloser
+
+	// strict 严格模式下遇到无法解析的数据行直接返回错误
+	strict bool
+
+	// parseErrors 记录被跳过的无法解析的数据行数量
+	parseErrors int
+
+	// lastBadLine 最近一次无法解析的原始数据行
+	lastBadLine string
+
+	// ctx 流所属的上下文，取消时主动关闭 body 以中断阻塞中的读取
+	ctx context.Context
+
+	// stopWatch 停止监听 ctx 取消
+	stopWatch func() bool
+
+	// idleTimeout 单次读取的空闲超时，0 表示不限制
+	idleTimeout time.Duration
+
+	// idleTimer 空闲超时计时器，body 不支持读取截止时间时超时后关闭 body
+	idleTimer *time.Timer
+
+	// timedOut 标记 body 是否因空闲超时被关闭
+	timedOut atomic.Bool
+}
+
+// ErrStreamParse 流数据行无法解析为JSON
+var ErrStreamParse = errors.New("流数据解析失败")
+
+// ErrStreamIdleTimeout 流在空闲超时时间内未收到任何数据
+var ErrStreamIdleTimeout = errors.New("流读取空闲超时")
+
+// readDeadliner 支持设置读取截止时间的 body（如直接暴露的网络连接）
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
 }
 
 // bufio包已在导入中声明
@@ -253,26 +416,110 @@ func NewStreamReader(body io.ReadCloser) *StreamReader {
 		reader:     bufio.NewReader(body),
 		isFinished: false,
 		body:      body,
+		ctx:        context.Background(),
+	}
+}
+
+// NewStreamReaderWithContext 创建与 ctx 绑定的流读取器
+// ctx 取消时主动关闭 body，使阻塞中的 Recv 及时返回 ctx 的错误
+func NewStreamReaderWithContext(ctx context.Context, body io.ReadCloser) *StreamReader {
+	s := NewStreamReader(body)
+	if ctx == nil {
+		return s
+	}
+	s.ctx = ctx
+	s.stopWatch = context.AfterFunc(ctx, func() {
+		body.Close()
+	})
+	return s
+}
+
+// SetStrict 设置是否启用严格模式
+// 严格模式下遇到无法解析的数据行时 Recv 返回 ErrStreamParse，默认宽松模式仅计数后跳过
+func (s *StreamReader) SetStrict(strict bool) *StreamReader {
+	s.strict = strict
+	return s
+}
+
+// SetIdleTimeout 设置单次读取的空闲超时
+// 服务端发完数据却不关闭连接（或网络半开）时，Recv 超过该时长未收到数据即返回 ErrStreamIdleTimeout；
+// body 支持读取截止时间时直接设置底层连接的 deadline，否则超时后主动关闭 body 中断读取
+func (s *StreamReader) SetIdleTimeout(timeout time.Duration) *StreamReader {
+	s.idleTimeout = timeout
+	return s
+}
+
+// armIdleTimer 在阻塞读取前开始空闲计时
+func (s *StreamReader) armIdleTimer() {
+	if s.idleTimeout <= 0 {
+		return
+	}
+	if d, ok := s.body.(readDeadliner); ok {
+		_ = d.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		return
+	}
+	if s.idleTimer == nil {
+		s.idleTimer = time.AfterFunc(s.idleTimeout, func() {
+			s.timedOut.Store(true)
+			s.body.Close()
+		})
+		return
+	}
+	s.idleTimer.Reset(s.idleTimeout)
+}
+
+// disarmIdleTimer 读取返回后停止空闲计时，调用方处理数据的耗时不计入超时
+func (s *StreamReader) disarmIdleTimer() {
+	if s.idleTimer != nil {
+		s.idleTimer.Stop()
 	}
 }
 
+// ParseErrorCount 返回宽松模式下被跳过的无法解析的数据行数量
+func (s *StreamReader) ParseErrorCount() int {
+	return s.parseErrors
+}
+
+// LastBadLine 返回最近一次无法解析的原始数据行，便于调试
+func (s *StreamReader) LastBadLine() string {
+	return s.lastBadLine
+}
+
 // Close 关闭流读取器
 func (s *StreamReader) Close() error {
 	s.isFinished = true
+	if s.stopWatch != nil {
+		s.stopWatch()
+	}
+	s.disarmIdleTimer()
 	return s.body.Close()
 }
 
 // Recv 从流中接收下一个事件
+// 收到服务端的错误帧时返回 *APIError，之后的调用返回 io.EOF
 func (s *StreamReader) Recv() (map[string]interface{}, error) {
+	if err := s.ctx.Err(); err != nil {
+		s.isFinished = true
+		return nil, err
+	}
 	if s.isFinished {
 		return nil, io.EOF
 	}
 	
 	for {
 		// 读取一行
+		s.armIdleTimer()
 		line, err := s.reader.ReadBytes('\n')
+		s.disarmIdleTimer()
 		if err != nil {
 			s.isFinished = true
+			// body 因 ctx 取消被关闭时返回取消错误，而不是底层的读取错误
+			if ctxErr := s.ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if s.timedOut.Load() || errors.Is(err, os.ErrDeadlineExceeded) {
+				return nil, fmt.Errorf("%w: %v", ErrStreamIdleTimeout, s.idleTimeout)
+			}
 			return nil, err
 		}
 		
@@ -295,13 +542,30 @@ func (s *StreamReader) Recv() (map[string]interface{}, error) {
 		
 		// 删除数据前缀
 		data := bytes.TrimPrefix(line, []byte(prefix))
+
+		// 带数据前缀的结束标记
+		if bytes.Equal(data, []byte("[DONE]")) {
+			s.isFinished = true
+			return nil, io.EOF
+		}
 		
 		// 解析JSON
 		var response map[string]interface{}
 		if err := json.Unmarshal(data, &response); err != nil {
+			s.lastBadLine = string(data)
+			if s.strict {
+				return nil, fmt.Errorf("%w: %v, 原始数据: %s", ErrStreamParse, err, string(data))
+			}
+			s.parseErrors++
 			continue
 		}
-		
+
+		// 服务端中途返回的错误帧，流随之结束
+		if apiErr := parseAPIError(response); apiErr != nil {
+			s.isFinished = true
+			return nil, apiErr
+		}
+
 		return response, nil
 	}
 }
@@ -0,0 +1,37 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrEmptyAPIKey 获取共享客户端时未提供API密钥
+var ErrEmptyAPIKey = errors.New("API密钥不能为空")
+
+// clientPool 按API密钥缓存的共享客户端
+var clientPool = struct {
+	mu      sync.Mutex
+	clients map[string]*Client
+}{clients: make(map[string]*Client)}
+
+// GetOrCreateClient 按API密钥获取共享客户端，不存在时使用默认配置创建并缓存
+// 同一密钥的调用方复用同一个客户端及其底层HTTP连接池，避免每次业务调用都重新创建
+func GetOrCreateClient(apiKey string) (*Client, error) {
+	if apiKey == "" {
+		return nil, ErrEmptyAPIKey
+	}
+
+	clientPool.mu.Lock()
+	defer clientPool.mu.Unlock()
+
+	if client, ok := clientPool.clients[apiKey]; ok {
+		return client, nil
+	}
+	client, err := NewClient(apiKey)
+	if err != nil {
+		return nil, err
+	}
+	clientPool.clients[apiKey] = client
+	return client, nil
+}
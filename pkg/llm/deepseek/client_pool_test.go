@@ -0,0 +1,86 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestGetOrCreateClient 测试按API密钥复用共享客户端
+func TestGetOrCreateClient(t *testing.T) {
+	first, err := GetOrCreateClient("pool-key-a")
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	second, err := GetOrCreateClient("pool-key-a")
+	if err != nil {
+		t.Fatalf("获取客户端失败: %v", err)
+	}
+	if first != second {
+		t.Error("相同API密钥应返回同一个客户端")
+	}
+
+	other, err := GetOrCreateClient("pool-key-b")
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	if other == first {
+		t.Error("不同API密钥不应共享客户端")
+	}
+
+	if _, err := GetOrCreateClient(""); !errors.Is(err, ErrEmptyAPIKey) {
+		t.Errorf("期望ErrEmptyAPIKey错误，实际为%v", err)
+	}
+}
+
+// TestClient_ConcurrentUse 测试多个 goroutine 并发复用同一客户端
+// 需配合 go test -race 运行以检测数据竞争
+func TestClient_ConcurrentUse(t *testing.T) {
+	var requests int64
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"chat-1","choices":[{"index":0,"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+	defer server.Close()
+
+	config := DefaultConfig("test-api-key").WithBaseURL(server.URL)
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	// 创建后修改原配置不应影响客户端
+	config.WithChatPath("/not-found")
+
+	const workers = 16
+	var wg sync.WaitGroup
+	errCh := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithEndUser(context.Background(), fmt.Sprintf("user-%d", i))
+			_, err := client.ChatCompletion(ctx, &ChatRequest{
+				Model:    "deepseek-chat",
+				Messages: []Message{{Role: "user", Content: "你好"}},
+			})
+			if err != nil {
+				errCh <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		t.Errorf("并发请求失败: %v", err)
+	}
+	if got := atomic.LoadInt64(&requests); got != workers {
+		t.Errorf("期望服务端收到%d个请求，实际为%d", workers, got)
+	}
+}
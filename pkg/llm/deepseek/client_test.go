@@ -335,3 +335,59 @@ func TestMessageBuilder(t *testing.T) {
 		t.Errorf("期望Messages长度为3，实际为%d", len(req.Messages))
 	}
 }
+
+// TestCustomAPIPaths 测试覆盖接口路径后请求打到自定义路径
+func TestCustomAPIPaths(t *testing.T) {
+	var paths []string
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data": [], "choices": []}`))
+	})
+	defer server.Close()
+
+	config := DefaultConfig("test-api-key").
+		WithBaseURL(server.URL + "/gateway/").
+		WithCompletionsPath("/deepseek/completions").
+		WithChatPath("deepseek/chat").
+		WithModelsPath("/deepseek/models")
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := client.Models(ctx); err != nil {
+		t.Fatalf("获取模型列表失败: %v", err)
+	}
+	if _, err := client.Completion(ctx, &CompletionRequest{Model: constants.DeepSeekChat, Prompt: "测试"}); err != nil {
+		t.Fatalf("发送文本生成请求失败: %v", err)
+	}
+	msgBuilder := NewMessageBuilder()
+	msgBuilder.AddUserMessage("测试")
+	if _, err := client.ChatCompletion(ctx, &ChatRequest{Model: constants.DeepSeekChat, Messages: msgBuilder.Messages()}); err != nil {
+		t.Fatalf("发送聊天请求失败: %v", err)
+	}
+
+	want := []string{"/gateway/deepseek/models", "/gateway/deepseek/completions", "/gateway/deepseek/chat"}
+	if len(paths) != len(want) {
+		t.Fatalf("期望请求路径为%v，实际为%v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("第%d个请求期望路径为'%s'，实际为'%s'", i, want[i], paths[i])
+		}
+	}
+}
+
+// TestDefaultAPIPaths 测试未覆盖时保持默认路径
+func TestDefaultAPIPaths(t *testing.T) {
+	config := DefaultConfig("test-api-key").WithBaseURL("http://example.com/")
+	if got := config.endpoint(config.CompletionsPath, DefaultCompletionsPath); got != "http://example.com/beta/completions" {
+		t.Errorf("期望completions路径为默认beta路径，实际为'%s'", got)
+	}
+	if got := config.endpoint(config.ChatPath, DefaultChatPath); got != "http://example.com/v1/chat/completions" {
+		t.Errorf("期望chat路径为默认v1路径，实际为'%s'", got)
+	}
+}
@@ -4,6 +4,7 @@ package deepseek
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/openai/openai-go"
@@ -16,6 +17,18 @@ const (
 
 	// DefaultTimeout 是HTTP请求的默认超时时间
 	DefaultTimeout = 30 * time.Second
+
+	// DefaultCompletionsPath 是文本补全接口的默认路径，DeepSeek 的 completions 只在 beta 下提供
+	DefaultCompletionsPath = "/beta/completions"
+
+	// DefaultChatPath 是聊天接口的默认路径
+	DefaultChatPath = "/v1/chat/completions"
+
+	// DefaultModelsPath 是模型列表接口的默认路径
+	DefaultModelsPath = "/models"
+
+	// DefaultStreamIdleTimeout 是流式响应单次读取的默认空闲超时
+	DefaultStreamIdleTimeout = 60 * time.Second
 )
 
 // Config 存储DeepSeek API客户端配置
@@ -26,6 +39,12 @@ type Config struct {
 	// APIKey 是DeepSeek API的认证密钥
 	APIKey string
 
+	// APIKeys 是轮换使用的多个认证密钥（可选），非空时按轮询选择密钥并覆盖 APIKey
+	APIKeys []string
+
+	// KeyCooldown 是密钥被限流（429）后暂时避开的时长，为 0 时使用 DefaultKeyCooldown
+	KeyCooldown time.Duration
+
 	// OrgID 是组织ID（可选）
 	OrgID string
 
@@ -37,6 +56,28 @@ type Config struct {
 
 	// UserAgent 是请求的User-Agent头
 	UserAgent string
+
+	// UsageRecorder 是用量上报钩子（可选）
+	UsageRecorder UsageRecorder
+
+	// CompletionsPath 覆盖文本补全接口路径（可选），为空时使用 DefaultCompletionsPath
+	CompletionsPath string
+
+	// ChatPath 覆盖聊天接口路径（可选），为空时使用 DefaultChatPath
+	ChatPath string
+
+	// ModelsPath 覆盖模型列表接口路径（可选），为空时使用 DefaultModelsPath
+	ModelsPath string
+
+	// StreamIdleTimeout 是流式响应单次读取的空闲超时，0 表示不限制
+	StreamIdleTimeout time.Duration
+
+	// BreakerThreshold 是触发熔断的连续失败次数，0 表示不启用熔断
+	// 默认不启用，需要时通过 WithCircuitBreaker 开启
+	BreakerThreshold int
+
+	// BreakerCooldown 是熔断打开后的冷却时间，为 0 时使用 DefaultBreakerCooldown
+	BreakerCooldown time.Duration
 }
 
 // DefaultConfig 返回一个默认的配置
@@ -47,6 +88,9 @@ func DefaultConfig(apiKey string) *Config {
 		Timeout:    30 * time.Second,
 		HTTPClient: &http.Client{Timeout: 30 * time.Second},
 		UserAgent:  "deepseek-go/1.0.0",
+
+		StreamIdleTimeout: DefaultStreamIdleTimeout,
+		BreakerCooldown:   DefaultBreakerCooldown,
 	}
 }
 
@@ -56,6 +100,18 @@ func (c *Config) WithBaseURL(baseURL string) *Config {
 	return c
 }
 
+// WithAPIKeys 设置轮换使用的多个认证密钥
+func (c *Config) WithAPIKeys(keys ...string) *Config {
+	c.APIKeys = keys
+	return c
+}
+
+// WithKeyCooldown 设置密钥被限流后暂时避开的时长
+func (c *Config) WithKeyCooldown(cooldown time.Duration) *Config {
+	c.KeyCooldown = cooldown
+	return c
+}
+
 // WithOrgID 设置组织ID
 func (c *Config) WithOrgID(orgID string) *Config {
 	c.OrgID = orgID
@@ -81,6 +137,51 @@ func (c *Config) WithUserAgent(userAgent string) *Config {
 	return c
 }
 
+// WithUsageRecorder 设置用量上报钩子
+func (c *Config) WithUsageRecorder(recorder UsageRecorder) *Config {
+	c.UsageRecorder = recorder
+	return c
+}
+
+// WithCompletionsPath 设置文本补全接口路径
+func (c *Config) WithCompletionsPath(path string) *Config {
+	c.CompletionsPath = path
+	return c
+}
+
+// WithChatPath 设置聊天接口路径
+func (c *Config) WithChatPath(path string) *Config {
+	c.ChatPath = path
+	return c
+}
+
+// WithModelsPath 设置模型列表接口路径
+func (c *Config) WithModelsPath(path string) *Config {
+	c.ModelsPath = path
+	return c
+}
+
+// WithStreamIdleTimeout 设置流式响应单次读取的空闲超时
+func (c *Config) WithStreamIdleTimeout(timeout time.Duration) *Config {
+	c.StreamIdleTimeout = timeout
+	return c
+}
+
+// WithCircuitBreaker 设置熔断阈值与冷却时间，threshold 为 0 时不启用熔断
+func (c *Config) WithCircuitBreaker(threshold int, cooldown time.Duration) *Config {
+	c.BreakerThreshold = threshold
+	c.BreakerCooldown = cooldown
+	return c
+}
+
+// endpoint 拼接基础URL与接口路径，path 为空时使用默认路径
+func (c *Config) endpoint(path, defaultPath string) string {
+	if path == "" {
+		path = defaultPath
+	}
+	return strings.TrimRight(c.BaseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
 // CreateClient 创建一个OpenAI SDK客户端
 func (c *Config) CreateClient() (*openai.Client, error) {
 	// 准备选项
@@ -0,0 +1,167 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"novelai/pkg/constants"
+)
+
+const (
+	// DefaultCompressRatio 历史估算token数达到上下文窗口的该比例时触发压缩
+	DefaultCompressRatio = 0.8
+
+	// DefaultKeepRecentTurns 压缩时默认保留的最近对话轮数
+	DefaultKeepRecentTurns = 4
+
+	// summaryPrefix 摘要消息内容的前缀，用于识别已有摘要
+	summaryPrefix = "以下是之前对话的摘要：\n"
+)
+
+// summaryPromptTemplate 历史摘要提示词模板，参数为待摘要的对话记录
+const summaryPromptTemplate = `请将下面的小说创作对话压缩为一段摘要，保留已确定的设定、人物、情节进展和尚未完成的要求，省略寒暄和重复内容。
+对话记录：
+%s
+只输出摘要本身。`
+
+// ErrEmptySummary 模型返回的历史摘要为空
+var ErrEmptySummary = errors.New("历史摘要为空")
+
+// ConversationConfig 多轮对话的配置
+type ConversationConfig struct {
+	// Model 是使用的模型名称
+	Model string
+
+	// MaxTokens 是每次回复的最大token数
+	MaxTokens int
+
+	// CompressThreshold 历史估算token数（含 MaxTokens）超过该值时压缩，
+	// 为0时按模型上下文窗口的 DefaultCompressRatio 计算，未知模型不压缩
+	CompressThreshold int
+
+	// KeepRecentTurns 压缩时保留的最近对话轮数，为0时使用 DefaultKeepRecentTurns
+	KeepRecentTurns int
+}
+
+// Conversation 带上下文窗口管理的多轮对话
+// 历史接近上限时，调用模型把较早的对话摘要为一条系统消息，保留系统提示和最近若干轮；
+// 同一对话的方法不可并发调用
+type Conversation struct {
+	adapter      *Adapter
+	config       ConversationConfig
+	systemPrompt string
+	summary      string    // 较早对话的摘要，为空表示尚未压缩
+	history      []Message // 摘要之后的用户与助手消息
+}
+
+// NewConversation 创建多轮对话，systemPrompt 为空时不发送系统提示
+func (a *Adapter) NewConversation(systemPrompt string, config ConversationConfig) *Conversation {
+	if config.KeepRecentTurns <= 0 {
+		config.KeepRecentTurns = DefaultKeepRecentTurns
+	}
+	return &Conversation{
+		adapter:      a,
+		config:       config,
+		systemPrompt: systemPrompt,
+	}
+}
+
+// Send 发送一条用户消息并返回助手回复
+// 发送前若历史超出压缩阈值，会先压缩较早的对话；请求失败时本条用户消息不计入历史
+func (c *Conversation) Send(ctx context.Context, content string) (string, error) {
+	c.history = append(c.history, Message{Role: constants.RoleUser, Content: content})
+
+	if c.needsCompress() {
+		if err := c.Compress(ctx); err != nil {
+			c.history = c.history[:len(c.history)-1]
+			return "", fmt.Errorf("压缩对话历史失败: %w", err)
+		}
+	}
+
+	reply, err := c.adapter.ChatWithMessages(ctx, c.config.Model, c.Messages(), c.config.MaxTokens)
+	if err != nil {
+		c.history = c.history[:len(c.history)-1]
+		return "", err
+	}
+	c.history = append(c.history, Message{Role: constants.RoleAssistant, Content: reply})
+	return reply, nil
+}
+
+// Messages 返回发送给模型的完整消息列表：系统提示、历史摘要和最近的对话
+func (c *Conversation) Messages() []Message {
+	messages := make([]Message, 0, len(c.history)+2)
+	if c.systemPrompt != "" {
+		messages = append(messages, Message{Role: constants.RoleSystem, Content: c.systemPrompt})
+	}
+	if c.summary != "" {
+		messages = append(messages, Message{Role: constants.RoleSystem, Content: summaryPrefix + c.summary})
+	}
+	return append(messages, c.history...)
+}
+
+// Summary 返回当前的历史摘要，尚未压缩时返回空字符串
+func (c *Conversation) Summary() string {
+	return c.summary
+}
+
+// Compress 将最近 KeepRecentTurns 轮之前的对话摘要为一条系统消息
+// 已有摘要会与待压缩的对话一起重新摘要；可压缩的对话不足时不做处理
+func (c *Conversation) Compress(ctx context.Context) error {
+	cut := c.recentStart()
+	if cut <= 0 {
+		return nil
+	}
+
+	var transcript strings.Builder
+	if c.summary != "" {
+		transcript.WriteString("[此前摘要] " + c.summary + "\n")
+	}
+	for _, msg := range c.history[:cut] {
+		transcript.WriteString("[" + msg.Role + "] " + msg.Content + "\n")
+	}
+
+	summary, err := c.adapter.ChatWithMessages(ctx, c.config.Model, []Message{
+		{Role: constants.RoleUser, Content: fmt.Sprintf(summaryPromptTemplate, transcript.String())},
+	}, c.config.MaxTokens)
+	if err != nil {
+		return err
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return ErrEmptySummary
+	}
+
+	c.summary = summary
+	c.history = append([]Message(nil), c.history[cut:]...)
+	return nil
+}
+
+// recentStart 返回需要保留的最近若干轮在历史中的起始下标，一轮以用户消息开始
+func (c *Conversation) recentStart() int {
+	turns := 0
+	for i := len(c.history) - 1; i >= 0; i-- {
+		if c.history[i].Role != constants.RoleUser {
+			continue
+		}
+		turns++
+		if turns == c.config.KeepRecentTurns {
+			return i
+		}
+	}
+	return 0
+}
+
+// needsCompress 判断当前历史的估算token数是否超出压缩阈值
+func (c *Conversation) needsCompress() bool {
+	threshold := c.config.CompressThreshold
+	if threshold <= 0 {
+		threshold = int(float64(ContextLimitFor(c.config.Model)) * DefaultCompressRatio)
+	}
+	if threshold <= 0 {
+		return false
+	}
+	return estimateMessagesTokens(c.Messages())+c.config.MaxTokens > threshold
+}
@@ -0,0 +1,105 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"novelai/pkg/constants"
+)
+
+// newConversationServer 创建模拟聊天服务：摘要请求返回固定摘要，其余请求返回固定回复
+func newConversationServer(t *testing.T, summaryCalls *int) *Adapter {
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+		reply := "好的，继续创作。"
+		if len(req.Messages) == 1 && strings.Contains(req.Messages[0].Content, "压缩为一段摘要") {
+			*summaryCalls++
+			reply = "主角林舟在北境要塞完成了第一章的冒险。"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{
+				"message": map[string]interface{}{"role": "assistant", "content": reply},
+			}},
+		})
+	})
+	t.Cleanup(server.Close)
+
+	adapter, err := NewAdapterWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("创建适配器失败: %v", err)
+	}
+	return adapter
+}
+
+// TestConversation_Compress 测试超长历史触发压缩后保留系统提示、摘要和最近几轮
+func TestConversation_Compress(t *testing.T) {
+	var summaryCalls int
+	adapter := newConversationServer(t, &summaryCalls)
+	conv := adapter.NewConversation("你是小说创作助手", ConversationConfig{
+		Model:             constants.DeepSeekChat,
+		MaxTokens:         10,
+		CompressThreshold: 200,
+		KeepRecentTurns:   2,
+	})
+
+	ctx := context.Background()
+	longText := strings.Repeat("北境要塞的风雪", 10)
+	for i := 0; i < 6; i++ {
+		if _, err := conv.Send(ctx, longText); err != nil {
+			t.Fatalf("第%d轮发送失败: %v", i, err)
+		}
+	}
+
+	if summaryCalls == 0 {
+		t.Fatal("期望历史超出阈值后触发摘要压缩")
+	}
+	messages := conv.Messages()
+	if len(messages) >= 2+6*2 {
+		t.Errorf("期望压缩后消息数下降，实际为%d", len(messages))
+	}
+	if messages[0].Role != constants.RoleSystem || messages[0].Content != "你是小说创作助手" {
+		t.Errorf("期望保留系统提示，实际为%+v", messages[0])
+	}
+	if messages[1].Role != constants.RoleSystem || !strings.Contains(messages[1].Content, "林舟") {
+		t.Errorf("期望第二条为摘要消息，实际为%+v", messages[1])
+	}
+
+	// 最近两轮（用户+助手）完整保留
+	recent := messages[len(messages)-4:]
+	wantRoles := []string{constants.RoleUser, constants.RoleAssistant, constants.RoleUser, constants.RoleAssistant}
+	for i, msg := range recent {
+		if msg.Role != wantRoles[i] {
+			t.Errorf("期望最近第%d条角色为%s，实际为%s", i, wantRoles[i], msg.Role)
+		}
+	}
+	if recent[3].Content != "好的，继续创作。" {
+		t.Errorf("期望保留最近一轮回复，实际为%s", recent[3].Content)
+	}
+}
+
+// TestConversation_NoCompressBelowThreshold 测试未超出阈值时不压缩
+func TestConversation_NoCompressBelowThreshold(t *testing.T) {
+	var summaryCalls int
+	adapter := newConversationServer(t, &summaryCalls)
+	conv := adapter.NewConversation("", ConversationConfig{Model: constants.DeepSeekChat, MaxTokens: 10})
+
+	for i := 0; i < 3; i++ {
+		if _, err := conv.Send(context.Background(), "你好"); err != nil {
+			t.Fatalf("发送失败: %v", err)
+		}
+	}
+	if summaryCalls != 0 || conv.Summary() != "" {
+		t.Errorf("未超出阈值时不应压缩，摘要调用%d次", summaryCalls)
+	}
+	if got := len(conv.Messages()); got != 6 {
+		t.Errorf("期望6条消息，实际为%d", got)
+	}
+}
@@ -0,0 +1,100 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultKeyCooldown 是某个密钥被限流（429）后暂时避开的默认时长
+const DefaultKeyCooldown = time.Minute
+
+// keyRing 多个API密钥的轮询选择器，并发安全
+// 按轮询顺序选择密钥，跳过仍在限流冷却期内的密钥；全部密钥都在冷却时选最早恢复的一个
+type keyRing struct {
+	mu           sync.Mutex
+	keys         []string
+	coolingUntil []time.Time // 各密钥限流冷却的结束时间，零值表示可用
+	next         int         // 下一次轮询的起始下标
+	cooldown     time.Duration
+	now          func() time.Time
+	index        map[string]int // 密钥到下标的映射
+}
+
+// newKeyRing 创建密钥选择器，keys 为空时使用 fallback 单个密钥
+func newKeyRing(keys []string, fallback string, cooldown time.Duration) *keyRing {
+	unique := make([]string, 0, len(keys))
+	index := make(map[string]int, len(keys))
+	for _, key := range keys {
+		if _, ok := index[key]; ok || key == "" {
+			continue
+		}
+		index[key] = len(unique)
+		unique = append(unique, key)
+	}
+	if len(unique) == 0 {
+		unique = append(unique, fallback)
+		index[fallback] = 0
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultKeyCooldown
+	}
+	return &keyRing{
+		keys:         unique,
+		coolingUntil: make([]time.Time, len(unique)),
+		cooldown:     cooldown,
+		now:          time.Now,
+		index:        index,
+	}
+}
+
+// pick 选择下一个用于请求的密钥
+func (r *keyRing) pick() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	soonest := -1
+	for i := 0; i < len(r.keys); i++ {
+		idx := (r.next + i) % len(r.keys)
+		if !now.Before(r.coolingUntil[idx]) {
+			r.next = idx + 1
+			return r.keys[idx]
+		}
+		if soonest < 0 || r.coolingUntil[idx].Before(r.coolingUntil[soonest]) {
+			soonest = idx
+		}
+	}
+	r.next = soonest + 1
+	return r.keys[soonest]
+}
+
+// markRateLimited 记录密钥被限流，retryAfter 大于0时按其冷却，否则使用默认冷却时长
+func (r *keyRing) markRateLimited(key string, retryAfter time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	idx, ok := r.index[key]
+	if !ok {
+		return
+	}
+	cooldown := r.cooldown
+	if retryAfter > 0 {
+		cooldown = retryAfter
+	}
+	r.coolingUntil[idx] = r.now().Add(cooldown)
+}
+
+// recordKeyResult 响应为 429 时让本次使用的密钥进入冷却，优先采用 Retry-After 头指定的秒数
+func (c *Client) recordKeyResult(key string, resp *http.Response) {
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	var retryAfter time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	c.keys.markRateLimited(key, retryAfter)
+}
@@ -0,0 +1,130 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"novelai/pkg/constants"
+)
+
+// TestClient_APIKeyRotation 测试配置多个密钥时请求在密钥间轮换，某密钥返回429后被暂时跳过
+func TestClient_APIKeyRotation(t *testing.T) {
+	var mu sync.Mutex
+	var used []string
+	limited := map[string]bool{"key-b": true}
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		mu.Lock()
+		used = append(used, key)
+		isLimited := limited[key]
+		mu.Unlock()
+		if isLimited {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+	defer server.Close()
+
+	config := DefaultConfig("").WithBaseURL(server.URL).WithAPIKeys("key-a", "key-b", "key-c").
+		WithKeyCooldown(time.Minute).WithCircuitBreaker(0, 0)
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	now := time.Now()
+	client.keys.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	chat := func() error {
+		_, err := client.ChatCompletion(ctx, &ChatRequest{
+			Model:    constants.DeepSeekChat,
+			Messages: []Message{{Role: constants.RoleUser, Content: "你好"}},
+		})
+		return err
+	}
+	usedKeys := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		keys := strings.Join(used, ",")
+		used = nil
+		return keys
+	}
+
+	// 第一轮依次使用三个密钥，key-b 被限流
+	for i := 0; i < 3; i++ {
+		err := chat()
+		if i == 1 && err == nil {
+			t.Errorf("期望key-b返回限流错误")
+		}
+		if i != 1 && err != nil {
+			t.Errorf("第%d次请求失败: %v", i, err)
+		}
+	}
+	if got := usedKeys(); got != "key-a,key-b,key-c" {
+		t.Fatalf("期望按轮询使用密钥，实际为%s", got)
+	}
+
+	// 冷却期内跳过 key-b
+	for i := 0; i < 4; i++ {
+		if err := chat(); err != nil {
+			t.Fatalf("冷却期内请求失败: %v", err)
+		}
+	}
+	if got := usedKeys(); got != "key-a,key-c,key-a,key-c" {
+		t.Errorf("期望冷却期内跳过key-b，实际为%s", got)
+	}
+
+	// 冷却结束后 key-b 重新参与轮换
+	mu.Lock()
+	limited["key-b"] = false
+	mu.Unlock()
+	now = now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		if err := chat(); err != nil {
+			t.Fatalf("冷却结束后请求失败: %v", err)
+		}
+	}
+	if got := usedKeys(); got != "key-a,key-b,key-c" {
+		t.Errorf("期望冷却结束后恢复轮换，实际为%s", got)
+	}
+}
+
+// TestKeyRing_RetryAfterAndAllLimited 测试按Retry-After冷却，全部密钥被限流时选最早恢复的密钥
+func TestKeyRing_RetryAfterAndAllLimited(t *testing.T) {
+	ring := newKeyRing([]string{"key-a", "key-b", "key-a", ""}, "fallback", time.Minute)
+	if len(ring.keys) != 2 {
+		t.Fatalf("期望去重并忽略空密钥，实际为%v", ring.keys)
+	}
+	now := time.Now()
+	ring.now = func() time.Time { return now }
+	client := &Client{keys: ring}
+
+	header := http.Header{}
+	header.Set("Retry-After", "5")
+	client.recordKeyResult("key-a", &http.Response{StatusCode: http.StatusTooManyRequests, Header: header})
+	client.recordKeyResult("key-b", &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	if got := ring.pick(); got != "key-a" {
+		t.Errorf("全部被限流时期望选最早恢复的key-a，实际为%s", got)
+	}
+
+	now = now.Add(5 * time.Second)
+	if got := ring.pick(); got != "key-a" {
+		t.Errorf("key-a按Retry-After冷却结束后应可用，实际为%s", got)
+	}
+	if got := ring.pick(); got != "key-a" {
+		t.Errorf("key-b仍在默认冷却期内应被跳过，实际为%s", got)
+	}
+
+	single := newKeyRing(nil, "only-key", 0)
+	if got := single.pick(); got != "only-key" || single.cooldown != DefaultKeyCooldown {
+		t.Errorf("未配置多个密钥时应使用单个密钥与默认冷却，实际为%s %v", got, single.cooldown)
+	}
+}
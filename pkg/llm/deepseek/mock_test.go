@@ -4,8 +4,14 @@ package deepseek
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 // 测试用的模拟流读取器
@@ -384,3 +390,259 @@ data: [DONE]
 		t.Errorf("期望文本为'有效JSON'，实际为'%s'", text)
 	}
 }
+
+// invalidJSONStream 包含一行坏JSON的模拟流数据
+const invalidJSONStream = `
+data: {"id":"cmpl-123",invalid json
+
+data: {"id":"cmpl-123","choices":[{"text":"有效JSON"}]}
+
+data: [DONE]
+`
+
+// TestStreamReader_StrictMode 测试严格模式下遇到无效JSON返回错误
+func TestStreamReader_StrictMode(t *testing.T) {
+	streamReader := NewStreamReader(newMockReadCloser(invalidJSONStream, nil)).SetStrict(true)
+
+	_, err := streamReader.Recv()
+	if !errors.Is(err, ErrStreamParse) {
+		t.Fatalf("期望ErrStreamParse错误，实际为%v", err)
+	}
+	if streamReader.LastBadLine() != `{"id":"cmpl-123",invalid json` {
+		t.Errorf("原始数据行记录错误: %s", streamReader.LastBadLine())
+	}
+
+	// 返回错误后仍可继续读取后续有效数据
+	resp, err := streamReader.Recv()
+	if err != nil {
+		t.Fatalf("读取有效JSON失败: %v", err)
+	}
+	if resp["id"] != "cmpl-123" {
+		t.Errorf("期望id为'cmpl-123'，实际为%v", resp["id"])
+	}
+}
+
+// TestStreamReader_ParseErrorCount 测试宽松模式下跳过无效JSON并计数
+func TestStreamReader_ParseErrorCount(t *testing.T) {
+	streamReader := NewStreamReader(newMockReadCloser(invalidJSONStream, nil))
+
+	if _, err := streamReader.Recv(); err != nil {
+		t.Fatalf("宽松模式不应返回错误: %v", err)
+	}
+	if streamReader.ParseErrorCount() != 1 {
+		t.Errorf("期望解析错误计数为1，实际为%d", streamReader.ParseErrorCount())
+	}
+
+	if _, err := streamReader.Recv(); err != io.EOF {
+		t.Errorf("期望EOF错误，实际为%v", err)
+	}
+	if streamReader.ParseErrorCount() != 1 {
+		t.Errorf("期望解析错误计数保持为1，实际为%d", streamReader.ParseErrorCount())
+	}
+}
+
+// recvResult 异步读取的结果
+type recvResult struct {
+	resp map[string]interface{}
+	err  error
+}
+
+// recvAsync 在协程中调用 Recv，便于检测是否阻塞
+func recvAsync(streamReader *StreamReader) <-chan recvResult {
+	ch := make(chan recvResult, 1)
+	go func() {
+		resp, err := streamReader.Recv()
+		ch <- recvResult{resp: resp, err: err}
+	}()
+	return ch
+}
+
+// TestStreamReader_ContextCancel 测试ctx取消时阻塞中的Recv及时返回
+func TestStreamReader_ContextCancel(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	streamReader := NewStreamReaderWithContext(ctx, pr)
+
+	go pw.Write([]byte("data: {\"id\":\"chat-1\"}\n\n"))
+	if _, err := streamReader.Recv(); err != nil {
+		t.Fatalf("读取第一条数据失败: %v", err)
+	}
+
+	// 此后管道不再写入数据，Recv 阻塞在底层读取上
+	resultCh := recvAsync(streamReader)
+	cancel()
+
+	select {
+	case result := <-resultCh:
+		if !errors.Is(result.err, context.Canceled) {
+			t.Errorf("期望context.Canceled错误，实际为%v", result.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ctx取消后Recv仍然阻塞")
+	}
+
+	// 取消后再次读取直接返回取消错误
+	if _, err := streamReader.Recv(); !errors.Is(err, context.Canceled) {
+		t.Errorf("期望context.Canceled错误，实际为%v", err)
+	}
+}
+
+// TestChatCompletionStream_ContextCancel 测试流式聊天进行中取消ctx
+func TestChatCompletionStream_ContextCancel(t *testing.T) {
+	serverDone := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"chat-1\"}\n\n")
+		w.(http.Flusher).Flush()
+		// 模拟服务端迟迟不发送后续数据
+		select {
+		case <-r.Context().Done():
+		case <-serverDone:
+		}
+	}))
+	defer server.Close()
+	defer close(serverDone)
+
+	client, err := NewClientWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.ChatCompletionStream(ctx, &ChatRequest{Model: "deepseek-chat"})
+	if err != nil {
+		t.Fatalf("创建流失败: %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("读取第一条数据失败: %v", err)
+	}
+
+	resultCh := recvAsync(stream)
+	cancel()
+
+	select {
+	case result := <-resultCh:
+		if !errors.Is(result.err, context.Canceled) {
+			t.Errorf("期望context.Canceled错误，实际为%v", result.err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ctx取消后Recv仍然阻塞")
+	}
+}
+
+// TestStreamReader_IdleTimeout 测试服务端发完数据后挂起不关闭连接时 Recv 超时返回
+func TestStreamReader_IdleTimeout(t *testing.T) {
+	serverDone := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"id\":\"chat-1\"}\n\n")
+		w.(http.Flusher).Flush()
+		// 模拟服务端发完数据后既不发送 [DONE] 也不关闭连接
+		select {
+		case <-r.Context().Done():
+		case <-serverDone:
+		}
+	}))
+	defer server.Close()
+	defer close(serverDone)
+
+	config := DefaultConfig("test-api-key").WithBaseURL(server.URL).WithStreamIdleTimeout(100 * time.Millisecond)
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	stream, err := client.ChatCompletionStream(context.Background(), &ChatRequest{Model: "deepseek-chat"})
+	if err != nil {
+		t.Fatalf("创建流失败: %v", err)
+	}
+	defer stream.Close()
+
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("读取第一条数据失败: %v", err)
+	}
+
+	// 读取数据后调用方处理耗时超过空闲超时，不应影响下一次读取的计时
+	time.Sleep(150 * time.Millisecond)
+
+	select {
+	case result := <-recvAsync(stream):
+		if !errors.Is(result.err, ErrStreamIdleTimeout) {
+			t.Errorf("期望ErrStreamIdleTimeout错误，实际为%v", result.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("空闲超时后Recv仍然阻塞")
+	}
+}
+
+// TestStreamReader_IdleTimeoutNotTriggered 测试数据持续到达时不触发空闲超时
+func TestStreamReader_IdleTimeoutNotTriggered(t *testing.T) {
+	pr, pw := io.Pipe()
+	streamReader := NewStreamReader(pr).SetIdleTimeout(200 * time.Millisecond)
+	defer streamReader.Close()
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprintf(pw, "data: {\"index\":%d}\n\n", i)
+		}
+		fmt.Fprint(pw, "data: [DONE]\n\n")
+		pw.Close()
+	}()
+
+	for i := 0; i < 3; i++ {
+		if _, err := streamReader.Recv(); err != nil {
+			t.Fatalf("第%d次读取失败: %v", i, err)
+		}
+	}
+	if _, err := streamReader.Recv(); err != io.EOF {
+		t.Errorf("期望io.EOF，实际为%v", err)
+	}
+}
+
+// TestStreamReader_ErrorFrame 测试流中途出现错误帧时Recv返回结构化错误而非继续
+func TestStreamReader_ErrorFrame(t *testing.T) {
+	mockSSE := `
+data: {"id":"chat-1","choices":[{"delta":{"content":"北境"}}]}
+
+data: {"error":{"message":"服务繁忙","type":"server_error","code":503}}
+
+data: {"id":"chat-1","choices":[{"delta":{"content":"要塞"}}]}
+
+data: [DONE]
+`
+	streamReader := NewStreamReader(newMockReadCloser(mockSSE, nil))
+
+	if _, err := streamReader.Recv(); err != nil {
+		t.Fatalf("读取第一条数据失败: %v", err)
+	}
+
+	_, err := streamReader.Recv()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("期望*APIError错误，实际为%v", err)
+	}
+	if apiErr.Message != "服务繁忙" || apiErr.Type != "server_error" || apiErr.Code != "503" {
+		t.Errorf("错误帧解析不正确: %+v", apiErr)
+	}
+
+	// 错误帧之后流结束，不再返回后续数据
+	if _, err := streamReader.Recv(); err != io.EOF {
+		t.Errorf("期望io.EOF，实际为%v", err)
+	}
+}
+
+// TestStreamReader_ErrorFrameString 测试error字段为字符串时同样返回结构化错误
+func TestStreamReader_ErrorFrameString(t *testing.T) {
+	streamReader := NewStreamReader(newMockReadCloser("data: {\"error\":\"额度不足\"}\n\n", nil))
+
+	_, err := streamReader.Recv()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "额度不足" {
+		t.Errorf("期望错误信息为'额度不足'，实际为%v", err)
+	}
+}
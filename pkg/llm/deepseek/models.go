@@ -49,6 +49,12 @@ type CompletionRequest struct {
 
 	// Stop 是停止生成的序列
 	Stop []string `json:"stop,omitempty"`
+
+	// User 是终端用户标识，供平台侧做滥用检测
+	User string `json:"user,omitempty"`
+
+	// Seed 是随机种子，设置后相同请求尽量返回相同结果，用于测试与内容复现
+	Seed *int `json:"seed,omitempty"`
 }
 
 // ChatRequest 表示聊天生成请求
@@ -79,6 +85,12 @@ type ChatRequest struct {
 
 	// ResponseFormat 指定模型输出格式（如 JSON 模式）
 	ResponseFormat ResponseFormat `json:"response_format,omitempty"`
+
+	// User 是终端用户标识，供平台侧做滥用检测
+	User string `json:"user,omitempty"`
+
+	// Seed 是随机种子，设置后相同请求尽量返回相同结果，用于测试与内容复现
+	Seed *int `json:"seed,omitempty"`
 }
 
 // MessageBuilder 用于构建聊天消息序列
@@ -0,0 +1,77 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode/utf8"
+
+	"novelai/pkg/constants"
+)
+
+// ErrContextTooLong 请求估算的token数超出模型上下文窗口
+var ErrContextTooLong = errors.New("请求超出模型上下文窗口")
+
+// messageTokenOverhead 每条聊天消息的格式开销（角色、分隔符等）估算
+const messageTokenOverhead = 4
+
+// modelContextLimits 各模型的上下文窗口上限（token）
+// 未收录的模型不做预检查
+var modelContextLimits = map[string]int{
+	constants.DeepSeekChat:  65536,
+	constants.DeepSeekCoder: 16384,
+	constants.DeepSeekMax:   4096,
+	constants.DeepSeek7B:    4096,
+}
+
+// ContextLimitFor 返回模型的上下文窗口上限，未知模型返回0
+func ContextLimitFor(model string) int {
+	return modelContextLimits[model]
+}
+
+// EstimateTokens 估算文本的token数
+// 按官方经验值：1个英文字符约0.3个token，1个中文字符约0.6个token，结果向上取整
+func EstimateTokens(text string) int {
+	var ascii, other int
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return int(math.Ceil(float64(ascii)*0.3 + float64(other)*0.6))
+}
+
+// estimateMessagesTokens 估算聊天消息列表的token数
+func estimateMessagesTokens(messages []Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += EstimateTokens(msg.Content) + messageTokenOverhead
+	}
+	return total
+}
+
+// checkContextLimit 校验估算的输入token数加上 maxTokens 是否超出模型上限
+func checkContextLimit(model string, promptTokens, maxTokens int) error {
+	limit := ContextLimitFor(model)
+	if limit <= 0 {
+		return nil
+	}
+	if promptTokens+maxTokens > limit {
+		return fmt.Errorf("%w: 估算输入 %d tokens，max_tokens %d，模型 %s 上限 %d tokens，请裁剪消息或减小 max_tokens",
+			ErrContextTooLong, promptTokens, maxTokens, model, limit)
+	}
+	return nil
+}
+
+// precheckChatRequest 发送前预检查聊天请求大小
+func precheckChatRequest(request *ChatRequest) error {
+	return checkContextLimit(request.Model, estimateMessagesTokens(request.Messages), request.MaxTokens)
+}
+
+// precheckCompletionRequest 发送前预检查文本生成请求大小
+func precheckCompletionRequest(request *CompletionRequest) error {
+	return checkContextLimit(request.Model, EstimateTokens(request.Prompt), request.MaxTokens)
+}
@@ -0,0 +1,74 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"novelai/pkg/constants"
+)
+
+// TestEstimateTokens 测试token数估算
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(""); got != 0 {
+		t.Errorf("空文本期望0个token，实际为%d", got)
+	}
+	if got := EstimateTokens(strings.Repeat("a", 10)); got != 3 {
+		t.Errorf("10个英文字符期望3个token，实际为%d", got)
+	}
+	if got := EstimateTokens(strings.Repeat("字", 10)); got != 6 {
+		t.Errorf("10个中文字符期望6个token，实际为%d", got)
+	}
+}
+
+// TestChatCompletion_ContextPrecheck 测试超长消息在本地被拒，正常长度放行
+func TestChatCompletion_ContextPrecheck(t *testing.T) {
+	var hits int32
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"}}]}`))
+	})
+	defer server.Close()
+
+	client, err := NewClientWithConfig(DefaultConfig("test-api-key").WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	ctx := context.Background()
+
+	// 明显超长的消息触发本地预检查错误，不发出请求
+	longRequest := NewMessageBuilder().
+		AddSystemMessage("你是小说助手").
+		AddUserMessage(strings.Repeat("很长的设定", 30000)).
+		CreateChatRequest(constants.DeepSeekChat, 1000)
+	_, err = client.ChatCompletion(ctx, longRequest)
+	if !errors.Is(err, ErrContextTooLong) {
+		t.Fatalf("期望ErrContextTooLong错误，实际为%v", err)
+	}
+	_, err = client.Completion(ctx, &CompletionRequest{Model: constants.DeepSeekCoder, Prompt: strings.Repeat("x", 60000)})
+	if !errors.Is(err, ErrContextTooLong) {
+		t.Fatalf("期望ErrContextTooLong错误，实际为%v", err)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("超长请求不应发送到服务端，实际请求%d次", hits)
+	}
+
+	// 正常长度放行
+	normalRequest := NewMessageBuilder().AddUserMessage("你好").CreateChatRequest(constants.DeepSeekChat, 1000)
+	if _, err := client.ChatCompletion(ctx, normalRequest); err != nil {
+		t.Fatalf("正常请求失败: %v", err)
+	}
+	// 未收录上限的模型不做预检查
+	unknownRequest := NewMessageBuilder().AddUserMessage(strings.Repeat("很长的设定", 30000)).CreateChatRequest("custom-model", 0)
+	if _, err := client.ChatCompletion(ctx, unknownRequest); err != nil {
+		t.Fatalf("未知模型请求失败: %v", err)
+	}
+	if atomic.LoadInt32(&hits) != 2 {
+		t.Errorf("期望请求2次，实际为%d次", hits)
+	}
+}
@@ -0,0 +1,134 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"sync"
+)
+
+// usageTagKey 用量标签在上下文中的键
+type usageTagKey struct{}
+
+// WithUsageTag 在上下文中附加用量统计标签（如用户ID或业务功能名）
+func WithUsageTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, usageTagKey{}, tag)
+}
+
+// UsageTagFromContext 从上下文中读取用量统计标签，不存在时返回空字符串
+func UsageTagFromContext(ctx context.Context) string {
+	tag, _ := ctx.Value(usageTagKey{}).(string)
+	return tag
+}
+
+// UsageRecord 单次请求的用量记录
+type UsageRecord struct {
+	// Tag 调用方传入的统计标签
+	Tag string
+
+	// Model 请求使用的模型
+	Model string
+
+	// PromptTokens 提示词消耗的token数
+	PromptTokens int
+
+	// CompletionTokens 生成内容消耗的token数
+	CompletionTokens int
+}
+
+// UsageRecorder 用量上报钩子
+// 每次非流式请求成功后调用，实现方需保证并发安全
+type UsageRecorder interface {
+	Record(ctx context.Context, record UsageRecord)
+}
+
+// UsageSummary 按标签聚合的用量汇总
+type UsageSummary struct {
+	// Requests 请求次数
+	Requests int
+
+	// PromptTokens 提示词token总数
+	PromptTokens int
+
+	// CompletionTokens 生成内容token总数
+	CompletionTokens int
+}
+
+// TotalTokens 返回token总数
+func (s UsageSummary) TotalTokens() int {
+	return s.PromptTokens + s.CompletionTokens
+}
+
+// MemoryUsageRecorder 基于内存的用量记录器，支持按标签聚合查询
+type MemoryUsageRecorder struct {
+	mu        sync.RWMutex
+	summaries map[string]UsageSummary
+}
+
+// NewMemoryUsageRecorder 创建内存用量记录器
+func NewMemoryUsageRecorder() *MemoryUsageRecorder {
+	return &MemoryUsageRecorder{
+		summaries: make(map[string]UsageSummary),
+	}
+}
+
+// Record 实现UsageRecorder接口
+func (r *MemoryUsageRecorder) Record(ctx context.Context, record UsageRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summary := r.summaries[record.Tag]
+	summary.Requests++
+	summary.PromptTokens += record.PromptTokens
+	summary.CompletionTokens += record.CompletionTokens
+	r.summaries[record.Tag] = summary
+}
+
+// Summary 返回指定标签的用量汇总
+func (r *MemoryUsageRecorder) Summary(tag string) UsageSummary {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.summaries[tag]
+}
+
+// Summaries 返回所有标签的用量汇总副本
+func (r *MemoryUsageRecorder) Summaries() map[string]UsageSummary {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]UsageSummary, len(r.summaries))
+	for tag, summary := range r.summaries {
+		result[tag] = summary
+	}
+	return result
+}
+
+// recordUsage 从响应中提取用量信息并上报，未配置记录器或响应不含用量时忽略
+func (c *Client) recordUsage(ctx context.Context, requestModel string, response map[string]interface{}) {
+	if c.config.UsageRecorder == nil {
+		return
+	}
+
+	usage, ok := response["usage"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	model := requestModel
+	if m, ok := response["model"].(string); ok && m != "" {
+		model = m
+	}
+
+	record := UsageRecord{
+		Tag:   UsageTagFromContext(ctx),
+		Model: model,
+	}
+	if v, ok := usage["prompt_tokens"].(float64); ok {
+		record.PromptTokens = int(v)
+	}
+	if v, ok := usage["completion_tokens"].(float64); ok {
+		record.CompletionTokens = int(v)
+	}
+
+	c.config.UsageRecorder.Record(ctx, record)
+}
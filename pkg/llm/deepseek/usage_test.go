@@ -0,0 +1,76 @@
+// Package deepseek 提供了与DeepSeek API交互的功能，基于OpenAI官方SDK
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"novelai/pkg/constants"
+)
+
+// TestUsageRecorder_AggregateByTag 测试按标签聚合token用量
+func TestUsageRecorder_AggregateByTag(t *testing.T) {
+	server := mockServer(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		// 以消息条数模拟不同的token消耗
+		promptTokens := 10 * len(req.Messages)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"model": req.Model,
+			"choices": []interface{}{
+				map[string]interface{}{"message": map[string]interface{}{"role": "assistant", "content": "ok"}},
+			},
+			"usage": map[string]interface{}{
+				"prompt_tokens":     promptTokens,
+				"completion_tokens": 5,
+			},
+		})
+	})
+	defer server.Close()
+
+	recorder := NewMemoryUsageRecorder()
+	config := DefaultConfig("test-api-key").WithBaseURL(server.URL).WithUsageRecorder(recorder)
+	client, err := NewClientWithConfig(config)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+
+	send := func(tag string, messageCount int) {
+		messages := make([]Message, messageCount)
+		for i := range messages {
+			messages[i] = Message{Role: "user", Content: "测试"}
+		}
+		ctx := WithUsageTag(context.Background(), tag)
+		if _, err := client.ChatCompletion(ctx, &ChatRequest{Model: constants.DeepSeekChat, Messages: messages}); err != nil {
+			t.Fatalf("发送聊天请求失败: %v", err)
+		}
+	}
+
+	send("user-1", 1)
+	send("user-1", 2)
+	send("feature-outline", 3)
+	send("", 1)
+
+	summary := recorder.Summary("user-1")
+	if summary.Requests != 2 {
+		t.Errorf("期望user-1请求次数为2，实际为%d", summary.Requests)
+	}
+	if summary.PromptTokens != 30 || summary.CompletionTokens != 10 {
+		t.Errorf("user-1用量统计错误: %+v", summary)
+	}
+	if summary.TotalTokens() != 40 {
+		t.Errorf("期望user-1总token为40，实际为%d", summary.TotalTokens())
+	}
+
+	if total := recorder.Summary("feature-outline").TotalTokens(); total != 35 {
+		t.Errorf("期望feature-outline总token为35，实际为%d", total)
+	}
+
+	if len(recorder.Summaries()) != 3 {
+		t.Errorf("期望3个标签（含空标签），实际为%d", len(recorder.Summaries()))
+	}
+}
@@ -0,0 +1,25 @@
+// Package middleware 提供 JWT 统一中间件入口，仅暴露 JwtMiddleware 与 IdentityKey
+// 其余实现均隐藏于 jwt 子包，确保低耦合高内聚
+package middleware
+
+import (
+	"context"
+
+	"novelai/biz/dal/db"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// ActiveTracker 返回刷新用户最后活跃时间的中间件
+// 需注册在 JWT 中间件之后；写库由 db.TouchUserActive 节流，失败只记录日志不影响请求
+func ActiveTracker() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if userId, err := GetUserID(c); err == nil {
+			if _, err := db.TouchUserActive(userId); err != nil {
+				hlog.CtxWarnf(ctx, "刷新用户活跃时间失败: userId=%d, err=%v", userId, err)
+			}
+		}
+		c.Next(ctx)
+	}
+}
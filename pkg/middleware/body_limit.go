@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+const (
+	// ServerMaxBodySize 服务端全局请求体上限（字节）
+	// 通过 server.WithMaxRequestBodySize 配置，在读取请求体时即拒绝超大请求
+	ServerMaxBodySize = 4 << 20
+
+	// DefaultMaxBodySize 写接口请求体的默认大小上限（字节），低于服务端全局上限
+	DefaultMaxBodySize = 2 << 20
+)
+
+// MaxBodySize 返回限制请求体大小的中间件
+// 请求声明的 Content-Length 或实际请求体超过 limit 字节时直接返回 413，不再进入后续处理；
+// limit 小于等于0时使用 DefaultMaxBodySize。
+// 中间件执行时请求体已读入内存，只适合为个别路由设置低于服务端全局上限的限制，
+// 防止超大请求耗尽内存依赖服务端以 ServerMaxBodySize 配置的 WithMaxRequestBodySize
+func MaxBodySize(limit int64) app.HandlerFunc {
+	if limit <= 0 {
+		limit = DefaultMaxBodySize
+	}
+	return func(ctx context.Context, c *app.RequestContext) {
+		size := int64(c.Request.Header.ContentLength())
+		if bodySize := int64(len(c.Request.Body())); bodySize > size {
+			size = bodySize
+		}
+		if size > limit {
+			hlog.CtxWarnf(ctx, "请求体过大: path=%s, size=%d, limit=%d", c.Path(), size, limit)
+			c.AbortWithStatusJSON(consts.StatusRequestEntityTooLarge, map[string]interface{}{
+				"code":    consts.StatusRequestEntityTooLarge,
+				"message": "请求体过大",
+			})
+			return
+		}
+		c.Next(ctx)
+	}
+}
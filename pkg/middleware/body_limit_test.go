@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMaxBodySize 测试超过限制的请求被拦截返回413，正常大小放行
+func TestMaxBodySize(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	handled := 0
+	engine.POST("/api/save/create", MaxBodySize(16), func(ctx context.Context, c *app.RequestContext) {
+		handled++
+		c.String(http.StatusOK, "ok")
+	})
+
+	resp := ut.PerformRequest(engine, http.MethodPost, "/api/save/create",
+		&ut.Body{Body: strings.NewReader(`{"a":1}`), Len: 7}).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "正常大小的请求应放行")
+
+	big := strings.Repeat("x", 17)
+	resp = ut.PerformRequest(engine, http.MethodPost, "/api/save/create",
+		&ut.Body{Body: strings.NewReader(big), Len: len(big)}).Result()
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode())
+	assert.Contains(t, string(resp.Body()), "请求体过大")
+	assert.Equal(t, 1, handled, "超限请求不应进入处理函数")
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	jwtImpl "novelai/pkg/middleware/jwt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/hertz-contrib/jwt"
+)
+
+var (
+	// ErrIdentityMissing 请求上下文中没有 JWT 身份信息
+	ErrIdentityMissing = errors.New("未登录或Token无效")
+	// ErrIdentityType JWT 身份信息的类型无法解析为用户ID
+	ErrIdentityType = errors.New("无法解析用户ID（JWT类型错误）")
+	// ErrInvalidUserID 解析出的用户ID不是正数
+	ErrInvalidUserID = errors.New("用户ID无效")
+)
+
+// GetUserID 从 JWT 身份信息中解析当前用户ID
+// 登录时写入的是 int64，从令牌还原时经 JSON 解码为 float64，两者均兼容；用户ID必须大于0
+func GetUserID(c *app.RequestContext) (int64, error) {
+	idVal, ok := c.Get(IdentityKey)
+	if !ok {
+		return 0, ErrIdentityMissing
+	}
+	var userId int64
+	switch v := idVal.(type) {
+	case float64:
+		userId = int64(v)
+	case int64:
+		userId = v
+	case int:
+		userId = int64(v)
+	default:
+		return 0, ErrIdentityType
+	}
+	if userId <= 0 {
+		return 0, ErrInvalidUserID
+	}
+	return userId, nil
+}
+
+// GetUserRole 从 JWT 的 role 声明中读取当前用户角色
+// 未登录或令牌未携带角色时返回空字符串，调用方按普通用户处理
+func GetUserRole(ctx context.Context, c *app.RequestContext) string {
+	role, _ := jwt.ExtractClaims(ctx, c)[jwtImpl.RoleKey].(string)
+	return role
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetUserID 测试各种类型的身份值解析
+func TestGetUserID(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   interface{}
+		set     bool
+		want    int64
+		wantErr error
+	}{
+		{name: "float64", value: float64(42), set: true, want: 42},
+		{name: "int64", value: int64(7), set: true, want: 7},
+		{name: "int", value: 3, set: true, want: 3},
+		{name: "未设置", wantErr: ErrIdentityMissing},
+		{name: "字符串", value: "42", set: true, wantErr: ErrIdentityType},
+		{name: "nil", value: nil, set: true, wantErr: ErrIdentityType},
+		{name: "零值", value: float64(0), set: true, wantErr: ErrInvalidUserID},
+		{name: "负数", value: int64(-1), set: true, wantErr: ErrInvalidUserID},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := app.NewContext(0)
+			if tc.set {
+				c.Set(IdentityKey, tc.value)
+			}
+			got, err := GetUserID(c)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
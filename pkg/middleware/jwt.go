@@ -10,6 +10,7 @@ import (
 	jwtImpl "novelai/pkg/middleware/jwt"
 	"time"
 
+	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/hertz-contrib/jwt"
 )
 
@@ -27,12 +28,20 @@ func JwtMiddleware() (*jwt.HertzJWTMiddleware, error) {
 		IdentityKey:     jwtImpl.IdentityKey,
 		PayloadFunc:     jwtImpl.PayloadFunc(),
 		Authenticator:   jwtImpl.Authenticator(),
+		Authorizator:    jwtImpl.Authorizator(),
 		Unauthorized:    jwtImpl.Unauthorized(),
 		LoginResponse:   jwtImpl.LoginResponse(),
 		RefreshResponse: jwtImpl.RefreshResponse(),
+		LogoutResponse:  jwtImpl.LogoutResponse(),
 	})
 }
 
+// RefreshHandler 返回刷新令牌接口，已登出或被吊销的令牌不能刷新
+// 用于替代 jwtMw.RefreshHandler 注册在 JWT 中间件之外的刷新路由
+func RefreshHandler(mw *jwt.HertzJWTMiddleware) app.HandlerFunc {
+	return jwtImpl.RefreshHandler(mw)
+}
+
 // 使用说明：
 // 1. 在路由注册时：
 //    jwtMw, _ := middleware.JwtMiddleware()
@@ -20,7 +20,7 @@ import (
 // 用于 hertz-contrib/jwt 中间件配置，负责登录认证逻辑
 // 返回一个闭包，签名为 func(ctx context.Context, c *app.RequestContext) (interface{}, error)
 // ctx: 上下文，c: hertz 请求上下文
-// 返回值：认证通过时返回用户相关数据（如 user_id），否则返回错误
+// 返回值：认证通过时返回用户相关数据（user_id 与 role），否则返回错误
 func Authenticator() func(ctx context.Context, c *app.RequestContext) (interface{}, error) {
 	return authenticator
 }
@@ -29,7 +29,7 @@ func Authenticator() func(ctx context.Context, c *app.RequestContext) (interface
 // 1. 解析请求体，获取用户名和密码
 // 2. 对密码进行 MD5 哈希
 // 3. 调用 db.VerifyUser 校验用户名密码
-// 4. 校验通过返回用户 user_id，失败返回错误
+// 4. 校验通过后查询用户角色，返回 user_id 与 role 写入令牌；失败返回错误
 func authenticator(ctx context.Context, c *app.RequestContext) (interface{}, error) {
 	var req LoginRequest
 	if err := c.Bind(&req); err != nil {
@@ -40,8 +40,12 @@ func authenticator(ctx context.Context, c *app.RequestContext) (interface{}, err
 	if err != nil {
 		return nil, jwt.ErrFailedAuthentication
 	}
+	user, err := db.QueryUserByID(userId)
+	if err != nil {
+		return nil, jwt.ErrFailedAuthentication
+	}
 	c.Set(IdentityKey, userId)
-	return map[string]interface{}{IdentityKey: userId}, nil
+	return map[string]interface{}{IdentityKey: userId, RoleKey: user.GetRole()}, nil
 }
 
 
@@ -56,13 +60,18 @@ func PayloadFunc() func(data interface{}) jwt.MapClaims {
 
 // payloadFunc JWT claims 生成实现
 // 1. 若 data 为 map[string]interface{}，则提取 IdentityKey 和 role 字段
-// 2. 返回 jwt.MapClaims，供 JWT token 使用
+// 2. 写入用户当前的令牌版本，吊销后旧令牌即失效
+// 3. 返回 jwt.MapClaims，供 JWT token 使用
 func payloadFunc(data interface{}) jwt.MapClaims {
 	if v, ok := data.(map[string]interface{}); ok {
-		return jwt.MapClaims{
+		claims := jwt.MapClaims{
 			IdentityKey: v[IdentityKey],
-			"role":      v["role"],
+			RoleKey:     v[RoleKey],
+		}
+		if userID, ok := claimInt64(v[IdentityKey]); ok {
+			claims[TokenVersionKey] = UserTokenVersion(userID)
 		}
+		return claims
 	}
 	return jwt.MapClaims{}
 }
@@ -0,0 +1,148 @@
+// blacklist.go
+// JWT 令牌黑名单，用于让已登出的令牌在过期前失效
+package jwt
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"novelai/pkg/constants"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/hertz-contrib/jwt"
+)
+
+// TokenBlacklist 令牌黑名单存储
+// 默认实现为进程内存，多实例部署时可替换为 Redis 等共享存储
+type TokenBlacklist interface {
+	// Add 将令牌加入黑名单，expireAt 之后自动移除
+	Add(token string, expireAt time.Time)
+	// Contains 判断令牌是否在黑名单中
+	Contains(token string) bool
+}
+
+// memoryBlacklist 基于内存的令牌黑名单
+type memoryBlacklist struct {
+	mu      sync.Mutex
+	entries map[string]time.Time // 令牌签名到过期时间的映射
+	now     func() time.Time
+}
+
+// NewMemoryBlacklist 创建基于内存的令牌黑名单
+func NewMemoryBlacklist() TokenBlacklist {
+	return &memoryBlacklist{
+		entries: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Add 将令牌加入黑名单，并顺带清理已过期的条目
+func (b *memoryBlacklist) Add(token string, expireAt time.Time) {
+	key := tokenKey(token)
+	if key == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	for k, exp := range b.entries {
+		if !now.Before(exp) {
+			delete(b.entries, k)
+		}
+	}
+	if now.Before(expireAt) {
+		b.entries[key] = expireAt
+	}
+}
+
+// Contains 判断令牌是否在黑名单中，过期条目视为不存在
+func (b *memoryBlacklist) Contains(token string) bool {
+	key := tokenKey(token)
+	if key == "" {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	exp, ok := b.entries[key]
+	if !ok {
+		return false
+	}
+	if !b.now().Before(exp) {
+		delete(b.entries, key)
+		return false
+	}
+	return true
+}
+
+// tokenKey 取令牌签名段作为黑名单键
+// 签发的令牌不带 jti，签名可唯一标识一个令牌且比完整令牌短
+func tokenKey(token string) string {
+	if i := strings.LastIndex(token, "."); i >= 0 {
+		return token[i+1:]
+	}
+	return token
+}
+
+// Blacklist 全局令牌黑名单
+var Blacklist = NewMemoryBlacklist()
+
+// Authorizator 返回 JWT Authorizator 实现
+// 用于 hertz-contrib/jwt 中间件配置，拒绝已登出（在黑名单中）或已被吊销的令牌
+func Authorizator() func(data interface{}, ctx context.Context, c *app.RequestContext) bool {
+	return authorizator
+}
+
+// authorizator 令牌授权实现
+// 1. 从请求上下文取出当前令牌
+// 2. 令牌在黑名单中时拒绝访问
+// 3. 令牌版本低于用户当前版本（已通过 RevokeUserTokens 吊销）时拒绝访问
+func authorizator(data interface{}, ctx context.Context, c *app.RequestContext) bool {
+	if Blacklist.Contains(jwt.GetToken(ctx, c)) {
+		return false
+	}
+	return tokenVersionValid(jwt.ExtractClaims(ctx, c))
+}
+
+// LogoutResponse 返回 JWT LogoutResponse 实现
+// 用于 hertz-contrib/jwt 中间件配置，登出时将当前令牌加入黑名单直到其过期
+// 返回一个闭包，签名为 func(ctx context.Context, c *app.RequestContext, code int)
+func LogoutResponse() func(ctx context.Context, c *app.RequestContext, code int) {
+	return logoutResponse
+}
+
+// logoutResponse 登出响应实现
+// 1. 读取当前令牌与其 exp、orig_iat 声明
+// 2. 将令牌加入黑名单，保留到令牌无法再刷新为止（orig_iat + MaxRefresh，且不早于 exp）
+// 3. 返回 JSON 格式的成功信息
+func logoutResponse(ctx context.Context, c *app.RequestContext, code int) {
+	token := jwt.GetToken(ctx, c)
+	if token != "" {
+		expireAt := blacklistExpireAt(jwt.ExtractClaims(ctx, c))
+		Blacklist.Add(token, expireAt)
+		hlog.CtxInfof(ctx, "令牌已加入黑名单，过期时间: %s", expireAt.Format(time.RFC3339))
+	}
+	c.JSON(constants.StatusOK, map[string]interface{}{
+		"code":    constants.StatusOK,
+		"message": "登出成功",
+	})
+}
+
+// blacklistExpireAt 计算登出令牌在黑名单中的保留截止时间
+// 过期的令牌在 orig_iat + MaxRefresh 之前仍可刷新，黑名单需保留到那时
+func blacklistExpireAt(claims jwt.MapClaims) time.Time {
+	expireAt := time.Now().Add(time.Hour * JwtMaxRefresh)
+	if origIat, ok := claims["orig_iat"].(float64); ok {
+		expireAt = time.Unix(int64(origIat), 0).Add(time.Hour * JwtMaxRefresh)
+	}
+	if exp, ok := claims["exp"].(float64); ok && time.Unix(int64(exp), 0).After(expireAt) {
+		expireAt = time.Unix(int64(exp), 0)
+	}
+	return expireAt
+}
@@ -0,0 +1,38 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hertz-contrib/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMemoryBlacklistExpire 测试黑名单条目在令牌过期后自动失效
+func TestMemoryBlacklistExpire(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	b := &memoryBlacklist{entries: make(map[string]time.Time), now: func() time.Time { return now }}
+
+	b.Add("header.payload.sig-a", now.Add(time.Minute))
+	b.Add("header.payload.sig-b", now.Add(-time.Minute))
+	assert.True(t, b.Contains("header.payload.sig-a"))
+	assert.False(t, b.Contains("header.payload.sig-b"), "已过期的令牌无需加入黑名单")
+	assert.False(t, b.Contains("header.payload.sig-c"))
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, b.Contains("header.payload.sig-a"), "令牌过期后应从黑名单移除")
+	assert.Empty(t, b.entries)
+}
+
+// TestBlacklistExpireAt 测试黑名单保留到令牌无法再刷新为止
+func TestBlacklistExpireAt(t *testing.T) {
+	origIat := time.Unix(1700000000, 0)
+	claims := jwt.MapClaims{
+		"orig_iat": float64(origIat.Unix()),
+		"exp":      float64(origIat.Add(time.Hour).Unix()),
+	}
+	assert.Equal(t, origIat.Add(time.Hour*JwtMaxRefresh), blacklistExpireAt(claims), "应保留到 orig_iat + MaxRefresh")
+
+	claims["exp"] = float64(origIat.Add(time.Hour * (JwtMaxRefresh + 1)).Unix())
+	assert.Equal(t, origIat.Add(time.Hour*(JwtMaxRefresh+1)), blacklistExpireAt(claims), "不早于令牌过期时间")
+}
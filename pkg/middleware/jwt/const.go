@@ -8,4 +8,5 @@ const (
 	JwtTimeout    = 24 // 单位：小时
 	JwtMaxRefresh = 24 // 单位：小时
 	IdentityKey   = "user_id" // 必须大写导出，供外部访问
+	RoleKey       = "role"    // 令牌中用户角色的 claim 名称
 )
@@ -0,0 +1,34 @@
+// refresh.go
+// 令牌刷新接口，刷新前拒绝已失效的令牌
+package jwt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/hertz-contrib/jwt"
+)
+
+// ErrTokenRevoked 令牌已登出或被吊销
+var ErrTokenRevoked = errors.New("令牌已失效")
+
+// RefreshHandler 返回带失效检查的刷新接口
+// hertz-contrib/jwt 的 RefreshHandler 不经过 Authorizator，已登出的令牌在 MaxRefresh 内仍能换到新令牌，
+// 刷新还会原样复制旧令牌的令牌版本，因此刷新前先检查黑名单与令牌版本，再交给中间件原有的刷新流程
+func RefreshHandler(mw *jwt.HertzJWTMiddleware) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		// 过期但仍可刷新的令牌解析时会返回错误，此时 token 依然可用
+		if token, _ := mw.ParseToken(ctx, c); token != nil && refreshRevoked(token.Raw, jwt.ExtractClaimsFromToken(token)) {
+			mw.Unauthorized(ctx, c, http.StatusUnauthorized, ErrTokenRevoked.Error())
+			return
+		}
+		mw.RefreshHandler(ctx, c)
+	}
+}
+
+// refreshRevoked 判断待刷新的令牌是否已登出或已通过 RevokeUserTokens 吊销
+func refreshRevoked(token string, claims jwt.MapClaims) bool {
+	return Blacklist.Contains(token) || !tokenVersionValid(claims)
+}
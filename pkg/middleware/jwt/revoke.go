@@ -0,0 +1,54 @@
+// revoke.go
+// 按用户吊销令牌，用于重置密码等场景让该用户已签发的全部令牌在过期前失效
+package jwt
+
+import (
+	"sync"
+)
+
+// TokenVersionKey 令牌中记录用户令牌版本的 claim 名称
+const TokenVersionKey = "token_version"
+
+// userTokenVersions 用户令牌版本存储
+// 每次吊销版本号加一，版本号低于当前版本的令牌被拒绝；默认实现为进程内存，与 Blacklist 一致
+var userTokenVersions = struct {
+	sync.Mutex
+	versions map[int64]int64
+}{versions: make(map[int64]int64)}
+
+// RevokeUserTokens 吊销用户已签发的全部令牌，之后重新登录获得的令牌不受影响
+// 吊销只在当前进程内生效，进程重启后旧令牌会重新有效，多实例之间也不共享
+func RevokeUserTokens(userID int64) {
+	userTokenVersions.Lock()
+	defer userTokenVersions.Unlock()
+	userTokenVersions.versions[userID]++
+}
+
+// UserTokenVersion 返回用户当前的令牌版本，从未吊销过时为0
+func UserTokenVersion(userID int64) int64 {
+	userTokenVersions.Lock()
+	defer userTokenVersions.Unlock()
+	return userTokenVersions.versions[userID]
+}
+
+// tokenVersionValid 判断令牌 claims 中的版本是否仍有效
+// 缺少版本的旧令牌按版本0处理
+func tokenVersionValid(claims map[string]interface{}) bool {
+	userID, ok := claimInt64(claims[IdentityKey])
+	if !ok {
+		return true
+	}
+	version, _ := claimInt64(claims[TokenVersionKey])
+	return version >= UserTokenVersion(userID)
+}
+
+// claimInt64 读取整数 claim，解析后的 JSON 数字为 float64
+func claimInt64(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	jwtImpl "novelai/pkg/middleware/jwt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/hertz-contrib/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogoutRevokesToken 测试登出后同一令牌访问受保护接口被拒
+func TestLogoutRevokesToken(t *testing.T) {
+	jwtMw, err := JwtMiddleware()
+	require.NoError(t, err)
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	group := engine.Group("/api", jwtMw.MiddlewareFunc())
+	group.GET("/info", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	})
+	group.POST("/logout", jwtMw.LogoutHandler)
+
+	token, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: int64(1)})
+	require.NoError(t, err)
+	other, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: int64(2)})
+	require.NoError(t, err)
+	auth := func(token string) ut.Header {
+		return ut.Header{Key: "Authorization", Value: "Bearer " + token}
+	}
+
+	resp := ut.PerformRequest(engine, http.MethodGet, "/api/info", nil, auth(token)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "登出前令牌应可用")
+
+	resp = ut.PerformRequest(engine, http.MethodPost, "/api/logout", nil, auth(token)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/info", nil, auth(token)).Result()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode(), "登出后令牌应被拒绝")
+
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/info", nil, auth(other)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "其他令牌不受影响")
+}
+
+// TestRevokeUserTokens 测试吊销用户令牌后旧令牌被拒，重新签发的令牌可用
+func TestRevokeUserTokens(t *testing.T) {
+	jwtMw, err := JwtMiddleware()
+	require.NoError(t, err)
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	group := engine.Group("/api", jwtMw.MiddlewareFunc())
+	group.GET("/info", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	const userID = int64(42)
+	oldToken, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: userID})
+	require.NoError(t, err)
+	other, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: userID + 1})
+	require.NoError(t, err)
+	auth := func(token string) ut.Header {
+		return ut.Header{Key: "Authorization", Value: "Bearer " + token}
+	}
+
+	resp := ut.PerformRequest(engine, http.MethodGet, "/api/info", nil, auth(oldToken)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "吊销前令牌应可用")
+
+	jwtImpl.RevokeUserTokens(userID)
+
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/info", nil, auth(oldToken)).Result()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode(), "吊销后旧令牌应被拒绝")
+
+	newToken, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: userID})
+	require.NoError(t, err)
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/info", nil, auth(newToken)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "重新登录后的令牌应可用")
+
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/info", nil, auth(other)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "其他用户的令牌不受影响")
+}
+
+// newRefreshTestEngine 创建带刷新、登出与受保护接口的测试路由，刷新接口与线上一样注册在 JWT 中间件之外
+func newRefreshTestEngine(t *testing.T) (*route.Engine, *jwt.HertzJWTMiddleware) {
+	jwtMw, err := JwtMiddleware()
+	require.NoError(t, err)
+
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.GET("/api/refresh", RefreshHandler(jwtMw))
+	group := engine.Group("/api", jwtMw.MiddlewareFunc())
+	group.GET("/info", func(ctx context.Context, c *app.RequestContext) {
+		c.String(http.StatusOK, "ok")
+	})
+	group.POST("/logout", jwtMw.LogoutHandler)
+	return engine, jwtMw
+}
+
+// TestLogoutBlocksRefresh 测试登出后的令牌不能再换取新令牌
+func TestLogoutBlocksRefresh(t *testing.T) {
+	engine, jwtMw := newRefreshTestEngine(t)
+	token, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: int64(7)})
+	require.NoError(t, err)
+	other, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: int64(8)})
+	require.NoError(t, err)
+	auth := func(token string) ut.Header {
+		return ut.Header{Key: "Authorization", Value: "Bearer " + token}
+	}
+
+	resp := ut.PerformRequest(engine, http.MethodGet, "/api/refresh", nil, auth(token)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "登出前令牌可以刷新")
+
+	resp = ut.PerformRequest(engine, http.MethodPost, "/api/logout", nil, auth(token)).Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/refresh", nil, auth(token)).Result()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode(), "登出后的令牌不能刷新")
+
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/refresh", nil, auth(other)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "其他令牌不受影响")
+}
+
+// TestRevokedTokenRefresh 测试吊销（如管理员重置密码）后的旧令牌不能再换取新令牌
+func TestRevokedTokenRefresh(t *testing.T) {
+	engine, jwtMw := newRefreshTestEngine(t)
+	const userID = int64(43)
+	oldToken, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: userID})
+	require.NoError(t, err)
+	auth := func(token string) ut.Header {
+		return ut.Header{Key: "Authorization", Value: "Bearer " + token}
+	}
+
+	jwtImpl.RevokeUserTokens(userID)
+
+	resp := ut.PerformRequest(engine, http.MethodGet, "/api/refresh", nil, auth(oldToken)).Result()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode(), "吊销后的旧令牌不能刷新")
+
+	newToken, _, err := jwtMw.TokenGenerator(map[string]interface{}{jwtImpl.IdentityKey: userID})
+	require.NoError(t, err)
+	resp = ut.PerformRequest(engine, http.MethodGet, "/api/refresh", nil, auth(newToken)).Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode(), "重新登录后的令牌可以刷新")
+}
@@ -0,0 +1,32 @@
+package crypto
+
+import (
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// HashPasswordBcrypt 生成 bcrypt 密码哈希
+// 参数: password 待存储的密码（登录链路中为 HashPassword 的结果）
+// 返回: bcrypt 哈希字符串
+func HashPasswordBcrypt(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// IsBcryptHash 判断保存的哈希是否为 bcrypt 格式
+func IsBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2")
+}
+
+// VerifyPasswordBcrypt 验证密码与 bcrypt 哈希是否一致
+// 返回: 验证通过返回nil，否则返回ErrPasswordMismatch
+func VerifyPasswordBcrypt(password, hash string) error {
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
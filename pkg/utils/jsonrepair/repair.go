@@ -0,0 +1,100 @@
+// Package jsonrepair 提供模型输出中 JSON 的宽松修复与提取
+package jsonrepair
+
+import (
+	"strings"
+)
+
+// Repair 宽松修复模型输出中常见的 JSON 小毛病
+// 处理范围：
+//   - 剥离首个 { 或 [ 之前以及顶层结构闭合之后的文本
+//   - 去掉对象、数组末尾多余的逗号
+//   - 单引号字符串转为双引号字符串
+//   - 补全未闭合的字符串与括号
+//
+// 只做语法层面的修补，不保证语义正确；输入中没有 JSON 结构时原样返回
+func Repair(input string) string {
+	start := strings.IndexAny(input, "{[")
+	if start == -1 {
+		return input
+	}
+
+	out := make([]byte, 0, len(input)+8)
+	var stack []byte // 尚未闭合的括号
+	var quote byte   // 当前字符串的引号，0 表示不在字符串中
+	escaped := false // 上一个字符是否为转义符
+
+	for i := start; i < len(input); i++ {
+		c := input[i]
+
+		if quote != 0 {
+			switch {
+			case escaped:
+				escaped = false
+				// \' 不是合法的 JSON 转义，去掉反斜杠
+				if c == '\'' {
+					out = out[:len(out)-1]
+				}
+				out = append(out, c)
+			case c == '\\':
+				escaped = true
+				out = append(out, c)
+			case c == quote:
+				quote = 0
+				out = append(out, '"')
+			case c == '"':
+				// 单引号字符串内部的双引号需要转义
+				out = append(out, '\\', '"')
+			default:
+				out = append(out, c)
+			}
+			continue
+		}
+
+		switch c {
+		case '"', '\'':
+			quote = c
+			out = append(out, '"')
+		case '{', '[':
+			stack = append(stack, c)
+			out = append(out, c)
+		case '}', ']':
+			out = trimTrailingComma(out)
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			out = append(out, c)
+			if len(stack) == 0 {
+				return string(out)
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+
+	// 输出被截断：补全字符串与括号
+	if quote != 0 {
+		if escaped {
+			out = out[:len(out)-1]
+		}
+		out = append(out, '"')
+	}
+	out = trimTrailingComma(out)
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			out = append(out, '}')
+		} else {
+			out = append(out, ']')
+		}
+	}
+	return string(out)
+}
+
+// trimTrailingComma 去掉已输出内容末尾的空白与多余逗号
+func trimTrailingComma(out []byte) []byte {
+	trimmed := strings.TrimRight(string(out), " \t\r\n")
+	if strings.HasSuffix(trimmed, ",") {
+		return out[:len(trimmed)-1]
+	}
+	return out
+}
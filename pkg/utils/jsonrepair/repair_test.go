@@ -0,0 +1,82 @@
+package jsonrepair
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStruct 测试用结构
+type testStruct struct {
+	Name    string `json:"name"`
+	Age     int    `json:"age"`
+	IsValid bool   `json:"is_valid"`
+}
+
+// TestRepair 测试常见小毛病的 JSON 能被修复并成功解析
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  testStruct
+	}{
+		{
+			name:  "对象尾逗号",
+			input: `{"name": "测试", "age": 18, "is_valid": true,}`,
+			want:  testStruct{Name: "测试", Age: 18, IsValid: true},
+		},
+		{
+			name:  "单引号",
+			input: `{'name': '测试', 'age': 18, 'is_valid': true}`,
+			want:  testStruct{Name: "测试", Age: 18, IsValid: true},
+		},
+		{
+			name:  "单引号内含双引号与转义单引号",
+			input: `{'name': '他说"你好"，It\'s ok', 'age': 1}`,
+			want:  testStruct{Name: `他说"你好"，It's ok`, Age: 1},
+		},
+		{
+			name:  "未闭合的对象",
+			input: `{"name": "测试", "age": 18`,
+			want:  testStruct{Name: "测试", Age: 18},
+		},
+		{
+			name:  "未闭合的字符串",
+			input: `{"age": 18, "name": "截断的名`,
+			want:  testStruct{Name: "截断的名", Age: 18},
+		},
+		{
+			name:  "前后有说明文字且带尾逗号",
+			input: "结果如下：\n```json\n{\"name\": \"测试\", \"age\": 3,\n}\n```\n如有需要请告诉我。",
+			want:  testStruct{Name: "测试", Age: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testStruct
+			repaired := Repair(tt.input)
+			require.NoError(t, json.Unmarshal([]byte(repaired), &got), "修复结果: %s", repaired)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("数组尾逗号与未闭合数组", func(t *testing.T) {
+		var got []map[string]interface{}
+		repaired := Repair(`[{"name": "甲",}, {"name": "乙"},`)
+		require.NoError(t, json.Unmarshal([]byte(repaired), &got), "修复结果: %s", repaired)
+		require.Len(t, got, 2)
+		assert.Equal(t, "乙", got[1]["name"])
+	})
+
+	t.Run("合法JSON保持不变", func(t *testing.T) {
+		valid := `{"name": "测试, 带逗号}", "list": [1, 2]}`
+		assert.Equal(t, valid, Repair(valid))
+	})
+
+	t.Run("不含JSON的内容原样返回", func(t *testing.T) {
+		assert.Equal(t, "没有JSON", Repair("没有JSON"))
+	})
+}
@@ -0,0 +1,65 @@
+// Package redact 提供生成文本的敏感信息脱敏，脱敏规则在此集中管理
+package redact
+
+import "regexp"
+
+// Rule 一条脱敏规则，匹配 Pattern 的内容替换为 Replacement
+type Rule struct {
+	Name        string         // 规则名称
+	Pattern     *regexp.Regexp // 匹配模式
+	Replacement string         // 替换后的占位文本
+}
+
+// DefaultRules 默认脱敏规则，按顺序依次应用
+// 身份证号需在银行卡号之前匹配，避免18位身份证号被识别为银行卡号
+var DefaultRules = []Rule{
+	{
+		Name:        "email",
+		Pattern:     regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Replacement: "[邮箱]",
+	},
+	{
+		Name:        "id_card",
+		Pattern:     regexp.MustCompile(`\b[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]\b`),
+		Replacement: "[身份证号]",
+	},
+	{
+		Name:        "bank_card",
+		Pattern:     regexp.MustCompile(`\b\d{16,19}\b`),
+		Replacement: "[银行卡号]",
+	},
+	{
+		Name:        "phone",
+		Pattern:     regexp.MustCompile(`(?:\+86[- ]?|\b86[- ]?|\b)1[3-9]\d{9}\b`),
+		Replacement: "[手机号]",
+	},
+}
+
+// Redactor 按规则列表脱敏的脱敏器
+type Redactor struct {
+	rules []Rule
+}
+
+// NewRedactor 创建脱敏器，未指定规则时使用 DefaultRules
+func NewRedactor(rules ...Rule) *Redactor {
+	if len(rules) == 0 {
+		rules = DefaultRules
+	}
+	return &Redactor{rules: rules}
+}
+
+// Redact 依次应用各规则，将敏感信息替换为占位文本
+func (r *Redactor) Redact(text string) string {
+	for _, rule := range r.rules {
+		text = rule.Pattern.ReplaceAllString(text, rule.Replacement)
+	}
+	return text
+}
+
+// defaultRedactor 使用默认规则的脱敏器
+var defaultRedactor = NewRedactor()
+
+// Redact 使用默认规则对文本脱敏
+func Redact(text string) string {
+	return defaultRedactor.Redact(text)
+}
@@ -0,0 +1,48 @@
+package redact
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRedact 测试各类敏感模式被替换为占位文本
+func TestRedact(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"手机号", "主角的电话是13812345678，请勿外传", "主角的电话是[手机号]，请勿外传"},
+		{"带区号手机号", "联系方式+86 13912345678", "联系方式[手机号]"},
+		{"邮箱", "来信请寄 zhang.san@example.com 谢谢", "来信请寄 [邮箱] 谢谢"},
+		{"身份证号", "身份证110105199003071234记录在案", "身份证[身份证号]记录在案"},
+		{"末位为X的身份证号", "证件号11010519900307123X", "证件号[身份证号]"},
+		{"银行卡号", "卡号6222021234567890123已冻结", "卡号[银行卡号]已冻结"},
+		{"多种混合", "邮箱a@b.cn，手机15000000000", "邮箱[邮箱]，手机[手机号]"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, Redact(c.in))
+		})
+	}
+}
+
+// TestRedactKeepsNormalText 测试正常文本保持不变
+func TestRedactKeepsNormalText(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"帝国历1024年，北境要塞陷落。",
+		"他在第3章拿到了编号12345的钥匙",
+		"版本号 v1.2.3，价格 199 元",
+	} {
+		assert.Equal(t, text, Redact(text))
+	}
+}
+
+// TestNewRedactorCustomRules 测试自定义规则
+func TestNewRedactorCustomRules(t *testing.T) {
+	r := NewRedactor(Rule{Name: "qq", Pattern: regexp.MustCompile(`QQ\d{5,11}`), Replacement: "[QQ]"})
+	assert.Equal(t, "加[QQ]，电话13812345678", r.Redact("加QQ123456，电话13812345678"), "自定义规则不应包含默认规则")
+}
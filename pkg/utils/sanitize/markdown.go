@@ -0,0 +1,94 @@
+// Package sanitize 提供用户可编辑的 markdown 描述文本的校验与清洗
+package sanitize
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxDescriptionLength 描述字段的默认长度上限（字符数）
+	DefaultMaxDescriptionLength = 5000
+
+	// DefaultMaxWordLength 单个“单词”（连续的非空白、非汉字字符）的默认长度上限
+	DefaultMaxWordLength = 200
+)
+
+// ErrContentTooLong 清洗后的内容超出长度上限
+var ErrContentTooLong = errors.New("内容超出长度上限")
+
+// dangerousBlockPattern 连同内容一起删除的危险标签，如 <script>...</script>
+var dangerousBlockPattern = regexp.MustCompile(`(?is)<(script|style|iframe|object|embed)\b[^>]*>.*?</\s*(?:script|style|iframe|object|embed)\s*>`)
+
+// dangerousTagPattern 未闭合或自闭合的危险标签
+var dangerousTagPattern = regexp.MustCompile(`(?i)</?\s*(?:script|style|iframe|object|embed|link|meta|base)\b[^>]*>`)
+
+// eventHandlerPattern HTML 标签中的内联事件属性，如 onclick="..."
+var eventHandlerPattern = regexp.MustCompile(`(?i)(<[^>]*?)\s+on[a-z]+\s*=\s*(?:"[^"]*"|'[^']*'|[^\s>]+)`)
+
+// scriptURLPattern 链接位置上可执行脚本的协议，如 [x](javascript:...)、href="javascript:..."
+// 只匹配 markdown 链接、自动链接、引用式链接定义与 href/src 等属性值开头带冒号的协议，正文中的 JavaScript 等字样不受影响
+var scriptURLPattern = regexp.MustCompile(`(?i)(\(\s*<?|<|\]:\s*<?|\b(?:href|src|action|formaction)\s*=\s*["']?)\s*(?:javascript|vbscript|data\s*:\s*text/html)\s*:`)
+
+// SanitizeMarkdown 清洗 markdown 文本中的危险内容，保留正常的 markdown 结构
+// 删除 script/style/iframe 等标签及其内容、内联事件属性与 javascript: 等脚本链接，
+// 超过 maxWordLength 的单词被截断；maxWordLength 小于等于0时使用 DefaultMaxWordLength
+func SanitizeMarkdown(s string, maxWordLength int) string {
+	if maxWordLength <= 0 {
+		maxWordLength = DefaultMaxWordLength
+	}
+	// 删除一处后残余部分可能重新拼出危险内容（如 <scr<script>ipt>），反复清洗直到不再变化
+	for {
+		cleaned := sanitizeOnce(s)
+		if cleaned == s {
+			break
+		}
+		s = cleaned
+	}
+	return truncateLongWords(s, maxWordLength)
+}
+
+// sanitizeOnce 执行一轮危险内容清洗
+func sanitizeOnce(s string) string {
+	s = dangerousBlockPattern.ReplaceAllString(s, "")
+	s = dangerousTagPattern.ReplaceAllString(s, "")
+	s = eventHandlerPattern.ReplaceAllString(s, "$1")
+	return scriptURLPattern.ReplaceAllString(s, "$1")
+}
+
+// ValidateDescription 清洗描述文本并校验长度
+// 返回清洗后的文本；清洗后超过 maxLength 个字符时返回 ErrContentTooLong，
+// maxLength 小于等于0时使用 DefaultMaxDescriptionLength
+func ValidateDescription(s string, maxLength int) (string, error) {
+	if maxLength <= 0 {
+		maxLength = DefaultMaxDescriptionLength
+	}
+	s = strings.TrimSpace(SanitizeMarkdown(s, DefaultMaxWordLength))
+	if n := utf8.RuneCountInString(s); n > maxLength {
+		return "", fmt.Errorf("%w: %d > %d", ErrContentTooLong, n, maxLength)
+	}
+	return s, nil
+}
+
+// truncateLongWords 截断超长单词，单词为连续的非空白、非汉字字符
+func truncateLongWords(s string, maxWordLength int) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	wordLength := 0
+	for _, r := range s {
+		if unicode.IsSpace(r) || unicode.Is(unicode.Han, r) {
+			wordLength = 0
+			b.WriteRune(r)
+			continue
+		}
+		wordLength++
+		if wordLength <= maxWordLength {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,61 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSanitizeMarkdown 测试危险内容被清洗
+func TestSanitizeMarkdown(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"script标签", "北境要塞<script>alert(1)</script>陷落", "北境要塞陷落"},
+		{"大小写混合的script", "前<SCRIPT type=\"text/javascript\">\nsteal()\n</Script >后", "前后"},
+		{"未闭合的script", "设定<script src=\"x.js\">", "设定"},
+		{"iframe", "<iframe src=\"https://evil\"></iframe>地图", "地图"},
+		{"事件属性", `<img src="map.png" onerror="alert(1)">`, `<img src="map.png">`},
+		{"javascript链接", "[点击](javascript:alert(1))", "[点击](alert(1))"},
+		{"属性中的javascript链接", `<a href="JavaScript:steal()">地图</a>`, `<a href="steal()">地图</a>`},
+		{"嵌套拼接的javascript链接", "[x](javascript:javascript:alert(1))", "[x](alert(1))"},
+		{"拆开的javascript链接不会被拼回", "[x](jajavascript:vascript:alert(1))", "[x](jajavascript:vascript:alert(1))"},
+		{"嵌套拼接的script标签", "前<scr<script>ipt>后", "前后"},
+		{"正文中的JavaScript", "我在学习 JavaScript 编程，vbscript 也会一点", "我在学习 JavaScript 编程，vbscript 也会一点"},
+		{"超长单词", "密钥 " + strings.Repeat("a", 250) + " 结束", "密钥 " + strings.Repeat("a", 200) + " 结束"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, SanitizeMarkdown(c.in, 0))
+		})
+	}
+}
+
+// TestSanitizeMarkdownKeepsNormalMarkdown 测试正常 markdown 原样通过
+func TestSanitizeMarkdownKeepsNormalMarkdown(t *testing.T) {
+	text := "# 星海帝国\n\n- **首都**：天枢城\n- *气候*：终年严寒\n\n> 帝国历1024年，北境要塞陷落。\n\n[地图](https://example.com/map.png)\n\n`魔法阵` 与 <b>禁咒</b>"
+	assert.Equal(t, text, SanitizeMarkdown(text, 0))
+
+	// 汉字不计入单词长度，长段中文不会被截断
+	long := strings.Repeat("北境要塞的风雪", 100)
+	assert.Equal(t, long, SanitizeMarkdown(long, 10))
+}
+
+// TestValidateDescription 测试超长被拒、危险内容被清洗、正常内容通过
+func TestValidateDescription(t *testing.T) {
+	_, err := ValidateDescription(strings.Repeat("长", 101), 100)
+	assert.ErrorIs(t, err, ErrContentTooLong)
+
+	got, err := ValidateDescription("  魔法体系<script>x()</script>以星辰为源  ", 100)
+	require.NoError(t, err)
+	assert.Equal(t, "魔法体系以星辰为源", got)
+
+	// 清洗后长度合规即通过
+	got, err = ValidateDescription(strings.Repeat("长", 100)+"<script>"+strings.Repeat("x", 50)+"</script>", 100)
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("长", 100), got)
+}
@@ -0,0 +1,122 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 双语世界观的语言标记
+const (
+	LanguageZh = "zh" // 中文
+	LanguageEn = "en" // 英文
+)
+
+// bilingualSourcePromptTemplate 中文世界观生成模板，参数为主题
+const bilingualSourcePromptTemplate = `你是一名小说世界观设计师，请为主题为“%s”的小说设计一个世界观，约200字。
+请严格按照如下JSON格式输出：{"name": "", "description": "", "tag": ""}，tag 为英文逗号分隔的标签。不要输出除JSON以外的内容。`
+
+// bilingualLocalizePromptTemplate 英文本地化模板，参数依次为名称、描述和标签
+const bilingualLocalizePromptTemplate = `你是一名小说本地化译者，请将下面的中文小说世界观翻译并本地化为英文。
+专有名词采用英文读者自然的译法并前后统一，保留原文的设定细节，不要增删内容。
+名称：%s
+标签：%s
+描述：
+%s
+请严格按照如下JSON格式输出：{"name": "", "description": "", "tag": ""}，tag 为英文逗号分隔的英文标签。不要输出除JSON以外的内容。`
+
+// BilingualWorldview 中英双语世界观
+// 两份内容的 Tag 分别带有 lang:zh、lang:en 标记
+type BilingualWorldview struct {
+	Zh Worldview // 中文版
+	En Worldview // 英文版，由中文版翻译本地化得到
+}
+
+// worldviewDraft 模型返回的世界观草稿
+type worldviewDraft struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Tag         string `json:"tag"`
+}
+
+// GenerateBilingualWorldview 按主题同步生成中英双语世界观
+// 先生成中文版，再让模型翻译本地化出英文版；上下文带有风格时渲染进中文生成的提示词
+// 参数:
+// - ctx: 上下文
+// - model: 模型调用函数
+// - theme: 世界观主题，不能为空
+// 返回:
+// - 双语世界观，两份内容的名称与描述均非空
+// - 模型调用失败或结果解析失败时返回错误
+func GenerateBilingualWorldview(ctx context.Context, model ModelFunc, theme string) (*BilingualWorldview, error) {
+	if model == nil {
+		return nil, errors.New("双语生成模型函数不能为空")
+	}
+	theme = strings.TrimSpace(theme)
+	if theme == "" {
+		return nil, errors.New("世界观主题不能为空")
+	}
+
+	zh, err := generateWorldviewDraft(ctx, model, StyledPrompt(ctx, fmt.Sprintf(bilingualSourcePromptTemplate, theme)))
+	if err != nil {
+		return nil, fmt.Errorf("生成中文世界观: %w", err)
+	}
+	en, err := generateWorldviewDraft(ctx, model, fmt.Sprintf(bilingualLocalizePromptTemplate, zh.Name, zh.Tag, zh.Description))
+	if err != nil {
+		return nil, fmt.Errorf("本地化英文世界观: %w", err)
+	}
+
+	return &BilingualWorldview{
+		Zh: Worldview{Name: zh.Name, Description: zh.Description, Tag: withLanguageTag(zh.Tag, LanguageZh)},
+		En: Worldview{Name: en.Name, Description: en.Description, Tag: withLanguageTag(en.Tag, LanguageEn)},
+	}, nil
+}
+
+// SaveBilingualWorldview 将双语世界观保存为关联记录
+// 先保存中文版，英文版的 ParentID 指向中文版；保存成功后回填两份内容的 ID
+func SaveBilingualWorldview(ctx context.Context, store WorldviewStore, bilingual *BilingualWorldview) error {
+	if store == nil {
+		return errors.New("世界观存储不能为空")
+	}
+	if bilingual == nil {
+		return errors.New("双语世界观不能为空")
+	}
+	if err := store.CreateWorldview(ctx, &bilingual.Zh); err != nil {
+		return NewGenerationError(StageWorldview, ErrorKindSave, fmt.Errorf("保存中文世界观失败: %w", err))
+	}
+	bilingual.En.ParentID = bilingual.Zh.ID
+	if err := store.CreateWorldview(ctx, &bilingual.En); err != nil {
+		return NewGenerationError(StageWorldview, ErrorKindSave, fmt.Errorf("保存英文世界观失败: %w", err))
+	}
+	return nil
+}
+
+// generateWorldviewDraft 调用模型并解析世界观草稿
+func generateWorldviewDraft(ctx context.Context, model ModelFunc, prompt string) (*worldviewDraft, error) {
+	output, err := model(ctx, prompt)
+	if err != nil {
+		return nil, NewGenerationError(StageWorldview, ErrorKindModel, err)
+	}
+	draft, err := parseWorldviewDraft(output)
+	if err != nil {
+		return nil, NewGenerationError(StageWorldview, ErrorKindParse, err)
+	}
+	return draft, nil
+}
+
+// parseWorldviewDraft 解析模型返回的世界观草稿
+// 兼容模型在JSON前后附带说明文字或代码块标记的情况，名称或描述为空时返回错误
+func parseWorldviewDraft(s string) (*worldviewDraft, error) {
+	var draft worldviewDraft
+	if err := decodeModelJSON(s, '{', &draft); err != nil {
+		return nil, fmt.Errorf("解析世界观结果失败: %w", err)
+	}
+	draft.Name = strings.TrimSpace(draft.Name)
+	draft.Description = strings.TrimSpace(draft.Description)
+	draft.Tag = strings.TrimSpace(draft.Tag)
+	if draft.Name == "" || draft.Description == "" {
+		return nil, errors.New("世界观结果的名称或描述为空")
+	}
+	return &draft, nil
+}
@@ -0,0 +1,61 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestGenerateBilingualWorldview 测试假生成器下返回结构同时含非空的中英两份，并保存为关联记录
+func TestGenerateBilingualWorldview(t *testing.T) {
+	var prompts []string
+	model := func(ctx context.Context, p string) (string, error) {
+		prompts = append(prompts, p)
+		if strings.Contains(p, "本地化为英文") {
+			return "```json\n" + `{"name": "Cloud City", "description": "A floating city held aloft by leyline crystals", "tag": "fantasy,city"}` + "\n```", nil
+		}
+		return `世界观如下：{"name": "浮空城", "description": "依靠地脉晶石漂浮的城市", "tag": "奇幻,城市"}`, nil
+	}
+
+	bilingual, err := GenerateBilingualWorldview(context.Background(), model, "浮空城市")
+	if err != nil {
+		t.Fatalf("双语生成失败: %v", err)
+	}
+	if len(prompts) != 2 || !strings.Contains(prompts[0], "浮空城市") || !strings.Contains(prompts[1], "依靠地脉晶石漂浮的城市") {
+		t.Errorf("应先按主题生成中文再基于中文本地化，实际提示词为%v", prompts)
+	}
+	if bilingual.Zh.Name != "浮空城" || bilingual.Zh.Description == "" || bilingual.Zh.Tag != "奇幻,城市,lang:zh" {
+		t.Errorf("中文版内容不符合预期: %+v", bilingual.Zh)
+	}
+	if bilingual.En.Name != "Cloud City" || bilingual.En.Description == "" || bilingual.En.Tag != "fantasy,city,lang:en" {
+		t.Errorf("英文版内容不符合预期: %+v", bilingual.En)
+	}
+
+	store := &memoryWorldviewStore{worldviews: map[uint]Worldview{}}
+	if err := SaveBilingualWorldview(context.Background(), store, bilingual); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if bilingual.Zh.ID == 0 || bilingual.En.ParentID != bilingual.Zh.ID || len(store.worldviews) != 2 {
+		t.Errorf("英文版应作为中文版的关联记录保存，实际为%+v", store.worldviews)
+	}
+}
+
+// TestGenerateBilingualWorldviewErrors 测试主题为空与本地化结果无效时返回错误
+func TestGenerateBilingualWorldviewErrors(t *testing.T) {
+	model := func(ctx context.Context, p string) (string, error) {
+		if strings.Contains(p, "本地化为英文") {
+			return `{"name": "Cloud City", "description": ""}`, nil
+		}
+		return `{"name": "浮空城", "description": "依靠地脉晶石漂浮的城市"}`, nil
+	}
+
+	if _, err := GenerateBilingualWorldview(context.Background(), model, " "); err == nil {
+		t.Error("主题为空时应返回错误")
+	}
+	_, err := GenerateBilingualWorldview(context.Background(), model, "浮空城市")
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("英文版描述为空时应返回解析错误，实际为%v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 多候选生成的限制
+const (
+	MaxCandidates               = 5 // 单次请求最多生成的候选数
+	DefaultCandidateParallelism = 3 // 默认同时生成的候选数
+)
+
+// ErrTooManyCandidates 请求的候选数超过上限
+var ErrTooManyCandidates = errors.New("候选数量超过上限")
+
+// WithCandidates 设置一次生成的候选数量，供 GenerateCandidates 使用
+// n 必须在 1 到 MaxCandidates 之间
+func WithCandidates(n int) StoryOption {
+	return func(opts *StoryOptions) error {
+		if n < 1 || n > MaxCandidates {
+			return fmt.Errorf("%w: %d，允许范围为1-%d", ErrTooManyCandidates, n, MaxCandidates)
+		}
+		opts.Candidates = n
+		return nil
+	}
+}
+
+// WithCandidateParallelism 设置同时生成的候选数，小于等于0时使用默认值
+func WithCandidateParallelism(n int) StoryOption {
+	return func(opts *StoryOptions) error {
+		opts.CandidateParallelism = n
+		return nil
+	}
+}
+
+// GenerateCandidates 并发生成多个候选故事供用户挑选
+// 候选只执行各生成阶段，不执行后处理（保存等落库逻辑放在后处理中），也不使用幂等键；
+// 用户选定后调用 CommitCandidate 对该候选执行后处理
+// 参数:
+// - ctx: 上下文，任一候选失败时取消其余候选
+// - options: 与 Generate 相同的选项，通过 WithCandidates 指定候选数量，默认为1
+// 返回:
+// - 按生成顺序排列的候选列表
+// - 任一候选生成失败时返回该错误
+func GenerateCandidates(ctx context.Context, options ...StoryOption) ([]Story, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	opts, err := applyOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Style != "" {
+		ctx = withStyleContext(ctx, opts.Style)
+	}
+
+	n := opts.Candidates
+	if n <= 0 {
+		n = 1
+	}
+	parallelism := opts.CandidateParallelism
+	if parallelism <= 0 {
+		parallelism = DefaultCandidateParallelism
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	candidates := make([]Story, n)
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+
+			story, err := generateDraft(ctx, opts)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			candidates[i] = story
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// CommitCandidate 对用户选定的候选执行后处理（如保存）
+// options 应与生成候选时一致，至少包含相同的后处理器与后处理函数
+func CommitCandidate(ctx context.Context, story *Story, options ...StoryOption) error {
+	if story == nil {
+		return errors.New("候选故事不能为空")
+	}
+	opts, err := applyOptions(options)
+	if err != nil {
+		return err
+	}
+	if err := runPostProcessors(ctx, opts, story); err != nil {
+		return err
+	}
+	if err := opts.PostProcessor(ctx, story); err != nil {
+		return wrapStageError(StagePostProcess, ErrorKindValidation, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGenerateCandidates 测试请求3个候选返回3个不同结果且都未入库
+func TestGenerateCandidates(t *testing.T) {
+	var calls int32
+	var running, maxRunning int32
+	worldviewGen := func(ctx context.Context) ([]Worldview, error) {
+		cur := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&maxRunning)
+			if cur <= old || atomic.CompareAndSwapInt32(&maxRunning, old, cur) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		n := atomic.AddInt32(&calls, 1)
+		return []Worldview{{ID: uint(n), Name: fmt.Sprintf("候选世界观%d", n)}}, nil
+	}
+
+	var mu sync.Mutex
+	var saved []Story
+	saveProcessor := func(ctx context.Context, story *Story) error {
+		mu.Lock()
+		defer mu.Unlock()
+		saved = append(saved, *story)
+		return nil
+	}
+	options := []StoryOption{
+		WithWorldviewGenerator(worldviewGen),
+		WithPostProcessor(saveProcessor),
+		WithCandidates(3),
+		WithCandidateParallelism(2),
+	}
+
+	candidates, err := GenerateCandidates(context.Background(), options...)
+	if err != nil {
+		t.Fatalf("生成候选失败: %v", err)
+	}
+	if len(candidates) != 3 {
+		t.Fatalf("期望3个候选，实际为%d", len(candidates))
+	}
+	names := make(map[string]struct{})
+	for _, c := range candidates {
+		names[c.WorldViews[0].Name] = struct{}{}
+	}
+	if len(names) != 3 {
+		t.Errorf("期望3个不同的候选，实际为%+v", candidates)
+	}
+	if len(saved) != 0 {
+		t.Errorf("候选不应入库，实际保存了%d个", len(saved))
+	}
+	if maxRunning > 2 {
+		t.Errorf("期望最多同时生成2个候选，实际为%d", maxRunning)
+	}
+
+	// 选定后再保存
+	if err := CommitCandidate(context.Background(), &candidates[1], options...); err != nil {
+		t.Fatalf("保存候选失败: %v", err)
+	}
+	if len(saved) != 1 || saved[0].WorldViews[0].Name != candidates[1].WorldViews[0].Name {
+		t.Errorf("期望只保存选定的候选，实际为%+v", saved)
+	}
+}
+
+// TestGenerateCandidatesLimit 测试候选数量上限与失败传播
+func TestGenerateCandidatesLimit(t *testing.T) {
+	if _, err := GenerateCandidates(context.Background(), WithCandidates(MaxCandidates+1)); !errors.Is(err, ErrTooManyCandidates) {
+		t.Errorf("期望超过上限返回ErrTooManyCandidates，实际为%v", err)
+	}
+
+	_, err := GenerateCandidates(context.Background(),
+		WithCandidates(2),
+		WithRuleGenerator(func(ctx context.Context, worldviews []Worldview) ([]Rule, error) {
+			return nil, errors.New("模型不可用")
+		}),
+	)
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Stage != StageRule {
+		t.Errorf("期望返回规则阶段的生成错误，实际为%v", err)
+	}
+}
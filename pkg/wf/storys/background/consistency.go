@@ -0,0 +1,124 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ModelFunc 调用大模型的函数，输入提示词返回模型原始输出
+type ModelFunc func(ctx context.Context, prompt string) (string, error)
+
+// ConsistencyResult 一致性校验结果
+type ConsistencyResult struct {
+	Consistent bool     `json:"consistent"` // 新内容与已有设定是否一致
+	Conflicts  []string `json:"conflicts"`  // 冲突点描述，一致时为空
+}
+
+// ErrInconsistent 生成内容与已有设定存在矛盾
+var ErrInconsistent = errors.New("生成内容与已有设定矛盾")
+
+// consistencyPromptTemplate 一致性校验提示词模板
+// 参数依次为：已有世界观、待校验规则
+const consistencyPromptTemplate = `你是一个小说设定审校助手，请判断新生成的规则是否与世界观或其他规则自相矛盾。
+已有世界观：
+%s
+待校验规则：
+%s
+请严格按照如下JSON格式输出：{"consistent": true, "conflicts": []}，存在矛盾时 consistent 为 false，并在 conflicts 中逐条列出冲突点。不要输出除JSON以外的内容。`
+
+// buildConsistencyPrompt 构建规则一致性校验提示词
+func buildConsistencyPrompt(worldviews []Worldview, rules []Rule) string {
+	var worldviewText, ruleText strings.Builder
+	for _, w := range worldviews {
+		worldviewText.WriteString(w.String())
+		worldviewText.WriteString("\n")
+	}
+	for _, r := range rules {
+		ruleText.WriteString(r.String())
+		ruleText.WriteString("\n")
+	}
+	return fmt.Sprintf(consistencyPromptTemplate, worldviewText.String(), ruleText.String())
+}
+
+// ParseConsistencyResult 解析模型返回的一致性校验结果
+// 兼容模型在JSON前后附带说明文字或代码块标记的情况
+func ParseConsistencyResult(s string) (*ConsistencyResult, error) {
+	var raw struct {
+		Consistent *bool    `json:"consistent"`
+		Conflicts  []string `json:"conflicts"`
+	}
+	if err := decodeModelJSON(s, '{', &raw); err != nil {
+		return nil, fmt.Errorf("解析一致性校验结果失败: %w", err)
+	}
+	if raw.Consistent == nil {
+		return nil, errors.New("一致性校验结果缺少 consistent 字段")
+	}
+
+	result := &ConsistencyResult{Consistent: *raw.Consistent, Conflicts: raw.Conflicts}
+	// 模型声称一致却列出冲突点时以冲突点为准
+	if len(result.Conflicts) > 0 {
+		result.Consistent = false
+	}
+	return result, nil
+}
+
+// CheckRuleConsistency 将规则连同所属世界观一起发给模型，判断是否存在矛盾
+// 参数:
+// - ctx: 上下文
+// - model: 调用大模型的函数
+// - worldviews: 已有世界观
+// - rules: 待校验的规则（包含已有规则与新生成规则）
+// 返回:
+// - 结构化的校验结果
+// - 模型调用或结果解析失败时返回错误
+func CheckRuleConsistency(ctx context.Context, model ModelFunc, worldviews []Worldview, rules []Rule) (*ConsistencyResult, error) {
+	output, err := model(ctx, buildConsistencyPrompt(worldviews, rules))
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindModel, err)
+	}
+	result, err := ParseConsistencyResult(output)
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindParse, err)
+	}
+	return result, nil
+}
+
+// WithConsistencyCheck 启用规则生成后的一致性校验
+// 规则与世界观矛盾时重新生成规则，最多重试 maxRegenerate 次，仍矛盾则返回包含冲突点的校验错误
+func WithConsistencyCheck(model ModelFunc, maxRegenerate int) StoryOption {
+	return func(opts *StoryOptions) error {
+		if model == nil {
+			return errors.New("一致性校验模型函数不能为空")
+		}
+		if maxRegenerate < 0 {
+			maxRegenerate = 0
+		}
+		opts.ConsistencyModel = model
+		opts.MaxRegenerate = maxRegenerate
+		return nil
+	}
+}
+
+// generateConsistentRules 生成规则并在启用一致性校验时校验，矛盾时重新生成
+func generateConsistentRules(ctx context.Context, opts *StoryOptions, worldviews []Worldview) ([]Rule, error) {
+	for attempt := 0; ; attempt++ {
+		rules, err := opts.RuleGenerator(ctx, worldviews)
+		if err != nil || opts.ConsistencyModel == nil {
+			return rules, err
+		}
+
+		result, err := CheckRuleConsistency(ctx, opts.ConsistencyModel, worldviews, rules)
+		if err != nil {
+			return nil, err
+		}
+		if result.Consistent {
+			return rules, nil
+		}
+		if attempt >= opts.MaxRegenerate {
+			return nil, NewGenerationError("", ErrorKindValidation,
+				fmt.Errorf("%w: %s", ErrInconsistent, strings.Join(result.Conflicts, "；")))
+		}
+	}
+}
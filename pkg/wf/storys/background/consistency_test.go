@@ -0,0 +1,118 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestParseConsistencyResult 测试一致性校验结果解析
+func TestParseConsistencyResult(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		wantConsistent bool
+		wantConflicts  int
+		wantErr        bool
+	}{
+		{name: "一致", input: `{"consistent": true, "conflicts": []}`, wantConsistent: true},
+		{name: "矛盾", input: "```json\n{\"consistent\": false, \"conflicts\": [\"魔法禁用却出现法师公会\", \"时间线冲突\"]}\n```", wantConflicts: 2},
+		{name: "声称一致但列出冲突", input: `{"consistent": true, "conflicts": ["冲突"]}`, wantConflicts: 1},
+		{name: "缺少字段", input: `{"conflicts": []}`, wantErr: true},
+		{name: "非JSON", input: "没有矛盾", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseConsistencyResult(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("期望解析失败，实际结果为%+v", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			if result.Consistent != tt.wantConsistent {
+				t.Errorf("期望consistent为%v，实际为%v", tt.wantConsistent, result.Consistent)
+			}
+			if len(result.Conflicts) != tt.wantConflicts {
+				t.Errorf("期望%d个冲突点，实际为%v", tt.wantConflicts, result.Conflicts)
+			}
+		})
+	}
+}
+
+// TestCheckRuleConsistency 测试假模型判断矛盾时返回冲突点
+func TestCheckRuleConsistency(t *testing.T) {
+	worldviews := []Worldview{{ID: 1, Name: "无魔之地", Description: "世界上不存在任何魔法"}}
+	rules := []Rule{{ID: 1, WorldviewID: 1, Name: "法师公会", Description: "法师通过公会学习魔法"}}
+
+	var prompt string
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return `{"consistent": false, "conflicts": ["世界观不存在魔法，但规则中有法师公会"]}`, nil
+	}
+
+	result, err := CheckRuleConsistency(context.Background(), fakeModel, worldviews, rules)
+	if err != nil {
+		t.Fatalf("一致性校验失败: %v", err)
+	}
+	if result.Consistent {
+		t.Error("期望consistent为false")
+	}
+	if len(result.Conflicts) != 1 || !strings.Contains(result.Conflicts[0], "法师公会") {
+		t.Errorf("冲突点不符: %v", result.Conflicts)
+	}
+	if !strings.Contains(prompt, "无魔之地") || !strings.Contains(prompt, "法师公会") {
+		t.Errorf("提示词应包含世界观与规则: %s", prompt)
+	}
+}
+
+// TestGenerateWithConsistencyCheck 测试矛盾时重新生成规则，多次仍矛盾返回校验错误
+func TestGenerateWithConsistencyCheck(t *testing.T) {
+	var ruleCalls int
+	ruleGenerator := WithRuleGenerator(func(ctx context.Context, w []Worldview) ([]Rule, error) {
+		ruleCalls++
+		return []Rule{{ID: uint(ruleCalls), Name: "规则"}}, nil
+	})
+
+	// 首次矛盾、二次一致
+	var checkCalls int
+	model := func(ctx context.Context, prompt string) (string, error) {
+		checkCalls++
+		if checkCalls == 1 {
+			return `{"consistent": false, "conflicts": ["冲突"]}`, nil
+		}
+		return `{"consistent": true, "conflicts": []}`, nil
+	}
+	story, err := Generate(context.Background(), ruleGenerator, WithConsistencyCheck(model, 2))
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if ruleCalls != 2 || len(story.Rules) != 1 || story.Rules[0].ID != 2 {
+		t.Errorf("期望重新生成一次规则，实际调用%d次，规则为%+v", ruleCalls, story.Rules)
+	}
+
+	// 始终矛盾
+	ruleCalls = 0
+	alwaysConflict := func(ctx context.Context, prompt string) (string, error) {
+		return `{"consistent": false, "conflicts": ["冲突A", "冲突B"]}`, nil
+	}
+	_, err = Generate(context.Background(), ruleGenerator, WithConsistencyCheck(alwaysConflict, 1))
+	if !errors.Is(err, ErrInconsistent) {
+		t.Fatalf("期望ErrInconsistent错误，实际为%v", err)
+	}
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Stage != StageRule || genErr.Kind != ErrorKindValidation {
+		t.Errorf("期望规则阶段的校验错误，实际为%v", err)
+	}
+	if !strings.Contains(err.Error(), "冲突A") || !strings.Contains(err.Error(), "冲突B") {
+		t.Errorf("错误信息应列出冲突点: %v", err)
+	}
+	if ruleCalls != 2 {
+		t.Errorf("期望生成规则2次，实际为%d次", ruleCalls)
+	}
+}
@@ -0,0 +1,71 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxCoverPromptLength 封面提示词的最大长度（字符数），超出部分截断
+const MaxCoverPromptLength = 1000
+
+// coverPromptTemplate 封面提示词生成模板，参数依次为世界观名称和描述
+const coverPromptTemplate = `你是一名插画师助手，请根据下面的小说世界观，为文生图模型写一段英文图像提示词，用作该世界观的封面。
+提示词需描述画面主体、场景、氛围、光线和画风，使用英文短语并以英文逗号分隔，不要出现任何中文。
+世界观名称：%s
+世界观描述：%s
+只输出提示词本身，不要输出解释或其他内容。`
+
+// GenerateCoverPrompt 基于世界观名称和描述生成英文封面图像提示词
+// 生成成功后写入 w.CoverPrompt，随世界观一并保存
+// 参数:
+// - ctx: 上下文
+// - model: 模型调用函数
+// - w: 世界观，名称不能为空
+// 返回:
+// - 清理后的英文提示词
+// - 模型调用失败，或输出为空、包含中文时返回错误
+func GenerateCoverPrompt(ctx context.Context, model ModelFunc, w *Worldview) (string, error) {
+	if model == nil {
+		return "", errors.New("封面提示词模型函数不能为空")
+	}
+	if w == nil || strings.TrimSpace(w.Name) == "" {
+		return "", errors.New("世界观名称不能为空")
+	}
+
+	output, err := model(ctx, fmt.Sprintf(coverPromptTemplate, w.Name, w.Description))
+	if err != nil {
+		return "", NewGenerationError(StageWorldview, ErrorKindModel, err)
+	}
+	prompt, err := cleanCoverPrompt(output)
+	if err != nil {
+		return "", NewGenerationError(StageWorldview, ErrorKindParse, err)
+	}
+	w.CoverPrompt = prompt
+	return prompt, nil
+}
+
+// cleanCoverPrompt 清理模型输出的封面提示词
+// 去掉代码块标记与首尾引号，多行合并为一行，超长时截断
+func cleanCoverPrompt(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "```text")
+	s = strings.Trim(s, "`")
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.Trim(s, "\"'“”")
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("封面提示词为空")
+	}
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return "", fmt.Errorf("封面提示词应为英文: %s", s)
+		}
+	}
+	if runes := []rune(s); len(runes) > MaxCoverPromptLength {
+		s = string(runes[:MaxCoverPromptLength])
+	}
+	return s, nil
+}
@@ -0,0 +1,60 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestGenerateCoverPrompt 测试假模型返回的英文提示词被清理并写入世界观
+func TestGenerateCoverPrompt(t *testing.T) {
+	w := &Worldview{ID: 1, Name: "星海帝国", Description: "横跨银河的星际帝国，贵族乘坐巨舰巡游群星"}
+
+	var prompt string
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "```\n\"a vast galactic empire, colossal starships,\nnebula background, epic lighting, concept art\"\n```", nil
+	}
+
+	got, err := GenerateCoverPrompt(context.Background(), fakeModel, w)
+	if err != nil {
+		t.Fatalf("生成封面提示词失败: %v", err)
+	}
+	if !strings.Contains(prompt, w.Name) || !strings.Contains(prompt, w.Description) {
+		t.Errorf("期望提示词包含世界观名称和描述，实际为%s", prompt)
+	}
+
+	want := "a vast galactic empire, colossal starships, nebula background, epic lighting, concept art"
+	if got != want {
+		t.Errorf("期望封面提示词为%q，实际为%q", want, got)
+	}
+	if w.CoverPrompt != want {
+		t.Errorf("期望封面提示词写入世界观，实际为%q", w.CoverPrompt)
+	}
+}
+
+// TestGenerateCoverPrompt_Invalid 测试空输出或中文输出返回解析错误
+func TestGenerateCoverPrompt_Invalid(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := GenerateCoverPrompt(ctx, func(ctx context.Context, p string) (string, error) {
+		return "x", nil
+	}, &Worldview{}); err == nil {
+		t.Error("世界观名称为空时应返回错误")
+	}
+
+	for _, output := range []string{"  ", "宏大的星际帝国, epic"} {
+		w := &Worldview{Name: "星海帝国"}
+		_, err := GenerateCoverPrompt(ctx, func(ctx context.Context, p string) (string, error) {
+			return output, nil
+		}, w)
+		var genErr *GenerationError
+		if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+			t.Errorf("输出%q期望解析类错误，实际为%v", output, err)
+		}
+		if w.CoverPrompt != "" {
+			t.Errorf("失败时不应写入封面提示词，实际为%q", w.CoverPrompt)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DefaultDuplicateThreshold 默认的重复度阈值，名称或描述的相似度达到该值视为高重复
+const DefaultDuplicateThreshold = 0.6
+
+// DuplicateSuggestion 高重复时给用户的处理建议
+type DuplicateSuggestion string
+
+// 重复处理建议常量
+const (
+	SuggestMerge  DuplicateSuggestion = "merge"  // 描述相近，含义重复，建议合并
+	SuggestRename DuplicateSuggestion = "rename" // 仅名称相近，建议改名
+)
+
+// DuplicateWarning 新规则与已有规则高重复的提示，由用户决定合并、改名或保留
+type DuplicateWarning struct {
+	Rule             Rule                // 新生成的规则
+	Existing         Rule                // 与之重复度最高的已有规则
+	NameScore        float64             // 名称相似度，取值 0~1
+	DescriptionScore float64             // 描述相似度，取值 0~1
+	Suggestion       DuplicateSuggestion // 处理建议
+	Warning          string              // 面向用户的提示文本
+}
+
+// TextSimilarity 计算两段文本的相似度，取值 0~1
+// 忽略大小写、空白与标点后按字符二元组的 Dice 系数计算，适用于中英文；任一文本为空时返回0
+func TextSimilarity(a, b string) float64 {
+	ra, rb := normalizeForSimilarity(a), normalizeForSimilarity(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	if string(ra) == string(rb) {
+		return 1
+	}
+	if len(ra) < 2 || len(rb) < 2 {
+		return 0
+	}
+
+	counts := make(map[[2]rune]int, len(ra)-1)
+	for i := 0; i+1 < len(ra); i++ {
+		counts[[2]rune{ra[i], ra[i+1]}]++
+	}
+	common := 0
+	for i := 0; i+1 < len(rb); i++ {
+		bigram := [2]rune{rb[i], rb[i+1]}
+		if counts[bigram] > 0 {
+			counts[bigram]--
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(ra)-1+len(rb)-1)
+}
+
+// normalizeForSimilarity 转小写并只保留字母与数字
+func normalizeForSimilarity(s string) []rune {
+	runes := make([]rune, 0, len(s))
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+// RuleDuplicateScore 计算两条规则名称与描述的相似度
+func RuleDuplicateScore(a, b Rule) (nameScore, descriptionScore float64) {
+	return TextSimilarity(a.Name, b.Name), TextSimilarity(a.Description, b.Description)
+}
+
+// CheckDuplicateRules 检测新规则与同一世界观下已有规则的重复度
+// 子规则一并参与检测；每条新规则只与重复度最高的已有规则比较，
+// 名称或描述的相似度达到 threshold 时返回提示：描述相近建议合并，仅名称相近建议改名。
+// threshold 小于等于0时使用 DefaultDuplicateThreshold
+func CheckDuplicateRules(candidates, existing []Rule, threshold float64) []DuplicateWarning {
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+	existingFlat := flattenRules(existing)
+
+	var warnings []DuplicateWarning
+	for _, rule := range flattenRules(candidates) {
+		var best DuplicateWarning
+		bestScore := -1.0
+		for _, old := range existingFlat {
+			if old.WorldviewID != rule.WorldviewID || (rule.ID != 0 && old.ID == rule.ID) {
+				continue
+			}
+			nameScore, descriptionScore := RuleDuplicateScore(rule, old)
+			if score := max(nameScore, descriptionScore); score > bestScore {
+				bestScore = score
+				best = DuplicateWarning{Rule: rule, Existing: old, NameScore: nameScore, DescriptionScore: descriptionScore}
+			}
+		}
+		if bestScore < threshold {
+			continue
+		}
+		best.Suggestion = SuggestRename
+		if best.DescriptionScore >= threshold {
+			best.Suggestion = SuggestMerge
+		}
+		best.Rule.Children, best.Existing.Children = nil, nil
+		best.Warning = duplicateWarningText(best)
+		warnings = append(warnings, best)
+	}
+	return warnings
+}
+
+// duplicateWarningText 生成面向用户的重复提示
+func duplicateWarningText(w DuplicateWarning) string {
+	advice := "可改名"
+	if w.Suggestion == SuggestMerge {
+		advice = "可合并"
+	}
+	return fmt.Sprintf("规则「%s」与已有规则「%s」高度相似（名称%.0f%%，描述%.0f%%），建议：%s",
+		w.Rule.Name, w.Existing.Name, w.NameScore*100, w.DescriptionScore*100, advice)
+}
+
+// flattenRules 展开规则树
+func flattenRules(rules []Rule) []Rule {
+	var flat []Rule
+	for _, rule := range rules {
+		flat = append(flat, rule)
+		flat = append(flat, flattenRules(rule.Children)...)
+	}
+	return flat
+}
+
+// WithDuplicateRuleCheck 在保存前检测生成的规则与已有规则的重复度
+// existing 按世界观返回已有规则；高重复不会中止生成，提示记录在 Story.DuplicateWarnings 中由用户决定。
+// threshold 小于等于0时使用 DefaultDuplicateThreshold
+func WithDuplicateRuleCheck(existing func(context.Context, []Worldview) ([]Rule, error), threshold float64) StoryOption {
+	return func(opts *StoryOptions) error {
+		if existing == nil {
+			return errors.New("已有规则查询函数不能为空")
+		}
+		opts.ExistingRules = existing
+		opts.DuplicateThreshold = threshold
+		return nil
+	}
+}
+
+// checkDuplicateRuleStage 按选项检测规则重复度，未启用时返回空
+func checkDuplicateRuleStage(ctx context.Context, opts *StoryOptions, worldviews []Worldview, rules []Rule) ([]DuplicateWarning, error) {
+	if opts.ExistingRules == nil {
+		return nil, nil
+	}
+	existing, err := opts.ExistingRules(ctx, worldviews)
+	if err != nil {
+		return nil, wrapStageError(StageRule, ErrorKindSave, fmt.Errorf("查询已有规则失败: %w", err))
+	}
+	return CheckDuplicateRules(rules, existing, opts.DuplicateThreshold), nil
+}
@@ -0,0 +1,106 @@
+package background
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestTextSimilarity 测试文本相似度计算
+func TestTextSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b    string
+		min     float64
+		max     float64
+		comment string
+	}{
+		{"跃迁禁令", "跃迁禁令", 1, 1, "完全相同"},
+		{"Warp Ban", "warp-ban!", 1, 1, "忽略大小写、空白与标点"},
+		{"跃迁禁令", "跃迁禁止令", 0.5, 0.9, "名称相近"},
+		{"跃迁禁令", "魔法潮汐", 0, 0, "完全不同"},
+		{"", "跃迁禁令", 0, 0, "空文本"},
+	}
+	for _, c := range cases {
+		got := TextSimilarity(c.a, c.b)
+		if got < c.min || got > c.max {
+			t.Errorf("%s: TextSimilarity(%q, %q) = %.2f，期望在[%.2f, %.2f]内", c.comment, c.a, c.b, got, c.min, c.max)
+		}
+		if rev := TextSimilarity(c.b, c.a); rev != got {
+			t.Errorf("%s: 相似度应对称，实际为%.2f与%.2f", c.comment, got, rev)
+		}
+	}
+}
+
+// TestCheckDuplicateRules 测试相似规则触发 warning，独特规则无 warning
+func TestCheckDuplicateRules(t *testing.T) {
+	existing := []Rule{
+		{ID: 1, WorldviewID: 1, Name: "跃迁禁令", Description: "帝国境内禁止私自进行超光速跃迁，违者舰船将被没收"},
+		{ID: 2, WorldviewID: 1, Name: "贵族议会", Description: "帝国重大决策由十二位大贵族组成的议会投票决定",
+			Children: []Rule{{ID: 3, WorldviewID: 1, ParentID: 2, Name: "议会否决权", Description: "皇帝可以否决议会决议一次"}}},
+		{ID: 4, WorldviewID: 2, Name: "魔法潮汐", Description: "每逢月圆魔力暴涨"},
+	}
+	candidates := []Rule{
+		// 描述几乎相同：建议合并
+		{WorldviewID: 1, Name: "超光速管制", Description: "帝国境内禁止私自进行超光速跃迁，违者的舰船将被没收"},
+		// 名称与子规则相同但含义不同：建议改名
+		{WorldviewID: 1, Name: "议会否决权", Description: "平民代表可以否决新增税收"},
+		// 独特规则
+		{WorldviewID: 1, Name: "星港税制", Description: "所有星港按吞吐量向帝国缴纳关税"},
+		// 与其他世界观的规则同名不算重复
+		{WorldviewID: 1, Name: "魔法潮汐", Description: "潮汐引力影响航线"},
+	}
+
+	warnings := CheckDuplicateRules(candidates, existing, 0)
+	if len(warnings) != 2 {
+		t.Fatalf("期望2条重复提示，实际为%d条: %+v", len(warnings), warnings)
+	}
+
+	merge := warnings[0]
+	if merge.Existing.ID != 1 || merge.Suggestion != SuggestMerge || merge.DescriptionScore < DefaultDuplicateThreshold {
+		t.Errorf("描述相近的规则应建议与规则1合并，实际为%+v", merge)
+	}
+	if !strings.Contains(merge.Warning, "建议：可合并") || !strings.Contains(merge.Warning, "跃迁禁令") {
+		t.Errorf("提示文本不符合预期: %s", merge.Warning)
+	}
+
+	rename := warnings[1]
+	if rename.Existing.ID != 3 || rename.Suggestion != SuggestRename || rename.NameScore != 1 {
+		t.Errorf("同名不同义的规则应建议改名，实际为%+v", rename)
+	}
+	if !strings.Contains(rename.Warning, "建议：可改名") {
+		t.Errorf("提示文本不符合预期: %s", rename.Warning)
+	}
+
+	if got := CheckDuplicateRules(candidates[2:3], existing, 0); len(got) != 0 {
+		t.Errorf("独特规则不应产生提示，实际为%+v", got)
+	}
+}
+
+// TestGenerateDuplicateRuleCheck 测试生成流程在保存前附带重复提示而不中止
+func TestGenerateDuplicateRuleCheck(t *testing.T) {
+	var saved int
+	story, err := Generate(context.Background(),
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			return []Worldview{{ID: 1, Name: "星海帝国"}}, nil
+		}),
+		WithRuleGenerator(func(ctx context.Context, worldviews []Worldview) ([]Rule, error) {
+			return []Rule{{WorldviewID: 1, Name: "跃迁禁令", Description: "禁止跃迁"}}, nil
+		}),
+		WithDuplicateRuleCheck(func(ctx context.Context, worldviews []Worldview) ([]Rule, error) {
+			return []Rule{{ID: 9, WorldviewID: worldviews[0].ID, Name: "跃迁禁令", Description: "禁止跃迁"}}, nil
+		}, 0),
+		WithPostProcessor(func(ctx context.Context, story *Story) error {
+			saved = len(story.DuplicateWarnings)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("高重复不应中止生成: %v", err)
+	}
+	if len(story.DuplicateWarnings) != 1 || story.DuplicateWarnings[0].Existing.ID != 9 {
+		t.Errorf("期望附带与规则9重复的提示，实际为%+v", story.DuplicateWarnings)
+	}
+	if saved != 1 {
+		t.Errorf("保存前应已得到重复提示，实际为%d条", saved)
+	}
+}
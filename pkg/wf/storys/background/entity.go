@@ -0,0 +1,121 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 实体类型常量，可根据业务扩展
+const (
+	EntityTypePerson = "person" // 人物
+	EntityTypePlace  = "place"  // 地点
+	EntityTypeItem   = "item"   // 物品
+	EntityTypeOrg    = "org"    // 组织
+	EntityTypeEvent  = "event"  // 事件
+)
+
+// Entity 从生成文本中抽取的关键实体
+type Entity struct {
+	Name         string `json:"name"`        // 实体名称
+	Type         string `json:"type"`        // 实体类型
+	Description  string `json:"description"` // 实体描述
+	BackgroundID uint   `json:"-"`           // 来源背景ID，自动抽取时填充
+}
+
+// entityPromptTemplate 实体抽取提示词模板，参数为待抽取文本
+const entityPromptTemplate = `你是一个小说设定整理助手，请从下面的文本中抽取人物、地点、物品、组织、事件等关键实体。
+实体类型只能是 person、place、item、org、event 之一。
+文本：
+%s
+请严格按照如下JSON格式输出：[{"name": "", "type": "", "description": ""}]，没有实体时输出 []。不要输出除JSON以外的内容。`
+
+// EntityExtractor 基于大模型的实体抽取器
+type EntityExtractor struct {
+	model ModelFunc
+}
+
+// NewEntityExtractor 创建实体抽取器
+func NewEntityExtractor(model ModelFunc) *EntityExtractor {
+	return &EntityExtractor{model: model}
+}
+
+// ExtractEntities 从文本中抽取关键实体
+// 参数:
+// - ctx: 上下文
+// - text: 待抽取的文本
+// 返回:
+// - 去掉空名称与重复项后的实体列表
+// - 模型调用或结果解析失败时返回错误
+func (e *EntityExtractor) ExtractEntities(ctx context.Context, text string) ([]Entity, error) {
+	if e.model == nil {
+		return nil, errors.New("实体抽取模型函数不能为空")
+	}
+	if strings.TrimSpace(text) == "" {
+		return []Entity{}, nil
+	}
+	output, err := e.model(ctx, fmt.Sprintf(entityPromptTemplate, text))
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindModel, err)
+	}
+	entities, err := ParseEntities(output)
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindParse, err)
+	}
+	return entities, nil
+}
+
+// ParseEntities 解析模型返回的实体列表
+// 兼容模型在JSON前后附带说明文字或代码块标记的情况，名称为空的实体被丢弃，同名同类型实体只保留第一个
+func ParseEntities(s string) ([]Entity, error) {
+	var raw []Entity
+	if err := decodeModelJSON(s, '[', &raw); err != nil {
+		return nil, fmt.Errorf("解析实体抽取结果失败: %w", err)
+	}
+
+	entities := make([]Entity, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, entity := range raw {
+		entity.Name = strings.TrimSpace(entity.Name)
+		entity.Type = strings.ToLower(strings.TrimSpace(entity.Type))
+		if entity.Name == "" {
+			continue
+		}
+		key := entity.Type + "/" + entity.Name
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// WithEntityExtraction 启用背景生成后的实体自动抽取
+// 抽取结果记录在 Story.Entities 中，并通过 BackgroundID 关联来源背景
+func WithEntityExtraction(model ModelFunc) StoryOption {
+	return func(opts *StoryOptions) error {
+		if model == nil {
+			return errors.New("实体抽取模型函数不能为空")
+		}
+		opts.EntityExtractor = NewEntityExtractor(model)
+		return nil
+	}
+}
+
+// extractBackgroundEntities 从各背景描述中抽取实体
+func extractBackgroundEntities(ctx context.Context, extractor *EntityExtractor, backgrounds []Background) ([]Entity, error) {
+	var all []Entity
+	for _, b := range backgrounds {
+		entities, err := extractor.ExtractEntities(ctx, b.Description)
+		if err != nil {
+			return nil, err
+		}
+		for i := range entities {
+			entities[i].BackgroundID = b.ID
+		}
+		all = append(all, entities...)
+	}
+	return all, nil
+}
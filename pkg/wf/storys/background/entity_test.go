@@ -0,0 +1,84 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestExtractEntities 测试假模型返回的实体列表被正确解析
+func TestExtractEntities(t *testing.T) {
+	text := "艾琳是王都的见习骑士，她携带着祖传的星辰剑前往北境要塞。"
+
+	var prompt string
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "以下是抽取结果：\n```json\n" + `[
+			{"name": "艾琳", "type": "person", "description": "王都的见习骑士"},
+			{"name": "王都", "type": "place", "description": "艾琳的出身地"},
+			{"name": "星辰剑", "type": "Item", "description": "祖传的宝剑"},
+			{"name": "北境要塞", "type": "place", "description": "艾琳的目的地"},
+			{"name": "艾琳", "type": "person", "description": "重复项"},
+			{"name": " ", "type": "person", "description": "空名称"}
+		]` + "\n```", nil
+	}
+
+	entities, err := NewEntityExtractor(fakeModel).ExtractEntities(context.Background(), text)
+	if err != nil {
+		t.Fatalf("抽取实体失败: %v", err)
+	}
+	if !strings.Contains(prompt, text) {
+		t.Errorf("期望提示词包含待抽取文本，实际为%s", prompt)
+	}
+
+	want := []Entity{
+		{Name: "艾琳", Type: EntityTypePerson, Description: "王都的见习骑士"},
+		{Name: "王都", Type: EntityTypePlace, Description: "艾琳的出身地"},
+		{Name: "星辰剑", Type: EntityTypeItem, Description: "祖传的宝剑"},
+		{Name: "北境要塞", Type: EntityTypePlace, Description: "艾琳的目的地"},
+	}
+	if len(entities) != len(want) {
+		t.Fatalf("期望%d个实体，实际为%+v", len(want), entities)
+	}
+	for i := range want {
+		if entities[i] != want[i] {
+			t.Errorf("第%d个实体期望为%+v，实际为%+v", i, want[i], entities[i])
+		}
+	}
+}
+
+// TestExtractEntitiesInvalidOutput 测试模型输出非JSON时返回解析错误
+func TestExtractEntitiesInvalidOutput(t *testing.T) {
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		return "文本中没有实体", nil
+	}
+
+	_, err := NewEntityExtractor(fakeModel).ExtractEntities(context.Background(), "一段文本")
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("期望解析错误，实际为%v", err)
+	}
+}
+
+// TestExtractBackgroundEntities 测试自动抽取结果关联来源背景
+func TestExtractBackgroundEntities(t *testing.T) {
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		if strings.Contains(p, "港口") {
+			return `[{"name": "银帆港", "type": "place", "description": "港口城市"}]`, nil
+		}
+		return `[]`, nil
+	}
+	backgrounds := []Background{
+		{ID: 3, Description: "银帆港是大陆最繁忙的港口"},
+		{ID: 4, Description: "一片荒原"},
+	}
+
+	entities, err := extractBackgroundEntities(context.Background(), NewEntityExtractor(fakeModel), backgrounds)
+	if err != nil {
+		t.Fatalf("抽取实体失败: %v", err)
+	}
+	if len(entities) != 1 || entities[0].Name != "银帆港" || entities[0].BackgroundID != 3 {
+		t.Errorf("期望抽取到关联背景3的银帆港，实际为%+v", entities)
+	}
+}
@@ -0,0 +1,80 @@
+package background
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GenerationStage 生成阶段
+type GenerationStage string
+
+// 生成阶段常量
+const (
+	StageWorldview   GenerationStage = "worldview"    // 世界观生成
+	StageRule        GenerationStage = "rule"         // 规则生成
+	StageBackground  GenerationStage = "background"   // 背景生成
+	StagePostProcess GenerationStage = "post_process" // 后处理
+)
+
+// stageNames 生成阶段的中文名称，用于错误信息
+var stageNames = map[GenerationStage]string{
+	StageWorldview:   "世界观",
+	StageRule:        "规则",
+	StageBackground:  "背景",
+	StagePostProcess: "后处理",
+}
+
+// GenerationErrorKind 生成错误类别
+type GenerationErrorKind string
+
+// 生成错误类别常量
+const (
+	ErrorKindModel      GenerationErrorKind = "model"      // 模型不可达或调用失败
+	ErrorKindParse      GenerationErrorKind = "parse"      // 模型输出解析失败
+	ErrorKindSave       GenerationErrorKind = "save"       // 保存失败
+	ErrorKindValidation GenerationErrorKind = "validation" // 内容校验失败
+)
+
+// GenerationError 结构化的生成错误
+// 调用方可通过 errors.As 提取，按阶段和类别决定重试或提示方式
+type GenerationError struct {
+	Stage GenerationStage     // 出错的生成阶段
+	Kind  GenerationErrorKind // 错误类别
+	Cause error               // 原始错误
+}
+
+// NewGenerationError 创建生成错误
+// 生成函数可直接返回该错误以指定类别，Stage 留空时由 Generate 按所在阶段补全
+func NewGenerationError(stage GenerationStage, kind GenerationErrorKind, cause error) *GenerationError {
+	return &GenerationError{Stage: stage, Kind: kind, Cause: cause}
+}
+
+// Error 实现error接口，保持“生成X失败: 原因”的格式
+func (e *GenerationError) Error() string {
+	name, ok := stageNames[e.Stage]
+	if !ok {
+		name = string(e.Stage)
+	}
+	if e.Stage == StagePostProcess {
+		return fmt.Sprintf("%s失败: %v", name, e.Cause)
+	}
+	return fmt.Sprintf("生成%s失败: %v", name, e.Cause)
+}
+
+// Unwrap 返回原始错误
+func (e *GenerationError) Unwrap() error {
+	return e.Cause
+}
+
+// wrapStageError 将阶段错误包装为 GenerationError
+// 已是 GenerationError 时保留其类别并补全阶段，否则按 defaultKind 归类
+func wrapStageError(stage GenerationStage, defaultKind GenerationErrorKind, err error) error {
+	var genErr *GenerationError
+	if errors.As(err, &genErr) {
+		if genErr.Stage == "" {
+			genErr.Stage = stage
+		}
+		return genErr
+	}
+	return &GenerationError{Stage: stage, Kind: defaultKind, Cause: err}
+}
@@ -0,0 +1,85 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPrimaryModelTimeout 主模型的默认超时时间，超时后降级到备用模型
+const DefaultPrimaryModelTimeout = 2 * time.Minute
+
+// FallbackConfig 模型降级配置
+type FallbackConfig struct {
+	FallbackModel ModelFunc     // 备用模型，通常为更快的小模型
+	Timeout       time.Duration // 主模型超时时间，小于等于0时使用 DefaultPrimaryModelTimeout
+}
+
+// NewFallbackModel 包装主模型，主模型超时或失败时用备用模型重试一次
+// 调用方取消或上下文超时时不再降级；通过 Generate 或分步生成调用时，
+// 降级会标记在结果的 Story.Degraded 中
+// 参数:
+// - primary: 主模型，通常为高质量的大模型
+// - config: 降级配置，FallbackModel 为空时原样返回主模型
+// 返回:
+// - 带降级能力的模型函数
+func NewFallbackModel(primary ModelFunc, config FallbackConfig) (ModelFunc, error) {
+	if primary == nil {
+		return nil, errors.New("主模型函数不能为空")
+	}
+	if config.FallbackModel == nil {
+		return primary, nil
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPrimaryModelTimeout
+	}
+	fallback := config.FallbackModel
+
+	return func(ctx context.Context, prompt string) (string, error) {
+		primaryCtx, cancel := context.WithTimeout(ctx, timeout)
+		output, err := primary(primaryCtx, prompt)
+		cancel()
+		if err == nil {
+			return output, nil
+		}
+		if ctx.Err() != nil {
+			return "", err
+		}
+
+		output, fallbackErr := fallback(ctx, prompt)
+		if fallbackErr != nil {
+			return "", fmt.Errorf("主模型失败: %v；备用模型失败: %w", err, fallbackErr)
+		}
+		markModelFallback(ctx)
+		return output, nil
+	}, nil
+}
+
+// fallbackTracker 记录一次生成中是否有模型调用降级
+type fallbackTracker struct {
+	degraded atomic.Bool
+}
+
+// used 判断是否发生过降级
+func (t *fallbackTracker) used() bool {
+	return t.degraded.Load()
+}
+
+// fallbackTrackerKey 降级记录在上下文中的键
+type fallbackTrackerKey struct{}
+
+// trackModelFallback 在上下文中附加降级记录，之后经该上下文的降级都会记入返回的记录
+func trackModelFallback(ctx context.Context) (context.Context, *fallbackTracker) {
+	tracker := &fallbackTracker{}
+	return context.WithValue(ctx, fallbackTrackerKey{}, tracker), tracker
+}
+
+// markModelFallback 将降级记入上下文中的记录，未附加记录时忽略
+func markModelFallback(ctx context.Context) {
+	if tracker, ok := ctx.Value(fallbackTrackerKey{}).(*fallbackTracker); ok {
+		tracker.degraded.Store(true)
+	}
+}
@@ -0,0 +1,158 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowModel 阻塞直到上下文结束的假主模型，模拟大模型生成超时
+func slowModel(ctx context.Context, prompt string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+// TestFallbackModelOnTimeout 测试主模型超时后用备用模型成功并在结果中标记降级
+func TestFallbackModelOnTimeout(t *testing.T) {
+	var fallbackPrompts []string
+	model, err := NewFallbackModel(slowModel, FallbackConfig{
+		FallbackModel: func(ctx context.Context, prompt string) (string, error) {
+			fallbackPrompts = append(fallbackPrompts, prompt)
+			return "快速世界观", nil
+		},
+		Timeout: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("创建降级模型失败: %v", err)
+	}
+
+	story, err := Generate(context.Background(),
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			output, err := model(ctx, "生成世界观")
+			if err != nil {
+				return nil, err
+			}
+			return []Worldview{{ID: 1, Name: output}}, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("主模型超时后应降级成功: %v", err)
+	}
+	if len(story.WorldViews) != 1 || story.WorldViews[0].Name != "快速世界观" {
+		t.Errorf("期望使用备用模型的输出，实际为%+v", story.WorldViews)
+	}
+	if len(fallbackPrompts) != 1 || fallbackPrompts[0] != "生成世界观" {
+		t.Errorf("备用模型应以相同提示词重试一次，实际为%v", fallbackPrompts)
+	}
+	if !story.Degraded {
+		t.Error("使用了备用模型时应标记降级")
+	}
+}
+
+// TestFallbackModelPrimarySuccess 测试主模型成功时不降级
+func TestFallbackModelPrimarySuccess(t *testing.T) {
+	fallbackCalls := 0
+	model, err := NewFallbackModel(func(ctx context.Context, prompt string) (string, error) {
+		return "高质量世界观", nil
+	}, FallbackConfig{
+		FallbackModel: func(ctx context.Context, prompt string) (string, error) {
+			fallbackCalls++
+			return "快速世界观", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建降级模型失败: %v", err)
+	}
+
+	story, err := Generate(context.Background(),
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			output, err := model(ctx, "生成世界观")
+			return []Worldview{{ID: 1, Name: output}}, err
+		}),
+	)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if story.WorldViews[0].Name != "高质量世界观" || fallbackCalls != 0 || story.Degraded {
+		t.Errorf("主模型成功时不应降级，实际为%+v，备用模型调用%d次", story, fallbackCalls)
+	}
+}
+
+// TestFallbackModelErrors 测试备用模型也失败时返回错误，调用方取消时不再降级
+func TestFallbackModelErrors(t *testing.T) {
+	primaryErr := errors.New("服务不可用")
+	fallbackCalls := 0
+	model, err := NewFallbackModel(func(ctx context.Context, prompt string) (string, error) {
+		return "", primaryErr
+	}, FallbackConfig{
+		FallbackModel: func(ctx context.Context, prompt string) (string, error) {
+			fallbackCalls++
+			return "", errors.New("备用模型超载")
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建降级模型失败: %v", err)
+	}
+
+	_, err = model(context.Background(), "生成规则")
+	if err == nil || !strings.Contains(err.Error(), "服务不可用") || !strings.Contains(err.Error(), "备用模型超载") {
+		t.Errorf("期望同时包含主模型与备用模型的错误，实际为%v", err)
+	}
+	if fallbackCalls != 1 {
+		t.Errorf("备用模型只应重试一次，实际为%d次", fallbackCalls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := model(ctx, "生成规则"); !errors.Is(err, primaryErr) {
+		t.Errorf("调用方取消时应直接返回主模型错误，实际为%v", err)
+	}
+	if fallbackCalls != 1 {
+		t.Errorf("调用方取消时不应降级，实际备用模型调用%d次", fallbackCalls)
+	}
+
+	if _, err := NewFallbackModel(nil, FallbackConfig{}); err == nil {
+		t.Error("主模型为空时应返回错误")
+	}
+}
+
+// TestSessionFallbackDegraded 测试分步生成中任一步骤降级都会标记在结果中
+func TestSessionFallbackDegraded(t *testing.T) {
+	model, err := NewFallbackModel(slowModel, FallbackConfig{
+		FallbackModel: func(ctx context.Context, prompt string) (string, error) {
+			return "快速规则", nil
+		},
+		Timeout: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("创建降级模型失败: %v", err)
+	}
+
+	manager := NewSessionManager(time.Minute)
+	ctx := context.Background()
+	sessionID, _, err := manager.StartGeneration(ctx,
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			return []Worldview{{ID: 1, Name: "星海帝国"}}, nil
+		}),
+		WithRuleGenerator(func(ctx context.Context, worldviews []Worldview) ([]Rule, error) {
+			output, err := model(ctx, "生成规则")
+			return []Rule{{WorldviewID: 1, Name: output}}, err
+		}),
+	)
+	if err != nil {
+		t.Fatalf("开始生成失败: %v", err)
+	}
+	story, err := manager.ContinueGeneration(ctx, sessionID, StageRule)
+	if err != nil {
+		t.Fatalf("生成规则失败: %v", err)
+	}
+	if !story.Degraded || story.Rules[0].Name != "快速规则" {
+		t.Errorf("规则步骤降级后应标记降级，实际为%+v", story)
+	}
+	story, err = manager.ContinueGeneration(ctx, sessionID, StageBackground)
+	if err != nil || !story.Degraded {
+		t.Errorf("后续步骤应保留降级标记，实际为%v %+v", err, story)
+	}
+}
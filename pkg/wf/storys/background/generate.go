@@ -3,6 +3,9 @@ package background
 import (
 	"context"
 	"errors"
+	"fmt"
+
+	"novelai/pkg/utils/redact"
 )
 
 // StoryOption 故事生成选项函数类型
@@ -19,6 +22,46 @@ type StoryOptions struct {
 	BackgroundGenerator func(context.Context, []Worldview, []Rule) ([]Background, error)
 	// 故事后处理函数，可以在生成完成后修改故事
 	PostProcessor func(context.Context, *Story) error
+	// 生成内容的风格，为空时不附加风格要求
+	Style Style
+	// 一致性校验模型函数，为空时不校验
+	ConsistencyModel ModelFunc
+	// 一致性校验不通过时重新生成规则的最大次数
+	MaxRegenerate int
+	// 幂等键，非空时有效期内同一用户（IdempotencyUserID）的重复请求直接返回首次生成的结果
+	IdempotencyKey string
+	// 幂等键所属的用户ID
+	IdempotencyUserID int64
+	// 实体抽取器，非空时在背景生成后自动抽取关键实体
+	EntityExtractor *EntityExtractor
+	// 引用校验模型函数，非空时规则与背景中的游离引用会触发一次重写
+	GroundingModel ModelFunc
+	// 质量评分器，非空时为生成的背景打分
+	QualityScorer *QualityScorer
+	// 背景综合得分下限，大于0时过滤低于该分数的背景
+	MinQualityOverall float64
+	// 候选数量，仅 GenerateCandidates 使用
+	Candidates int
+	// 同时生成的候选数，仅 GenerateCandidates 使用
+	CandidateParallelism int
+	// 脱敏器，非空时在后处理（保存）前对生成文本脱敏
+	Redactor *redact.Redactor
+	// 摘要模型函数，非空时为生成的世界观补充摘要
+	SummaryModel ModelFunc
+	// 摘要字数上限，小于等于0时使用 DefaultSummaryMaxChars
+	SummaryMaxChars int
+	// 后处理器，按注册顺序在后处理函数之前执行
+	PostProcessors []PostProcessor
+	// 后处理器报错时的处理策略，为空时中止
+	PostProcessErrorPolicy PostProcessErrorPolicy
+	// 配额检查器，非空时生成前按 QuotaUserID 检查每日配额
+	QuotaChecker *QuotaChecker
+	// 配额计量的用户ID
+	QuotaUserID int64
+	// 已有规则查询函数，非空时检测生成的规则与已有规则的重复度
+	ExistingRules func(context.Context, []Worldview) ([]Rule, error)
+	// 规则重复度阈值，小于等于0时使用 DefaultDuplicateThreshold
+	DuplicateThreshold float64
 }
 
 // WithWorldviewGenerator 设置世界观生成函数
@@ -100,6 +143,7 @@ func defaultOptions() *StoryOptions {
 }
 
 // Generate 生成一个故事及其相关背景设定
+// 各阶段失败时返回 *GenerationError，可通过 errors.As 提取阶段与错误类别
 // 参数:
 // - ctx: 上下文，用于控制生成过程的取消和超时
 // - options: 可变参数，用于自定义生成过程的各个方面
@@ -112,44 +156,146 @@ func Generate(ctx context.Context, options ...StoryOption) (Story, error) {
 		return Story{}, ctx.Err()
 	}
 
-	// 初始化默认选项
-	opts := defaultOptions()
+	opts, err := applyOptions(options)
+	if err != nil {
+		return Story{}, err
+	}
 
-	// 应用自定义选项
+	// 将风格传递给各生成函数
+	if opts.Style != "" {
+		ctx = withStyleContext(ctx, opts.Style)
+	}
+
+	if opts.IdempotencyKey != "" {
+		return defaultIdempotencyStore.do(ctx, opts.IdempotencyUserID, opts.IdempotencyKey, func() (Story, error) {
+			return generateWithQuota(ctx, opts)
+		})
+	}
+	return generateWithQuota(ctx, opts)
+}
+
+// applyOptions 在默认选项上依次应用自定义选项
+func applyOptions(options []StoryOption) (*StoryOptions, error) {
+	opts := defaultOptions()
 	for _, option := range options {
 		if err := option(opts); err != nil {
-			return Story{}, errors.New("应用选项失败: " + err.Error())
+			return nil, fmt.Errorf("应用选项失败: %w", err)
 		}
 	}
+	return opts, nil
+}
+
+// generateStory 按选项依次执行各生成阶段并应用后处理
+func generateStory(ctx context.Context, opts *StoryOptions) (Story, error) {
+	story, err := generateDraft(ctx, opts)
+	if err != nil {
+		return Story{}, err
+	}
 
+	// 依次应用后处理器与后处理函数，后处理器中的模型降级在保存前一并标记
+	postCtx, tracker := trackModelFallback(ctx)
+	if err := runPostProcessors(postCtx, opts, &story); err != nil {
+		return Story{}, err
+	}
+	story.Degraded = story.Degraded || tracker.used()
+	if err := opts.PostProcessor(ctx, &story); err != nil {
+		return Story{}, wrapStageError(StagePostProcess, ErrorKindValidation, err)
+	}
+
+	return story, nil
+}
+
+// generateDraft 按选项依次执行各生成阶段，不应用后处理
+func generateDraft(ctx context.Context, opts *StoryOptions) (Story, error) {
 	// 创建故事结构
 	story := Story{}
+	ctx, tracker := trackModelFallback(ctx)
 
 	// 生成世界观
-	worldviews, err := opts.WorldviewGenerator(ctx)
+	worldviews, err := generateWorldviewStage(ctx, opts)
 	if err != nil {
-		return Story{}, errors.New("生成世界观失败: " + err.Error())
+		return Story{}, err
 	}
 	story.WorldViews = worldviews
 
-	// 生成规则
-	rules, err := opts.RuleGenerator(ctx, worldviews)
+	// 生成规则（启用一致性校验时矛盾会触发重生成）
+	rules, err := generateRuleStage(ctx, opts, worldviews)
 	if err != nil {
-		return Story{}, errors.New("生成规则失败: " + err.Error())
+		return Story{}, err
 	}
 	story.Rules = rules
+	if story.DuplicateWarnings, err = checkDuplicateRuleStage(ctx, opts, worldviews, rules); err != nil {
+		return Story{}, err
+	}
 
 	// 生成背景
-	backgrounds, err := opts.BackgroundGenerator(ctx, worldviews, rules)
-	if err != nil {
-		return Story{}, errors.New("生成背景失败: " + err.Error())
+	if err := generateBackgroundStage(ctx, opts, &story); err != nil {
+		return Story{}, err
 	}
-	story.Backgrounds = backgrounds
 
-	// 应用后处理
-	if err := opts.PostProcessor(ctx, &story); err != nil {
-		return Story{}, errors.New("后处理失败: " + err.Error())
+	if opts.Redactor != nil {
+		redactStory(opts.Redactor, &story)
 	}
 
+	story.Degraded = tracker.used()
 	return story, nil
 }
+
+// generateWorldviewStage 生成世界观，启用摘要时补充摘要
+func generateWorldviewStage(ctx context.Context, opts *StoryOptions) ([]Worldview, error) {
+	worldviews, err := opts.WorldviewGenerator(ctx)
+	if err != nil {
+		return nil, wrapStageError(StageWorldview, ErrorKindModel, err)
+	}
+	if opts.SummaryModel != nil {
+		if err := summarizeWorldviews(ctx, opts.SummaryModel, opts.SummaryMaxChars, worldviews); err != nil {
+			return nil, wrapStageError(StageWorldview, ErrorKindModel, err)
+		}
+	}
+	return worldviews, nil
+}
+
+// generateRuleStage 基于世界观生成规则，并按选项做一致性校验与引用校验
+func generateRuleStage(ctx context.Context, opts *StoryOptions, worldviews []Worldview) ([]Rule, error) {
+	rules, err := generateConsistentRules(ctx, opts, worldviews)
+	if err != nil {
+		return nil, wrapStageError(StageRule, ErrorKindModel, err)
+	}
+	if opts.GroundingModel != nil {
+		if err := groundRules(ctx, opts.GroundingModel, worldviews, rules); err != nil {
+			return nil, wrapStageError(StageRule, ErrorKindModel, err)
+		}
+	}
+	return rules, nil
+}
+
+// generateBackgroundStage 基于故事中已有的世界观与规则生成背景，并按选项打分与抽取实体
+func generateBackgroundStage(ctx context.Context, opts *StoryOptions, story *Story) error {
+	backgrounds, err := opts.BackgroundGenerator(ctx, story.WorldViews, story.Rules)
+	if err != nil {
+		return wrapStageError(StageBackground, ErrorKindModel, err)
+	}
+	if opts.GroundingModel != nil {
+		if err := groundBackgrounds(ctx, opts.GroundingModel, story.WorldViews, backgrounds); err != nil {
+			return wrapStageError(StageBackground, ErrorKindModel, err)
+		}
+	}
+	if opts.QualityScorer != nil {
+		backgrounds, err = scoreBackgrounds(ctx, opts.QualityScorer, opts.MinQualityOverall, backgrounds)
+		if err != nil {
+			return wrapStageError(StageBackground, ErrorKindModel, err)
+		}
+	}
+	story.Backgrounds = backgrounds
+
+	// 抽取背景中的关键实体
+	story.Entities = nil
+	if opts.EntityExtractor != nil {
+		entities, err := extractBackgroundEntities(ctx, opts.EntityExtractor, backgrounds)
+		if err != nil {
+			return wrapStageError(StageBackground, ErrorKindModel, err)
+		}
+		story.Entities = entities
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestGenerateErrorClassification 测试各阶段错误被分类为 GenerationError
+func TestGenerateErrorClassification(t *testing.T) {
+	cause := errors.New("模拟错误")
+
+	tests := []struct {
+		name      string
+		option    StoryOption
+		wantStage GenerationStage
+		wantKind  GenerationErrorKind
+	}{
+		{
+			name: "世界观模型错误",
+			option: WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+				return nil, cause
+			}),
+			wantStage: StageWorldview,
+			wantKind:  ErrorKindModel,
+		},
+		{
+			name: "规则解析错误",
+			option: WithRuleGenerator(func(ctx context.Context, w []Worldview) ([]Rule, error) {
+				return nil, NewGenerationError("", ErrorKindParse, cause)
+			}),
+			wantStage: StageRule,
+			wantKind:  ErrorKindParse,
+		},
+		{
+			name: "背景保存错误",
+			option: WithBackgroundGenerator(func(ctx context.Context, w []Worldview, r []Rule) ([]Background, error) {
+				return nil, NewGenerationError(StageBackground, ErrorKindSave, cause)
+			}),
+			wantStage: StageBackground,
+			wantKind:  ErrorKindSave,
+		},
+		{
+			name: "后处理校验错误",
+			option: WithPostProcessor(func(ctx context.Context, s *Story) error {
+				return cause
+			}),
+			wantStage: StagePostProcess,
+			wantKind:  ErrorKindValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(context.Background(), tt.option)
+
+			var genErr *GenerationError
+			if !errors.As(err, &genErr) {
+				t.Fatalf("期望返回GenerationError，实际为%T: %v", err, err)
+			}
+			if genErr.Stage != tt.wantStage {
+				t.Errorf("期望阶段为%s，实际为%s", tt.wantStage, genErr.Stage)
+			}
+			if genErr.Kind != tt.wantKind {
+				t.Errorf("期望类别为%s，实际为%s", tt.wantKind, genErr.Kind)
+			}
+			if !errors.Is(err, cause) {
+				t.Errorf("应能通过errors.Is找到原始错误")
+			}
+		})
+	}
+}
+
+// TestGenerationErrorMessage 测试错误信息保持原有格式
+func TestGenerationErrorMessage(t *testing.T) {
+	err := NewGenerationError(StageBackground, ErrorKindModel, errors.New("连接被拒绝"))
+	if err.Error() != "生成背景失败: 连接被拒绝" {
+		t.Errorf("错误信息格式不符: %s", err.Error())
+	}
+}
@@ -0,0 +1,162 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// quotedTermPattern 匹配书名号、引号等括起来的专有名词，作为粗提取的术语与引用
+var quotedTermPattern = regexp.MustCompile(`[「《“"【]([^」》”"】\n]{1,20})[」》”"】]`)
+
+// groundingRewritePrompt 引用校验重写提示词模板，参数依次为世界观设定、关键术语、游离引用、原文
+const groundingRewritePrompt = `请与给定世界观保持一致，重写下面的设定内容。
+世界观设定：
+%s
+世界观关键术语：%s
+以下概念在世界观中并不存在，请删除或替换为世界观中已有的概念：%s
+原文：
+%s
+只输出重写后的内容，不要输出任何解释。`
+
+// ExtractTerms 从世界观名称与描述中粗提取关键术语
+// 名称整体作为术语，描述中被引号或书名号括起来的词也作为术语，结果已去重
+func ExtractTerms(worldviews []Worldview) []string {
+	var terms []string
+	seen := make(map[string]struct{})
+	add := func(term string) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			return
+		}
+		if _, ok := seen[term]; ok {
+			return
+		}
+		seen[term] = struct{}{}
+		terms = append(terms, term)
+	}
+
+	var walk func([]Worldview)
+	walk = func(list []Worldview) {
+		for _, wv := range list {
+			add(wv.Name)
+			for _, term := range quotedTerms(wv.Description) {
+				add(term)
+			}
+			walk(wv.Children)
+		}
+	}
+	walk(worldviews)
+	return terms
+}
+
+// FindUngroundedReferences 找出生成内容中引用但世界观里并不存在的概念
+// 只检查被引号或书名号括起来的引用，在世界观名称、描述中都找不到的视为游离引用
+func FindUngroundedReferences(worldviews []Worldview, text string) []string {
+	var corpus strings.Builder
+	var walk func([]Worldview)
+	walk = func(list []Worldview) {
+		for _, wv := range list {
+			corpus.WriteString(wv.Name)
+			corpus.WriteString("\n")
+			corpus.WriteString(wv.Description)
+			corpus.WriteString("\n")
+			walk(wv.Children)
+		}
+	}
+	walk(worldviews)
+	known := corpus.String()
+
+	var ungrounded []string
+	seen := make(map[string]struct{})
+	for _, ref := range quotedTerms(text) {
+		if _, ok := seen[ref]; ok || strings.Contains(known, ref) {
+			continue
+		}
+		seen[ref] = struct{}{}
+		ungrounded = append(ungrounded, ref)
+	}
+	return ungrounded
+}
+
+// quotedTerms 提取文本中被引号或书名号括起来的词
+func quotedTerms(text string) []string {
+	matches := quotedTermPattern.FindAllStringSubmatch(text, -1)
+	terms := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if term := strings.TrimSpace(m[1]); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// RewriteUngrounded 内容含游离引用时调用模型按世界观重写一次
+// 参数:
+// - ctx: 上下文
+// - model: 调用大模型的函数
+// - worldviews: 作为依据的世界观
+// - text: 待检查的生成内容
+// 返回:
+// - 重写后的内容，无游离引用时原样返回
+// - 是否触发了重写
+// - 模型调用失败或返回空内容时返回错误
+func RewriteUngrounded(ctx context.Context, model ModelFunc, worldviews []Worldview, text string) (string, bool, error) {
+	refs := FindUngroundedReferences(worldviews, text)
+	if len(refs) == 0 {
+		return text, false, nil
+	}
+
+	var setting strings.Builder
+	for _, wv := range worldviews {
+		setting.WriteString(fmt.Sprintf("- %s：%s\n", wv.Name, wv.Description))
+	}
+	output, err := model(ctx, fmt.Sprintf(groundingRewritePrompt,
+		setting.String(), strings.Join(ExtractTerms(worldviews), "、"), strings.Join(refs, "、"), text))
+	if err != nil {
+		return "", true, NewGenerationError("", ErrorKindModel, err)
+	}
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return "", true, NewGenerationError("", ErrorKindParse, errors.New("重写结果为空"))
+	}
+	return output, true, nil
+}
+
+// WithGroundingRewrite 启用规则与背景生成后的引用校验
+// 描述中出现世界观里不存在的引用时，调用模型按世界观重写一次
+func WithGroundingRewrite(model ModelFunc) StoryOption {
+	return func(opts *StoryOptions) error {
+		if model == nil {
+			return errors.New("引用校验模型函数不能为空")
+		}
+		opts.GroundingModel = model
+		return nil
+	}
+}
+
+// groundRules 对规则描述做引用校验重写
+func groundRules(ctx context.Context, model ModelFunc, worldviews []Worldview, rules []Rule) error {
+	for i := range rules {
+		description, _, err := RewriteUngrounded(ctx, model, worldviews, rules[i].Description)
+		if err != nil {
+			return err
+		}
+		rules[i].Description = description
+	}
+	return nil
+}
+
+// groundBackgrounds 对背景描述做引用校验重写
+func groundBackgrounds(ctx context.Context, model ModelFunc, worldviews []Worldview, backgrounds []Background) error {
+	for i := range backgrounds {
+		description, _, err := RewriteUngrounded(ctx, model, worldviews, backgrounds[i].Description)
+		if err != nil {
+			return err
+		}
+		backgrounds[i].Description = description
+	}
+	return nil
+}
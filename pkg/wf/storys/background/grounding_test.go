@@ -0,0 +1,104 @@
+package background
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// groundingWorldviews 引用校验测试使用的世界观
+func groundingWorldviews() []Worldview {
+	return []Worldview{{
+		ID:          1,
+		Name:        "灵潮大陆",
+		Description: "大陆上的修士依靠「灵潮」修炼，最高学府是《天衍书院》",
+	}}
+}
+
+// TestExtractTerms 测试从世界观名称与描述中粗提取术语
+func TestExtractTerms(t *testing.T) {
+	terms := ExtractTerms(groundingWorldviews())
+	want := []string{"灵潮大陆", "灵潮", "天衍书院"}
+	if strings.Join(terms, ",") != strings.Join(want, ",") {
+		t.Errorf("期望术语为%v，实际为%v", want, terms)
+	}
+}
+
+// TestRewriteUngroundedTriggered 测试含游离引用时触发重写
+func TestRewriteUngroundedTriggered(t *testing.T) {
+	text := "修士在「天衍书院」学习，并借助「魔导炉」驱动「灵潮」"
+
+	var prompt string
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return " 修士在「天衍书院」学习如何引导「灵潮」 ", nil
+	}
+
+	got, rewritten, err := RewriteUngrounded(context.Background(), fakeModel, groundingWorldviews(), text)
+	if err != nil {
+		t.Fatalf("重写失败: %v", err)
+	}
+	if !rewritten {
+		t.Fatal("期望含游离引用时触发重写")
+	}
+	if got != "修士在「天衍书院」学习如何引导「灵潮」" {
+		t.Errorf("期望返回重写后的内容，实际为%s", got)
+	}
+	if !strings.Contains(prompt, "请与给定世界观保持一致") || !strings.Contains(prompt, "魔导炉") {
+		t.Errorf("期望提示词要求保持一致并指出游离引用，实际为%s", prompt)
+	}
+	if refs := FindUngroundedReferences(groundingWorldviews(), text); len(refs) != 1 || refs[0] != "魔导炉" {
+		t.Errorf("期望只有魔导炉是游离引用，实际为%v", refs)
+	}
+}
+
+// TestRewriteUngroundedPassThrough 测试内容一致时直接通过，不调用模型
+func TestRewriteUngroundedPassThrough(t *testing.T) {
+	text := "修士在「天衍书院」学习引导「灵潮」，这是没有引用的普通描述"
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		t.Fatal("内容一致时不应调用模型")
+		return "", nil
+	}
+
+	got, rewritten, err := RewriteUngrounded(context.Background(), fakeModel, groundingWorldviews(), text)
+	if err != nil {
+		t.Fatalf("校验失败: %v", err)
+	}
+	if rewritten || got != text {
+		t.Errorf("期望原样通过，实际rewritten=%v，内容为%s", rewritten, got)
+	}
+}
+
+// TestGenerateWithGroundingRewrite 测试生成流程中规则的游离引用被重写
+func TestGenerateWithGroundingRewrite(t *testing.T) {
+	calls := 0
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		calls++
+		return "修士只能在「灵潮」涨落时突破", nil
+	}
+
+	story, err := Generate(context.Background(),
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			return groundingWorldviews(), nil
+		}),
+		WithRuleGenerator(func(ctx context.Context, worldviews []Worldview) ([]Rule, error) {
+			return []Rule{
+				{ID: 1, Name: "突破", Description: "修士需要吞服「九转金丹」才能突破"},
+				{ID: 2, Name: "学府", Description: "《天衍书院》每十年招生一次"},
+			}, nil
+		}),
+		WithGroundingRewrite(fakeModel),
+	)
+	if err != nil {
+		t.Fatalf("生成故事失败: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("期望只重写1条规则，实际调用模型%d次", calls)
+	}
+	if story.Rules[0].Description != "修士只能在「灵潮」涨落时突破" {
+		t.Errorf("期望游离引用的规则被重写，实际为%s", story.Rules[0].Description)
+	}
+	if story.Rules[1].Description != "《天衍书院》每十年招生一次" {
+		t.Errorf("期望一致的规则保持不变，实际为%s", story.Rules[1].Description)
+	}
+}
@@ -0,0 +1,139 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyTTL 幂等键默认有效期
+const DefaultIdempotencyTTL = 10 * time.Minute
+
+// idempotencyEntry 幂等键对应的生成结果
+type idempotencyEntry struct {
+	done     chan struct{} // 首次生成完成后关闭
+	story    Story         // 生成结果
+	err      error         // 生成错误
+	expireAt time.Time     // 过期时间，生成完成后设置
+}
+
+// idempotencyScopedKey 按用户隔离的幂等键
+type idempotencyScopedKey struct {
+	userID int64  // 发起请求的用户ID
+	key    string // 调用方传入的幂等键
+}
+
+// idempotencyStore 带 TTL 的幂等键内存存储
+type idempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[idempotencyScopedKey]*idempotencyEntry
+	lastSweep time.Time // 上次清理过期记录的时间
+}
+
+// defaultIdempotencyStore 全局幂等键存储
+var defaultIdempotencyStore = &idempotencyStore{
+	ttl:     DefaultIdempotencyTTL,
+	entries: make(map[idempotencyScopedKey]*idempotencyEntry),
+}
+
+// SetIdempotencyTTL 设置幂等键有效期，并清空现有记录
+// ttl 小于等于0时使用默认值
+func SetIdempotencyTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	defaultIdempotencyStore.mu.Lock()
+	defer defaultIdempotencyStore.mu.Unlock()
+
+	defaultIdempotencyStore.ttl = ttl
+	defaultIdempotencyStore.entries = make(map[idempotencyScopedKey]*idempotencyEntry)
+	defaultIdempotencyStore.lastSweep = time.Time{}
+}
+
+// WithIdempotencyKey 设置发起请求的用户与幂等键
+// 有效期内同一用户相同幂等键的重复请求直接返回首次生成的结果，不会重新生成；首次生成失败不记录结果。
+// 幂等键按用户隔离，不同用户使用相同的键互不影响，因此必须指定用户
+func WithIdempotencyKey(userID int64, key string) StoryOption {
+	return func(opts *StoryOptions) error {
+		if userID <= 0 {
+			return errors.New("幂等键必须指定有效的用户ID")
+		}
+		if key == "" {
+			return errors.New("幂等键不能为空")
+		}
+		opts.IdempotencyUserID = userID
+		opts.IdempotencyKey = key
+		return nil
+	}
+}
+
+// do 按用户与幂等键执行生成
+// 同一用户同一幂等键的并发请求等待首次生成完成后共享其结果
+func (s *idempotencyStore) do(ctx context.Context, userID int64, idempotencyKey string, generate func() (Story, error)) (Story, error) {
+	key := idempotencyScopedKey{userID: userID, key: idempotencyKey}
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		entry, ok := s.entries[key]
+		if ok && entry.expired(now) {
+			delete(s.entries, key)
+			ok = false
+		}
+		if !ok {
+			s.sweepLocked(now)
+			entry = &idempotencyEntry{done: make(chan struct{})}
+			s.entries[key] = entry
+			s.mu.Unlock()
+			return s.run(key, entry, generate)
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-entry.done:
+			if entry.err == nil {
+				return entry.story, nil
+			}
+			// 首次生成失败的记录已被移除，重新尝试
+		case <-ctx.Done():
+			return Story{}, ctx.Err()
+		}
+	}
+}
+
+// expired 判断已完成的记录是否过期，生成中的记录不会过期
+func (e *idempotencyEntry) expired(now time.Time) bool {
+	return !e.expireAt.IsZero() && now.After(e.expireAt)
+}
+
+// sweepLocked 清理过期记录，每个有效期内最多执行一次，调用方需持有锁
+func (s *idempotencyStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// run 执行首次生成并记录结果
+func (s *idempotencyStore) run(key idempotencyScopedKey, entry *idempotencyEntry, generate func() (Story, error)) (Story, error) {
+	story, err := generate()
+
+	s.mu.Lock()
+	entry.story, entry.err = story, err
+	if err != nil {
+		delete(s.entries, key)
+	} else {
+		entry.expireAt = time.Now().Add(s.ttl)
+	}
+	s.mu.Unlock()
+	close(entry.done)
+
+	return story, err
+}
@@ -0,0 +1,143 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGenerateIdempotencyKey 测试相同幂等键两次请求只触发一次实际生成
+func TestGenerateIdempotencyKey(t *testing.T) {
+	SetIdempotencyTTL(time.Minute)
+	defer SetIdempotencyTTL(0)
+
+	var calls int32
+	generator := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return []Worldview{{ID: uint(n), Name: "世界观"}}, nil
+	})
+
+	first, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-1"))
+	if err != nil {
+		t.Fatalf("首次生成失败: %v", err)
+	}
+	second, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-1"))
+	if err != nil {
+		t.Fatalf("重复请求失败: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("期望只生成1次，实际为%d次", calls)
+	}
+	if len(second.WorldViews) != 1 || second.WorldViews[0].ID != first.WorldViews[0].ID {
+		t.Errorf("重复请求应返回首次结果，首次为%+v，实际为%+v", first.WorldViews, second.WorldViews)
+	}
+
+	// 不同幂等键重新生成
+	if _, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-2")); err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("不同幂等键应重新生成，实际生成%d次", calls)
+	}
+}
+
+// TestGenerateIdempotencyConcurrent 测试相同幂等键并发请求共享一次生成
+func TestGenerateIdempotencyConcurrent(t *testing.T) {
+	SetIdempotencyTTL(time.Minute)
+	defer SetIdempotencyTTL(0)
+
+	var calls int32
+	generator := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return []Worldview{{ID: 1}}, nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-concurrent")); err != nil {
+				t.Errorf("生成失败: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if atomic.LoadInt32(&calls) != 1 {
+		t.Errorf("期望只生成1次，实际为%d次", calls)
+	}
+}
+
+// TestGenerateIdempotencyErrorAndExpire 测试失败结果不缓存、过期后重新生成
+func TestGenerateIdempotencyErrorAndExpire(t *testing.T) {
+	SetIdempotencyTTL(50 * time.Millisecond)
+	defer SetIdempotencyTTL(0)
+
+	var calls int32
+	generator := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, errors.New("模型不可达")
+		}
+		return []Worldview{{ID: 1}}, nil
+	})
+
+	if _, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-retry")); err == nil {
+		t.Fatal("期望首次生成失败")
+	}
+	if _, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-retry")); err != nil {
+		t.Fatalf("失败后重试应重新生成: %v", err)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if _, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-retry")); err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("期望生成3次，实际为%d次", calls)
+	}
+}
+
+// TestGenerateIdempotencyPerUser 测试幂等键按用户隔离，且插入新记录时清理过期记录
+func TestGenerateIdempotencyPerUser(t *testing.T) {
+	SetIdempotencyTTL(20 * time.Millisecond)
+	defer SetIdempotencyTTL(0)
+
+	var calls int32
+	generator := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return []Worldview{{ID: uint(n)}}, nil
+	})
+
+	first, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-user"))
+	if err != nil {
+		t.Fatalf("用户1生成失败: %v", err)
+	}
+	second, err := Generate(context.Background(), generator, WithIdempotencyKey(2, "req-user"))
+	if err != nil {
+		t.Fatalf("用户2生成失败: %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 2 || first.WorldViews[0].ID == second.WorldViews[0].ID {
+		t.Errorf("不同用户的相同幂等键不应共享结果，实际生成%d次", calls)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, err := Generate(context.Background(), generator, WithIdempotencyKey(1, "req-other")); err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	// 未指定用户的幂等键被拒绝
+	if _, err := Generate(context.Background(), generator, WithIdempotencyKey(0, "req-anon")); err == nil {
+		t.Error("未指定用户时应拒绝幂等键")
+	}
+
+	defaultIdempotencyStore.mu.Lock()
+	remaining := len(defaultIdempotencyStore.entries)
+	defaultIdempotencyStore.mu.Unlock()
+	if remaining != 1 {
+		t.Errorf("过期记录应在插入时被清理，实际剩余%d条", remaining)
+	}
+}
@@ -0,0 +1,40 @@
+package background
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"novelai/pkg/utils/jsonrepair"
+)
+
+// errJSONNotFound 模型输出中没有期望的 JSON 结构
+var errJSONNotFound = errors.New("未找到JSON")
+
+// decodeModelJSON 从模型输出中提取 JSON 对象或数组并解码到 v
+// open 为期望的顶层结构起始符 '{' 或 '['；兼容模型在JSON前后附带说明文字或代码块标记的情况，
+// 直接解码失败时经 jsonrepair.Repair 修复尾逗号、单引号、截断等小毛病后重试
+func decodeModelJSON(s string, open byte, v interface{}) error {
+	closing := byte('}')
+	if open == '[' {
+		closing = ']'
+	}
+	start := strings.IndexByte(s, open)
+	if start < 0 {
+		return fmt.Errorf("%w: %s", errJSONNotFound, s)
+	}
+	candidate := s[start:]
+	if end := strings.LastIndexByte(s, closing); end > start {
+		candidate = s[start : end+1]
+	}
+
+	err := json.Unmarshal([]byte(candidate), v)
+	if err == nil {
+		return nil
+	}
+	if json.Unmarshal([]byte(jsonrepair.Repair(s[start:])), v) == nil {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+package background
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestDecodeModelJSON 测试从带说明文字的模型输出中提取并修复JSON
+func TestDecodeModelJSON(t *testing.T) {
+	var obj struct {
+		Name string `json:"name"`
+	}
+	if err := decodeModelJSON("结果如下：\n```json\n{'name': '浮空城',}\n```", '{', &obj); err != nil || obj.Name != "浮空城" {
+		t.Errorf("期望修复后解析出名称，实际为%+v, %v", obj, err)
+	}
+
+	var list []string
+	if err := decodeModelJSON(`标签：["奇幻", "城市",`, '[', &list); err != nil || len(list) != 2 {
+		t.Errorf("期望补全截断的数组，实际为%v, %v", list, err)
+	}
+
+	if err := decodeModelJSON("没有JSON", '{', &obj); !errors.Is(err, errJSONNotFound) {
+		t.Errorf("期望返回未找到JSON错误，实际为%v", err)
+	}
+}
@@ -9,12 +9,15 @@ package background
 // 包含名称、描述、标签、父ID及子世界观
 // 可用于树状世界观体系
 type Worldview struct {
-	ID          uint        // 主键ID
-	Name        string      // 世界观名称
-	Description string      // 世界观详细描述
-	Tag         string      // 标签，多个标签用英文逗号分隔
-	ParentID    uint        // 父世界观ID，0表示主世界观，否则为子世界观
-	Children    []Worldview // 子世界观列表
+	ID          uint               // 主键ID
+	Name        string             // 世界观名称
+	Description string             // 世界观详细描述
+	Tag         string             // 标签，多个标签用英文逗号分隔
+	ParentID    uint               // 父世界观ID，0表示主世界观，否则为子世界观
+	Children    []Worldview        // 子世界观列表
+	CoverPrompt string             // 封面图像提示词（英文），供文生图模型使用
+	Summary     string             // 简短摘要，供列表展示
+	Sections    []WorldviewSection // 按章节组织的设定，结构化生成时填充
 }
 
 // Rule 规则实体，描述世界观下的运行法则
@@ -44,18 +47,23 @@ type Rule struct {
 // 包含名称、描述、标签、父ID及子背景
 // 可用于树状背景体系
 type Background struct {
-	ID          uint         // 主键ID
-	WorldviewID uint         // 所属世界观ID
-	Name        string       // 背景名称
-	Description string       // 背景详细描述
-	Tag         string       // 标签，多个标签用英文逗号分隔
-	ParentID    uint         // 父背景ID，0表示主背景，否则为子背景
-	Children    []Background // 子背景列表
+	ID          uint          // 主键ID
+	WorldviewID uint          // 所属世界观ID
+	Name        string        // 背景名称
+	Description string        // 背景详细描述
+	Tag         string        // 标签，多个标签用英文逗号分隔
+	ParentID    uint          // 父背景ID，0表示主背景，否则为子背景
+	Children    []Background  // 子背景列表
+	Quality     *QualityScore // 质量评分，启用质量评分时填充
 }
 
 type Story struct {
-	ID          uint // 主键ID
-	WorldViews  []Worldview
-	Rules       []Rule
-	Backgrounds []Background
+	ID                    uint // 主键ID
+	WorldViews            []Worldview
+	Rules                 []Rule
+	Backgrounds           []Background
+	Entities              []Entity           // 从背景中抽取的关键实体
+	SkippedPostProcessors []string           // 跳过策略下出错而被跳过的后处理器名称
+	DuplicateWarnings     []DuplicateWarning // 与已有规则高重复的提示，启用重复检测时填充
+	Degraded              bool               // 是否有模型调用因主模型超时或失败降级到了备用模型
 }
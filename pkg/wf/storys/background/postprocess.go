@@ -0,0 +1,175 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"novelai/pkg/utils/redact"
+)
+
+// PostProcessor 可插拔的后处理器，生成完成后按注册顺序依次作用于故事
+type PostProcessor interface {
+	// Name 返回后处理器名称，用于错误信息与跳过记录
+	Name() string
+	// Process 就地修改故事，返回错误时按 PostProcessErrorPolicy 中止或跳过
+	Process(ctx context.Context, story *Story) error
+}
+
+// PostProcessErrorPolicy 后处理器报错时的处理策略
+type PostProcessErrorPolicy string
+
+// 后处理错误策略常量
+const (
+	PostProcessAbort PostProcessErrorPolicy = "abort" // 中止生成并返回错误（默认）
+	PostProcessSkip  PostProcessErrorPolicy = "skip"  // 跳过该后处理器继续执行后续后处理器
+)
+
+// funcPostProcessor 由函数实现的后处理器
+type funcPostProcessor struct {
+	name string
+	fn   func(context.Context, *Story) error
+}
+
+// Name 实现PostProcessor接口
+func (p *funcPostProcessor) Name() string { return p.name }
+
+// Process 实现PostProcessor接口
+func (p *funcPostProcessor) Process(ctx context.Context, story *Story) error { return p.fn(ctx, story) }
+
+// NewPostProcessor 用函数创建后处理器
+func NewPostProcessor(name string, fn func(context.Context, *Story) error) PostProcessor {
+	return &funcPostProcessor{name: name, fn: fn}
+}
+
+// WithPostProcessors 追加后处理器，多次调用按调用顺序累加
+// 后处理器在各生成阶段完成之后、WithPostProcessor 设置的后处理函数（如保存）之前执行
+func WithPostProcessors(processors ...PostProcessor) StoryOption {
+	return func(opts *StoryOptions) error {
+		for _, p := range processors {
+			if p == nil {
+				return errors.New("后处理器不能为空")
+			}
+		}
+		opts.PostProcessors = append(opts.PostProcessors, processors...)
+		return nil
+	}
+}
+
+// WithPostProcessErrorPolicy 设置后处理器报错时的处理策略，默认中止
+func WithPostProcessErrorPolicy(policy PostProcessErrorPolicy) StoryOption {
+	return func(opts *StoryOptions) error {
+		if policy != PostProcessAbort && policy != PostProcessSkip {
+			return fmt.Errorf("未知的后处理错误策略: %s", policy)
+		}
+		opts.PostProcessErrorPolicy = policy
+		return nil
+	}
+}
+
+// runPostProcessors 按注册顺序执行后处理器
+// 跳过策略下出错的后处理器名称记录在 Story.SkippedPostProcessors 中
+func runPostProcessors(ctx context.Context, opts *StoryOptions, story *Story) error {
+	for _, p := range opts.PostProcessors {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.Process(ctx, story); err != nil {
+			if opts.PostProcessErrorPolicy == PostProcessSkip {
+				story.SkippedPostProcessors = append(story.SkippedPostProcessors, p.Name())
+				continue
+			}
+			return wrapStageError(StagePostProcess, ErrorKindValidation, fmt.Errorf("后处理器 %s: %w", p.Name(), err))
+		}
+	}
+	return nil
+}
+
+// RedactionPostProcessor 返回对生成文本脱敏的后处理器，redactor 为空时使用默认脱敏规则
+func RedactionPostProcessor(redactor *redact.Redactor) PostProcessor {
+	if redactor == nil {
+		redactor = redact.NewRedactor()
+	}
+	return NewPostProcessor("redaction", func(ctx context.Context, story *Story) error {
+		redactStory(redactor, story)
+		return nil
+	})
+}
+
+// TagNormalizePostProcessor 返回规范化标签的后处理器
+// 中英文逗号统一为英文逗号，去掉空白与空标签，重复标签（忽略大小写）只保留第一个
+func TagNormalizePostProcessor() PostProcessor {
+	return NewPostProcessor("tag_normalize", func(ctx context.Context, story *Story) error {
+		normalizeWorldviewTags(story.WorldViews)
+		normalizeRuleTags(story.Rules)
+		normalizeBackgroundTags(story.Backgrounds)
+		return nil
+	})
+}
+
+// SummaryPostProcessor 返回为世界观生成摘要的后处理器，maxChars 小于等于0时使用 DefaultSummaryMaxChars
+func SummaryPostProcessor(model ModelFunc, maxChars int) PostProcessor {
+	return NewPostProcessor("summary", func(ctx context.Context, story *Story) error {
+		if model == nil {
+			return errors.New("摘要模型函数不能为空")
+		}
+		return summarizeWorldviews(ctx, model, maxChars, story.WorldViews)
+	})
+}
+
+// QualityPostProcessor 返回为背景打分的后处理器，minOverall 大于0时过滤综合得分低于它的背景
+func QualityPostProcessor(model ModelFunc, minOverall float64) PostProcessor {
+	scorer := NewQualityScorer(model)
+	return NewPostProcessor("quality", func(ctx context.Context, story *Story) error {
+		backgrounds, err := scoreBackgrounds(ctx, scorer, minOverall, story.Backgrounds)
+		if err != nil {
+			return err
+		}
+		story.Backgrounds = backgrounds
+		return nil
+	})
+}
+
+// normalizeTagList 规范化以逗号分隔的标签字符串
+func normalizeTagList(s string) string {
+	tags := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '，' }) {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		key := strings.ToLower(tag)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		tags = append(tags, tag)
+	}
+	return strings.Join(tags, ",")
+}
+
+// normalizeWorldviewTags 递归规范化世界观标签
+func normalizeWorldviewTags(worldviews []Worldview) {
+	for i := range worldviews {
+		worldviews[i].Tag = normalizeTagList(worldviews[i].Tag)
+		normalizeWorldviewTags(worldviews[i].Children)
+	}
+}
+
+// normalizeRuleTags 递归规范化规则标签
+func normalizeRuleTags(rules []Rule) {
+	for i := range rules {
+		rules[i].Tag = normalizeTagList(rules[i].Tag)
+		normalizeRuleTags(rules[i].Children)
+	}
+}
+
+// normalizeBackgroundTags 递归规范化背景标签
+func normalizeBackgroundTags(backgrounds []Background) {
+	for i := range backgrounds {
+		backgrounds[i].Tag = normalizeTagList(backgrounds[i].Tag)
+		normalizeBackgroundTags(backgrounds[i].Children)
+	}
+}
@@ -0,0 +1,83 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestGeneratePostProcessors 测试注册的后处理器按顺序作用于生成结果且各自的修改都生效
+func TestGeneratePostProcessors(t *testing.T) {
+	var order []string
+	appendSuffix := NewPostProcessor("suffix", func(ctx context.Context, story *Story) error {
+		order = append(order, "suffix")
+		story.WorldViews[0].Name += "·续"
+		return nil
+	})
+	var savedName string
+	story, err := Generate(context.Background(),
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			return []Worldview{{ID: 1, Name: "星海帝国", Tag: " 科幻，太空 ,科幻,,帝国"}}, nil
+		}),
+		WithPostProcessors(TagNormalizePostProcessor()),
+		WithPostProcessors(NewPostProcessor("upper_tag", func(ctx context.Context, story *Story) error {
+			order = append(order, "upper_tag")
+			// 依赖前一个后处理器规范化后的标签
+			if story.WorldViews[0].Tag != "科幻,太空,帝国" {
+				return errors.New("标签尚未规范化: " + story.WorldViews[0].Tag)
+			}
+			story.WorldViews[0].Tag = strings.ReplaceAll(story.WorldViews[0].Tag, "科幻", "SF")
+			return nil
+		}), appendSuffix),
+		WithPostProcessor(func(ctx context.Context, story *Story) error {
+			savedName = story.WorldViews[0].Name
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if strings.Join(order, ",") != "upper_tag,suffix" {
+		t.Errorf("后处理器执行顺序不正确: %v", order)
+	}
+	if got := story.WorldViews[0]; got.Tag != "SF,太空,帝国" || got.Name != "星海帝国·续" {
+		t.Errorf("后处理器的修改未全部生效: %+v", got)
+	}
+	if savedName != "星海帝国·续" {
+		t.Errorf("后处理函数应在后处理器之后执行，实际看到%s", savedName)
+	}
+}
+
+// TestGeneratePostProcessorErrorPolicy 测试后处理器报错时按策略中止或跳过
+func TestGeneratePostProcessorErrorPolicy(t *testing.T) {
+	failing := NewPostProcessor("failing", func(ctx context.Context, story *Story) error {
+		return errors.New("打分服务不可用")
+	})
+	marked := false
+	marker := NewPostProcessor("marker", func(ctx context.Context, story *Story) error {
+		marked = true
+		return nil
+	})
+
+	_, err := Generate(context.Background(), WithPostProcessors(failing, marker))
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Stage != StagePostProcess {
+		t.Fatalf("默认策略应中止并返回后处理错误，实际为%v", err)
+	}
+	if !strings.Contains(err.Error(), "failing") || marked {
+		t.Errorf("中止后不应执行后续后处理器，错误为%v", err)
+	}
+
+	story, err := Generate(context.Background(), WithPostProcessors(failing, marker), WithPostProcessErrorPolicy(PostProcessSkip))
+	if err != nil {
+		t.Fatalf("跳过策略不应返回错误: %v", err)
+	}
+	if !marked || len(story.SkippedPostProcessors) != 1 || story.SkippedPostProcessors[0] != "failing" {
+		t.Errorf("期望跳过failing并继续执行，实际跳过%v，marker执行=%v", story.SkippedPostProcessors, marked)
+	}
+
+	if _, err := Generate(context.Background(), WithPostProcessErrorPolicy("retry")); err == nil {
+		t.Error("未知策略应返回错误")
+	}
+}
@@ -0,0 +1,111 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// 质量评分范围
+const (
+	MinQualityScore = 1  // 单项最低分
+	MaxQualityScore = 10 // 单项最高分
+)
+
+// QualityScore 生成内容的质量评分
+type QualityScore struct {
+	Creativity   int    `json:"creativity"`   // 创意性
+	Coherence    int    `json:"coherence"`    // 连贯性
+	Completeness int    `json:"completeness"` // 完整性
+	Comment      string `json:"comment"`      // 简短评语
+}
+
+// Overall 综合得分，为各维度得分的平均值
+func (s QualityScore) Overall() float64 {
+	return float64(s.Creativity+s.Coherence+s.Completeness) / 3
+}
+
+// qualityPromptTemplate 质量评分提示词模板，参数为待评分文本
+const qualityPromptTemplate = `你是一个小说设定评审，请从创意性、连贯性、完整性三个维度为下面的设定打分，每项为1到10的整数。
+设定：
+%s
+请严格按照如下JSON格式输出：{"creativity": 0, "coherence": 0, "completeness": 0, "comment": ""}。不要输出除JSON以外的内容。`
+
+// ParseQualityScore 解析模型返回的质量评分
+// 兼容模型在JSON前后附带说明文字或代码块标记的情况，任一维度缺失或超出范围时返回错误
+func ParseQualityScore(s string) (*QualityScore, error) {
+	var score QualityScore
+	if err := decodeModelJSON(s, '{', &score); err != nil {
+		return nil, fmt.Errorf("解析质量评分结果失败: %w", err)
+	}
+	dims := map[string]int{"creativity": score.Creativity, "coherence": score.Coherence, "completeness": score.Completeness}
+	for name, value := range dims {
+		if value < MinQualityScore || value > MaxQualityScore {
+			return nil, fmt.Errorf("质量评分 %s 超出范围: %d", name, value)
+		}
+	}
+	return &score, nil
+}
+
+// QualityScorer 基于大模型的质量评分器
+type QualityScorer struct {
+	model ModelFunc
+}
+
+// NewQualityScorer 创建质量评分器
+func NewQualityScorer(model ModelFunc) *QualityScorer {
+	return &QualityScorer{model: model}
+}
+
+// ScoreContent 对一段生成内容打分
+// 参数:
+// - ctx: 上下文
+// - text: 待评分的内容
+// 返回:
+// - 结构化的质量评分
+// - 模型调用或结果解析失败时返回错误
+func (s *QualityScorer) ScoreContent(ctx context.Context, text string) (*QualityScore, error) {
+	if s.model == nil {
+		return nil, errors.New("质量评分模型函数不能为空")
+	}
+	output, err := s.model(ctx, fmt.Sprintf(qualityPromptTemplate, text))
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindModel, err)
+	}
+	score, err := ParseQualityScore(output)
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindParse, err)
+	}
+	return score, nil
+}
+
+// WithQualityScoring 启用背景生成后的质量评分
+// 评分记录在各背景的 Quality 中；minOverall 大于0时综合得分低于它的背景会被过滤
+func WithQualityScoring(model ModelFunc, minOverall float64) StoryOption {
+	return func(opts *StoryOptions) error {
+		if model == nil {
+			return errors.New("质量评分模型函数不能为空")
+		}
+		opts.QualityScorer = NewQualityScorer(model)
+		opts.MinQualityOverall = minOverall
+		return nil
+	}
+}
+
+// scoreBackgrounds 为各背景打分，返回记录了评分并过滤低分项后的背景
+// 生成流程中的背景尚未入库、ID 均为0，评分直接记录在背景上而不按ID索引
+func scoreBackgrounds(ctx context.Context, scorer *QualityScorer, minOverall float64, backgrounds []Background) ([]Background, error) {
+	kept := make([]Background, 0, len(backgrounds))
+	for _, b := range backgrounds {
+		score, err := scorer.ScoreContent(ctx, b.Description)
+		if err != nil {
+			return nil, err
+		}
+		if minOverall > 0 && score.Overall() < minOverall {
+			continue
+		}
+		b.Quality = score
+		kept = append(kept, b)
+	}
+	return kept, nil
+}
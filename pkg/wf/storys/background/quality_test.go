@@ -0,0 +1,92 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestScoreContent 测试假模型返回的评分字段被正确解析且在合理范围
+func TestScoreContent(t *testing.T) {
+	text := "浮空城依靠地脉晶石漂浮，每百年需要一次献祭来稳定晶石"
+
+	var prompt string
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "评分如下：\n```json\n{\"creativity\": 8, \"coherence\": 7, \"completeness\": 6, \"comment\": \"设定新颖但缺少细节\"}\n```", nil
+	}
+
+	score, err := NewQualityScorer(fakeModel).ScoreContent(context.Background(), text)
+	if err != nil {
+		t.Fatalf("评分失败: %v", err)
+	}
+	if !strings.Contains(prompt, text) {
+		t.Errorf("期望提示词包含待评分内容，实际为%s", prompt)
+	}
+	if score.Creativity != 8 || score.Coherence != 7 || score.Completeness != 6 {
+		t.Errorf("评分字段解析错误: %+v", score)
+	}
+	if score.Comment != "设定新颖但缺少细节" {
+		t.Errorf("期望评语被解析，实际为%s", score.Comment)
+	}
+	if overall := score.Overall(); overall != 7 {
+		t.Errorf("期望综合得分为7，实际为%v", overall)
+	}
+}
+
+// TestParseQualityScoreOutOfRange 测试超出范围或缺失维度的评分被拒绝
+func TestParseQualityScoreOutOfRange(t *testing.T) {
+	inputs := []string{
+		`{"creativity": 11, "coherence": 7, "completeness": 6}`,
+		`{"creativity": 8, "coherence": -1, "completeness": 6}`,
+		`{"creativity": 8, "coherence": 7}`,
+		`评分为八分`,
+	}
+	for _, input := range inputs {
+		if score, err := ParseQualityScore(input); err == nil {
+			t.Errorf("期望解析失败，输入%s，实际结果为%+v", input, score)
+		}
+	}
+
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		return inputs[0], nil
+	}
+	_, err := NewQualityScorer(fakeModel).ScoreContent(context.Background(), "内容")
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("期望解析错误，实际为%v", err)
+	}
+}
+
+// TestGenerateWithQualityScoring 测试生成流程为未入库（ID为0）的背景各自记录分数并过滤低分背景
+func TestGenerateWithQualityScoring(t *testing.T) {
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		if strings.Contains(p, "平庸") {
+			return `{"creativity": 2, "coherence": 5, "completeness": 2}`, nil
+		}
+		return `{"creativity": 9, "coherence": 8, "completeness": 7}`, nil
+	}
+
+	story, err := Generate(context.Background(),
+		WithBackgroundGenerator(func(ctx context.Context, worldviews []Worldview, rules []Rule) ([]Background, error) {
+			return []Background{
+				{Name: "浮空城", Description: "精彩的背景"},
+				{Name: "小镇", Description: "平庸的背景"},
+				{Name: "地下城", Description: "另一个精彩的背景"},
+			}, nil
+		}),
+		WithQualityScoring(fakeModel, 5),
+	)
+	if err != nil {
+		t.Fatalf("生成故事失败: %v", err)
+	}
+	if len(story.Backgrounds) != 2 || story.Backgrounds[0].Name != "浮空城" || story.Backgrounds[1].Name != "地下城" {
+		t.Fatalf("期望低分背景被过滤，实际为%+v", story.Backgrounds)
+	}
+	for _, b := range story.Backgrounds {
+		if b.Quality == nil || b.Quality.Creativity != 9 || b.Quality.Overall() != 8 {
+			t.Errorf("背景 %s 的评分记录错误: %+v", b.Name, b.Quality)
+		}
+	}
+}
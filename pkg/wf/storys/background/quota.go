@@ -0,0 +1,217 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"novelai/pkg/llm/deepseek"
+)
+
+// ErrQuotaExceeded 用户当日生成配额已用尽，可通过 errors.Is 判断
+var ErrQuotaExceeded = errors.New("当日生成配额已用尽")
+
+// QuotaLimits 每个用户的每日生成配额
+type QuotaLimits struct {
+	DailyRequests int // 每日最大生成次数，小于等于0表示不限制
+	DailyTokens   int // 每日最大token用量，小于等于0表示不限制
+}
+
+// QuotaExceededError 超出配额时返回的错误，包含超限项和配额重置时间
+type QuotaExceededError struct {
+	UserID  int64     // 用户ID
+	Reason  string    // 超限项，如“生成次数”或“token用量”
+	Limit   int       // 对应的每日上限
+	ResetAt time.Time // 配额重置时间（次日零点）
+}
+
+// Error 实现error接口
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s: %s已达每日上限%d，将于%s重置", ErrQuotaExceeded.Error(), e.Reason, e.Limit, e.ResetAt.Format("2006-01-02 15:04:05"))
+}
+
+// Is 使 errors.Is(err, ErrQuotaExceeded) 成立
+func (e *QuotaExceededError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// QuotaUsage 用户当日的用量
+type QuotaUsage struct {
+	Requests int // 当日生成次数
+	Tokens   int // 当日token用量
+}
+
+// dailyUsage 按自然日记录的用量
+type dailyUsage struct {
+	day string // 所属日期，格式为 2006-01-02
+	QuotaUsage
+}
+
+// QuotaChecker 按用户ID计量的每日配额检查器，并发安全
+// 用量按检查器时钟所在时区的自然日统计，跨天后自动清零；
+// 同时实现 deepseek.UsageRecorder，配置为模型客户端的用量钩子后按上下文中的用户累加token用量
+type QuotaChecker struct {
+	mu     sync.Mutex
+	limits QuotaLimits
+	now    func() time.Time
+	usages map[int64]*dailyUsage
+}
+
+// NewQuotaChecker 创建每日配额检查器
+func NewQuotaChecker(limits QuotaLimits) *QuotaChecker {
+	return &QuotaChecker{
+		limits: limits,
+		now:    time.Now,
+		usages: make(map[int64]*dailyUsage),
+	}
+}
+
+// SetClock 设置时钟函数，主要用于测试
+func (q *QuotaChecker) SetClock(now func() time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.now = now
+}
+
+var _ deepseek.UsageRecorder = (*QuotaChecker)(nil)
+
+// Check 检查用户当日剩余配额，次数或token用量任一达到上限时返回 *QuotaExceededError
+// 只检查不占用配额，生成前应使用 Reserve 避免并发请求同时通过检查
+func (q *QuotaChecker) Check(userID int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.checkLocked(userID, q.now())
+}
+
+// Reserve 检查用户当日剩余配额并原子地占用一次生成次数
+// 超限时返回 *QuotaExceededError；成功时返回的 release 用于生成失败后归还本次占用，
+// 跨天后调用 release 不影响新一天的用量
+func (q *QuotaChecker) Reserve(userID int64) (release func(), err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	if err := q.checkLocked(userID, now); err != nil {
+		return nil, err
+	}
+	usage := q.usageLocked(userID, now)
+	usage.Requests++
+	day := usage.day
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			if current, ok := q.usages[userID]; ok && current.day == day && current.Requests > 0 {
+				current.Requests--
+			}
+		})
+	}, nil
+}
+
+// checkLocked 检查用户当日剩余配额，调用方需持有锁
+func (q *QuotaChecker) checkLocked(userID int64, now time.Time) error {
+	usage := q.usageLocked(userID, now)
+	if q.limits.DailyRequests > 0 && usage.Requests >= q.limits.DailyRequests {
+		return &QuotaExceededError{UserID: userID, Reason: "生成次数", Limit: q.limits.DailyRequests, ResetAt: nextDay(now)}
+	}
+	if q.limits.DailyTokens > 0 && usage.Tokens >= q.limits.DailyTokens {
+		return &QuotaExceededError{UserID: userID, Reason: "token用量", Limit: q.limits.DailyTokens, ResetAt: nextDay(now)}
+	}
+	return nil
+}
+
+// AddRequest 累加用户当日的生成次数
+func (q *QuotaChecker) AddRequest(userID int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.usageLocked(userID, q.now()).Requests++
+}
+
+// RecordTokens 累加用户当日的token用量
+func (q *QuotaChecker) RecordTokens(userID int64, tokens int) {
+	if tokens <= 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.usageLocked(userID, q.now()).Tokens += tokens
+}
+
+// Record 实现 deepseek.UsageRecorder，按上下文中的配额用户累加token用量
+// 上下文中没有配额用户（未经 WithUserQuota 生成）的请求不计入
+func (q *QuotaChecker) Record(ctx context.Context, record deepseek.UsageRecord) {
+	userID, ok := QuotaUserFromContext(ctx)
+	if !ok {
+		return
+	}
+	q.RecordTokens(userID, record.PromptTokens+record.CompletionTokens)
+}
+
+// Usage 返回用户当日的用量
+func (q *QuotaChecker) Usage(userID int64) QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.usageLocked(userID, q.now()).QuotaUsage
+}
+
+// usageLocked 返回用户当日的用量记录，跨天时重置，调用方需持有锁
+func (q *QuotaChecker) usageLocked(userID int64, now time.Time) *dailyUsage {
+	day := now.Format("2006-01-02")
+	usage, ok := q.usages[userID]
+	if !ok || usage.day != day {
+		usage = &dailyUsage{day: day}
+		q.usages[userID] = usage
+	}
+	return usage
+}
+
+// nextDay 返回 t 所在时区的次日零点
+func nextDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
+// quotaUserKey 配额用户ID在上下文中的键
+type quotaUserKey struct{}
+
+// QuotaUserFromContext 从上下文中读取配额用户ID，仅在 WithUserQuota 的生成过程中存在
+func QuotaUserFromContext(ctx context.Context) (int64, bool) {
+	userID, ok := ctx.Value(quotaUserKey{}).(int64)
+	return userID, ok
+}
+
+// WithUserQuota 设置按用户的每日配额检查
+// 生成前原子地占用一次生成次数，超限时返回 *QuotaExceededError，生成失败时归还占用；
+// 生成期间上下文携带用户ID，将 checker 配置为模型客户端的 UsageRecorder 即可累加token用量
+func WithUserQuota(checker *QuotaChecker, userID int64) StoryOption {
+	return func(opts *StoryOptions) error {
+		if checker == nil {
+			return errors.New("配额检查器不能为空")
+		}
+		opts.QuotaChecker = checker
+		opts.QuotaUserID = userID
+		return nil
+	}
+}
+
+// generateWithQuota 在配额内执行生成，生成失败时归还占用的次数；未设置配额检查器时直接生成
+func generateWithQuota(ctx context.Context, opts *StoryOptions) (Story, error) {
+	if opts.QuotaChecker == nil {
+		return generateStory(ctx, opts)
+	}
+	release, err := opts.QuotaChecker.Reserve(opts.QuotaUserID)
+	if err != nil {
+		return Story{}, err
+	}
+	ctx = context.WithValue(ctx, quotaUserKey{}, opts.QuotaUserID)
+	story, err := generateStory(ctx, opts)
+	if err != nil {
+		release()
+		return Story{}, err
+	}
+	return story, nil
+}
@@ -0,0 +1,155 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"novelai/pkg/llm/deepseek"
+)
+
+// TestGenerateUserQuotaDailyLimit 测试达到每日生成次数上限后拒绝，跨天后恢复
+func TestGenerateUserQuotaDailyLimit(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 0, 0, 0, time.Local)
+	checker := NewQuotaChecker(QuotaLimits{DailyRequests: 2})
+	checker.SetClock(func() time.Time { return now })
+
+	var calls int
+	generator := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		calls++
+		return []Worldview{{ID: 1}}, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := Generate(context.Background(), generator, WithUserQuota(checker, 7)); err != nil {
+			t.Fatalf("第%d次生成失败: %v", i+1, err)
+		}
+	}
+
+	_, err := Generate(context.Background(), generator, WithUserQuota(checker, 7))
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("期望超出配额错误，实际为%v", err)
+	}
+	var quotaErr *QuotaExceededError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("期望返回*QuotaExceededError，实际为%T", err)
+	}
+	wantReset := time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local)
+	if !quotaErr.ResetAt.Equal(wantReset) {
+		t.Errorf("期望重置时间为%v，实际为%v", wantReset, quotaErr.ResetAt)
+	}
+	if calls != 2 {
+		t.Errorf("超限请求不应触发生成，实际生成%d次", calls)
+	}
+
+	// 其他用户不受影响
+	if _, err := Generate(context.Background(), generator, WithUserQuota(checker, 8)); err != nil {
+		t.Errorf("其他用户生成失败: %v", err)
+	}
+
+	// 跨天后配额恢复
+	now = now.Add(2 * time.Hour)
+	if _, err := Generate(context.Background(), generator, WithUserQuota(checker, 7)); err != nil {
+		t.Fatalf("跨天后生成失败: %v", err)
+	}
+	if usage := checker.Usage(7); usage.Requests != 1 {
+		t.Errorf("跨天后期望当日次数为1，实际为%d", usage.Requests)
+	}
+}
+
+// TestQuotaCheckerTokens 测试token用量达到上限后拒绝，失败的生成不计次数
+func TestQuotaCheckerTokens(t *testing.T) {
+	checker := NewQuotaChecker(QuotaLimits{DailyTokens: 100})
+
+	failing := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		return nil, errors.New("模型不可用")
+	})
+	if _, err := Generate(context.Background(), failing, WithUserQuota(checker, 1)); err == nil {
+		t.Fatal("期望生成失败")
+	}
+	if usage := checker.Usage(1); usage.Requests != 0 {
+		t.Errorf("失败的生成不应计入次数，实际为%d", usage.Requests)
+	}
+
+	checker.RecordTokens(1, 60)
+	if err := checker.Check(1); err != nil {
+		t.Fatalf("未达上限时不应拒绝: %v", err)
+	}
+	checker.RecordTokens(1, 40)
+	var quotaErr *QuotaExceededError
+	if err := checker.Check(1); !errors.As(err, &quotaErr) || quotaErr.Reason != "token用量" {
+		t.Errorf("期望token用量超限，实际为%v", err)
+	}
+}
+
+// TestGenerateUserQuotaConcurrent 测试并发生成时只有配额内的请求能通过检查
+func TestGenerateUserQuotaConcurrent(t *testing.T) {
+	const total, limit = 5, 2
+	checker := NewQuotaChecker(QuotaLimits{DailyRequests: limit})
+
+	unblock := make(chan struct{})
+	generator := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		<-unblock
+		return []Worldview{{ID: 1}}, nil
+	})
+
+	errs := make(chan error, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := Generate(context.Background(), generator, WithUserQuota(checker, 1))
+			errs <- err
+		}()
+	}
+
+	// 占用配额的请求阻塞在生成中，其余请求应立即被拒绝
+	for i := 0; i < total-limit; i++ {
+		select {
+		case err := <-errs:
+			if !errors.Is(err, ErrQuotaExceeded) {
+				t.Errorf("期望超出配额错误，实际为%v", err)
+			}
+		case <-time.After(2 * time.Second):
+			close(unblock)
+			t.Fatal("并发请求未被配额拒绝")
+		}
+	}
+	close(unblock)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("配额内的生成失败: %v", err)
+		}
+	}
+	if usage := checker.Usage(1); usage.Requests != limit {
+		t.Errorf("期望当日次数为%d，实际为%d", limit, usage.Requests)
+	}
+}
+
+// TestQuotaCheckerRecordUsage 测试作为用量钩子时按生成上下文中的用户累加token
+func TestQuotaCheckerRecordUsage(t *testing.T) {
+	checker := NewQuotaChecker(QuotaLimits{DailyTokens: 1000})
+
+	generator := WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+		// 模拟模型客户端请求成功后调用用量钩子
+		checker.Record(ctx, deepseek.UsageRecord{PromptTokens: 30, CompletionTokens: 20})
+		return []Worldview{{ID: 1}}, nil
+	})
+	if _, err := Generate(context.Background(), generator, WithUserQuota(checker, 3)); err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	if usage := checker.Usage(3); usage.Tokens != 50 || usage.Requests != 1 {
+		t.Errorf("期望用量为1次50token，实际为%+v", usage)
+	}
+
+	// 不在配额生成过程中的请求不计入任何用户
+	checker.Record(context.Background(), deepseek.UsageRecord{PromptTokens: 100})
+	if usage := checker.Usage(3); usage.Tokens != 50 {
+		t.Errorf("无配额用户的请求不应计入，实际为%d", usage.Tokens)
+	}
+}
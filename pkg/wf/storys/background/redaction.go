@@ -0,0 +1,59 @@
+package background
+
+import "novelai/pkg/utils/redact"
+
+// WithRedaction 启用生成文本脱敏
+// 用户可能在主题或角色中输入真实手机号、邮箱等隐私，脱敏在后处理（保存）前进行；
+// redactor 为空时使用默认脱敏规则
+func WithRedaction(redactor *redact.Redactor) StoryOption {
+	return func(opts *StoryOptions) error {
+		if redactor == nil {
+			redactor = redact.NewRedactor()
+		}
+		opts.Redactor = redactor
+		return nil
+	}
+}
+
+// redactStory 对故事中所有生成文本脱敏，包括子设定与抽取出的实体
+func redactStory(r *redact.Redactor, story *Story) {
+	redactWorldviews(r, story.WorldViews)
+	redactRules(r, story.Rules)
+	redactBackgrounds(r, story.Backgrounds)
+	for i := range story.Entities {
+		story.Entities[i].Name = r.Redact(story.Entities[i].Name)
+		story.Entities[i].Description = r.Redact(story.Entities[i].Description)
+	}
+}
+
+// redactWorldviews 递归脱敏世界观，包括摘要与结构化章节
+func redactWorldviews(r *redact.Redactor, worldviews []Worldview) {
+	for i := range worldviews {
+		worldviews[i].Name = r.Redact(worldviews[i].Name)
+		worldviews[i].Description = r.Redact(worldviews[i].Description)
+		worldviews[i].Summary = r.Redact(worldviews[i].Summary)
+		for j := range worldviews[i].Sections {
+			worldviews[i].Sections[j].Title = r.Redact(worldviews[i].Sections[j].Title)
+			worldviews[i].Sections[j].Content = r.Redact(worldviews[i].Sections[j].Content)
+		}
+		redactWorldviews(r, worldviews[i].Children)
+	}
+}
+
+// redactRules 递归脱敏规则
+func redactRules(r *redact.Redactor, rules []Rule) {
+	for i := range rules {
+		rules[i].Name = r.Redact(rules[i].Name)
+		rules[i].Description = r.Redact(rules[i].Description)
+		redactRules(r, rules[i].Children)
+	}
+}
+
+// redactBackgrounds 递归脱敏背景
+func redactBackgrounds(r *redact.Redactor, backgrounds []Background) {
+	for i := range backgrounds {
+		backgrounds[i].Name = r.Redact(backgrounds[i].Name)
+		backgrounds[i].Description = r.Redact(backgrounds[i].Description)
+		redactBackgrounds(r, backgrounds[i].Children)
+	}
+}
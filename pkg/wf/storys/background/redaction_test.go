@@ -0,0 +1,63 @@
+package background
+
+import (
+	"context"
+	"testing"
+)
+
+// TestGenerateWithRedaction 测试后处理拿到的故事已完成脱敏
+func TestGenerateWithRedaction(t *testing.T) {
+	var saved Story
+	story, err := Generate(context.Background(),
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			return []Worldview{{
+				ID:          1,
+				Name:        "现代都市",
+				Description: "主角张三，电话13812345678",
+				Sections: []WorldviewSection{
+					{Title: "势力", Content: "帮派据点联系 boss@example.com"},
+					{Title: "热线13912345678", Content: "普通设定"},
+				},
+			}}, nil
+		}),
+		WithRuleGenerator(func(ctx context.Context, w []Worldview) ([]Rule, error) {
+			return []Rule{{ID: 1, Name: "规则", Description: "普通规则", Children: []Rule{{ID: 2, Description: "联系 someone@example.com"}}}}, nil
+		}),
+		WithBackgroundGenerator(func(ctx context.Context, w []Worldview, r []Rule) ([]Background, error) {
+			return []Background{{ID: 1, Name: "背景", Description: "身份证110105199003071234"}}, nil
+		}),
+		WithPostProcessor(func(ctx context.Context, s *Story) error {
+			saved = *s
+			return nil
+		}),
+		WithWorldviewSummary(func(ctx context.Context, prompt string) (string, error) {
+			return "联系人电话13812345678", nil
+		}, 0),
+		WithRedaction(nil),
+	)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+
+	if got := saved.WorldViews[0].Description; got != "主角张三，电话[手机号]" {
+		t.Errorf("世界观描述未脱敏: %s", got)
+	}
+	if got := saved.WorldViews[0].Summary; got != "联系人电话[手机号]" {
+		t.Errorf("世界观摘要未脱敏: %s", got)
+	}
+	if got := saved.WorldViews[0].Sections[0].Content; got != "帮派据点联系 [邮箱]" {
+		t.Errorf("世界观章节内容未脱敏: %s", got)
+	}
+	if got := saved.WorldViews[0].Sections[1].Title; got != "热线[手机号]" {
+		t.Errorf("世界观章节标题未脱敏: %s", got)
+	}
+	if got := saved.Rules[0].Children[0].Description; got != "联系 [邮箱]" {
+		t.Errorf("子规则描述未脱敏: %s", got)
+	}
+	if got := story.Backgrounds[0].Description; got != "身份证[身份证号]" {
+		t.Errorf("背景描述未脱敏: %s", got)
+	}
+	if got := story.Rules[0].Description; got != "普通规则" {
+		t.Errorf("正常文本不应被修改: %s", got)
+	}
+}
@@ -0,0 +1,106 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 可单独重新生成的字段
+const (
+	FieldName        = "name"        // 名称
+	FieldDescription = "description" // 描述
+	FieldTag         = "tag"         // 标签
+)
+
+// fieldNames 可重新生成字段的中文名称，用于提示词
+var fieldNames = map[string]string{
+	FieldName:        "名称",
+	FieldDescription: "描述",
+	FieldTag:         "标签（英文逗号分隔）",
+}
+
+// regeneratePromptTemplate 单字段重新生成模板，参数依次为实体类型、名称、描述、标签和待重新生成的字段
+const regeneratePromptTemplate = `你是一名小说设定编辑，下面是一条已有的%s设定：
+名称：%s
+描述：%s
+标签：%s
+用户对其他内容满意，只希望重新撰写“%s”。请在与其他字段保持一致的前提下给出新的内容，只输出该字段的新内容，不要输出字段名或其他内容。`
+
+// EntityFields 世界观、规则、背景中可单独重新生成的字段
+type EntityFields struct {
+	Name        string // 名称
+	Description string // 描述
+	Tag         string // 标签，多个标签用英文逗号分隔
+}
+
+// EntityStore 重新生成单个字段时读取与更新记录所需的存储操作，由调用方基于数据层实现
+// entityType 为 StageWorldview、StageRule 或 StageBackground
+type EntityStore interface {
+	// GetEntityFields 按类型和ID读取记录的字段
+	GetEntityFields(ctx context.Context, entityType GenerationStage, id uint) (*EntityFields, error)
+	// UpdateEntityField 只更新记录的指定字段
+	UpdateEntityField(ctx context.Context, entityType GenerationStage, id uint, field, value string) error
+}
+
+// RegenerateField 重新生成已有记录的单个字段，其他字段保持不变
+// 参数:
+// - ctx: 上下文，带有风格时渲染进提示词
+// - model: 模型调用函数
+// - store: 记录存储
+// - entityType: 记录类型，为 StageWorldview、StageRule 或 StageBackground
+// - entityID: 记录ID
+// - field: 待重新生成的字段，为 FieldName、FieldDescription 或 FieldTag
+// 返回:
+// - 更新后的记录字段
+// - 参数无效、读取记录失败、模型调用失败或输出为空、更新失败时返回错误
+func RegenerateField(ctx context.Context, model ModelFunc, store EntityStore, entityType GenerationStage, entityID uint, field string) (*EntityFields, error) {
+	if model == nil {
+		return nil, errors.New("重新生成模型函数不能为空")
+	}
+	if store == nil {
+		return nil, errors.New("记录存储不能为空")
+	}
+	switch entityType {
+	case StageWorldview, StageRule, StageBackground:
+	default:
+		return nil, fmt.Errorf("不支持的记录类型: %s", entityType)
+	}
+	fieldName, ok := fieldNames[field]
+	if !ok {
+		return nil, fmt.Errorf("不支持重新生成的字段: %s", field)
+	}
+
+	fields, err := store.GetEntityFields(ctx, entityType, entityID)
+	if err != nil {
+		return nil, fmt.Errorf("读取%s失败: %w", stageNames[entityType], err)
+	}
+
+	prompt := fmt.Sprintf(regeneratePromptTemplate, stageNames[entityType], fields.Name, fields.Description, fields.Tag, fieldName)
+	output, err := model(ctx, StyledPrompt(ctx, prompt))
+	if err != nil {
+		return nil, NewGenerationError(entityType, ErrorKindModel, err)
+	}
+	value := strings.TrimSpace(output)
+	if field == FieldTag {
+		value = normalizeTagList(value)
+	}
+	if value == "" {
+		return nil, NewGenerationError(entityType, ErrorKindParse, fmt.Errorf("重新生成的%s为空", fieldName))
+	}
+
+	if err := store.UpdateEntityField(ctx, entityType, entityID, field, value); err != nil {
+		return nil, NewGenerationError(entityType, ErrorKindSave, fmt.Errorf("更新%s失败: %w", fieldName, err))
+	}
+	updated := *fields
+	switch field {
+	case FieldName:
+		updated.Name = value
+	case FieldDescription:
+		updated.Description = value
+	case FieldTag:
+		updated.Tag = value
+	}
+	return &updated, nil
+}
@@ -0,0 +1,94 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// memoryEntityStore 测试用的内存记录存储
+type memoryEntityStore struct {
+	records map[GenerationStage]map[uint]EntityFields
+}
+
+// GetEntityFields 实现EntityStore接口
+func (s *memoryEntityStore) GetEntityFields(ctx context.Context, entityType GenerationStage, id uint) (*EntityFields, error) {
+	fields, ok := s.records[entityType][id]
+	if !ok {
+		return nil, errors.New("记录不存在")
+	}
+	return &fields, nil
+}
+
+// UpdateEntityField 实现EntityStore接口
+func (s *memoryEntityStore) UpdateEntityField(ctx context.Context, entityType GenerationStage, id uint, field, value string) error {
+	fields := s.records[entityType][id]
+	switch field {
+	case FieldName:
+		fields.Name = value
+	case FieldDescription:
+		fields.Description = value
+	case FieldTag:
+		fields.Tag = value
+	}
+	s.records[entityType][id] = fields
+	return nil
+}
+
+// TestRegenerateField 测试重新生成 name 后仅 name 变化，description 与 tag 不变
+func TestRegenerateField(t *testing.T) {
+	original := EntityFields{Name: "星海帝国", Description: "人类在银河建立的庞大帝国", Tag: "科幻,帝国"}
+	store := &memoryEntityStore{records: map[GenerationStage]map[uint]EntityFields{
+		StageWorldview: {1: original},
+	}}
+	var prompt string
+	model := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "  银河王庭\n", nil
+	}
+
+	updated, err := RegenerateField(context.Background(), model, store, StageWorldview, 1, FieldName)
+	if err != nil {
+		t.Fatalf("重新生成失败: %v", err)
+	}
+	if !strings.Contains(prompt, "人类在银河建立的庞大帝国") || !strings.Contains(prompt, "重新撰写“名称”") {
+		t.Errorf("提示词应包含现有字段与待重新生成的字段，实际为%s", prompt)
+	}
+	saved := store.records[StageWorldview][1]
+	if saved.Name != "银河王庭" || updated.Name != "银河王庭" {
+		t.Errorf("名称应被更新，实际保存为%+v，返回为%+v", saved, updated)
+	}
+	if saved.Description != original.Description || saved.Tag != original.Tag {
+		t.Errorf("其他字段不应变化，实际为%+v", saved)
+	}
+}
+
+// TestRegenerateFieldErrors 测试无效参数、记录不存在与输出为空时返回错误且不更新
+func TestRegenerateFieldErrors(t *testing.T) {
+	store := &memoryEntityStore{records: map[GenerationStage]map[uint]EntityFields{
+		StageRule: {1: {Name: "魔法守恒", Description: "魔力不能凭空产生", Tag: "魔法"}},
+	}}
+	model := func(ctx context.Context, p string) (string, error) {
+		return " ，, ", nil
+	}
+	ctx := context.Background()
+
+	if _, err := RegenerateField(ctx, model, store, StageRule, 1, "parent_id"); err == nil {
+		t.Error("不支持的字段应返回错误")
+	}
+	if _, err := RegenerateField(ctx, model, store, StagePostProcess, 1, FieldName); err == nil {
+		t.Error("不支持的记录类型应返回错误")
+	}
+	if _, err := RegenerateField(ctx, model, store, StageRule, 2, FieldName); err == nil {
+		t.Error("记录不存在时应返回错误")
+	}
+	_, err := RegenerateField(ctx, model, store, StageRule, 1, FieldTag)
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("输出为空时应返回解析错误，实际为%v", err)
+	}
+	if store.records[StageRule][1].Tag != "魔法" {
+		t.Errorf("失败时不应更新记录，实际为%+v", store.records[StageRule][1])
+	}
+}
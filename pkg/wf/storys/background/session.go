@@ -0,0 +1,245 @@
+package background
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultSessionTTL 分步生成会话的默认有效期，每次访问会话后重新计时
+const DefaultSessionTTL = 30 * time.Minute
+
+// ErrSessionNotFound 生成会话不存在或已过期
+var ErrSessionNotFound = errors.New("生成会话不存在或已过期")
+
+// ErrInvalidGenerationStep 生成步骤不合法或前一步尚未完成
+var ErrInvalidGenerationStep = errors.New("生成步骤不合法")
+
+// sessionSteps 分步生成的步骤顺序，后处理（如保存）为最后一步
+var sessionSteps = []GenerationStage{StageWorldview, StageRule, StageBackground, StagePostProcess}
+
+// generationSession 分步生成会话
+type generationSession struct {
+	mu       sync.Mutex    // 串行化同一会话的步骤
+	opts     *StoryOptions // 开始会话时的生成选项
+	story    Story         // 已确认的生成内容
+	done     int           // 已完成的步骤数，对应 sessionSteps 的下标
+	expireAt time.Time     // 过期时间
+}
+
+// SessionManager 分步生成会话管理器，并发安全
+// 先生成世界观，由用户确认后再逐步生成规则、背景，最后执行后处理（如保存）
+type SessionManager struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	now      func() time.Time
+	sessions map[string]*generationSession
+}
+
+// NewSessionManager 创建分步生成会话管理器，ttl 小于等于0时使用 DefaultSessionTTL
+func NewSessionManager(ttl time.Duration) *SessionManager {
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	return &SessionManager{
+		ttl:      ttl,
+		now:      time.Now,
+		sessions: make(map[string]*generationSession),
+	}
+}
+
+// SetClock 设置时钟函数，主要用于测试
+func (m *SessionManager) SetClock(now func() time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+// StartGeneration 开始分步生成：按选项生成世界观并创建会话
+// options 在会话内后续各步骤中沿用
+// 返回:
+// - 会话ID
+// - 生成的世界观
+// - 如果生成过程出错，返回相应错误，此时不创建会话
+func (m *SessionManager) StartGeneration(ctx context.Context, options ...StoryOption) (string, []Worldview, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	opts, err := applyOptions(options)
+	if err != nil {
+		return "", nil, err
+	}
+
+	session := &generationSession{opts: opts}
+	if err := session.run(ctx, StageWorldview); err != nil {
+		return "", nil, err
+	}
+
+	id, err := newSessionID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purgeExpiredLocked()
+	session.expireAt = m.now().Add(m.ttl)
+	m.sessions[id] = session
+	return id, cloneWorldviews(session.story.WorldViews), nil
+}
+
+// ContinueGeneration 基于会话中已确认的内容执行指定步骤，返回到目前为止的生成结果
+// step 为下一步时继续生成；为已完成的步骤时重新生成该步并丢弃其后的结果。
+// step 为 StagePostProcess 时对完整结果执行后处理器与后处理函数（如保存），成功后结束会话
+func (m *SessionManager) ContinueGeneration(ctx context.Context, sessionID string, step GenerationStage) (Story, error) {
+	if err := ctx.Err(); err != nil {
+		return Story{}, err
+	}
+	session, err := m.get(sessionID)
+	if err != nil {
+		return Story{}, err
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if err := session.run(ctx, step); err != nil {
+		return Story{}, err
+	}
+	story := cloneStory(session.story)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if step == StagePostProcess {
+		delete(m.sessions, sessionID)
+	} else {
+		session.expireAt = m.now().Add(m.ttl)
+	}
+	return story, nil
+}
+
+// CancelGeneration 结束会话并丢弃已生成的内容
+func (m *SessionManager) CancelGeneration(sessionID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, sessionID)
+}
+
+// get 读取未过期的会话
+func (m *SessionManager) get(sessionID string) (*generationSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	if !m.now().Before(session.expireAt) {
+		delete(m.sessions, sessionID)
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+// purgeExpiredLocked 清理已过期的会话，调用方需持有 m.mu
+func (m *SessionManager) purgeExpiredLocked() {
+	now := m.now()
+	for id, session := range m.sessions {
+		if !now.Before(session.expireAt) {
+			delete(m.sessions, id)
+		}
+	}
+}
+
+// run 执行指定步骤，要求其之前的步骤均已完成
+func (s *generationSession) run(ctx context.Context, step GenerationStage) error {
+	index := -1
+	for i, stage := range sessionSteps {
+		if stage == step {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidGenerationStep, step)
+	}
+	if index > s.done {
+		return fmt.Errorf("%w: 需要先完成%s生成", ErrInvalidGenerationStep, stageNames[sessionSteps[s.done]])
+	}
+
+	ctx, tracker := trackModelFallback(s.context(ctx))
+	story := cloneStory(s.story)
+	switch step {
+	case StageWorldview:
+		worldviews, err := generateWorldviewStage(ctx, s.opts)
+		if err != nil {
+			return err
+		}
+		story = Story{WorldViews: worldviews}
+	case StageRule:
+		rules, err := generateRuleStage(ctx, s.opts, story.WorldViews)
+		if err != nil {
+			return err
+		}
+		warnings, err := checkDuplicateRuleStage(ctx, s.opts, story.WorldViews, rules)
+		if err != nil {
+			return err
+		}
+		story = Story{WorldViews: story.WorldViews, Rules: rules, DuplicateWarnings: warnings, Degraded: story.Degraded}
+	case StageBackground:
+		if err := generateBackgroundStage(ctx, s.opts, &story); err != nil {
+			return err
+		}
+	case StagePostProcess:
+		if err := runPostProcessors(ctx, s.opts, &story); err != nil {
+			return err
+		}
+		if err := s.opts.PostProcessor(ctx, &story); err != nil {
+			return wrapStageError(StagePostProcess, ErrorKindValidation, err)
+		}
+	}
+	if s.opts.Redactor != nil && step != StagePostProcess {
+		redactStory(s.opts.Redactor, &story)
+	}
+
+	story.Degraded = story.Degraded || tracker.used()
+	s.story = story
+	s.done = index + 1
+	return nil
+}
+
+// context 将会话选项中的风格传递给各生成函数
+func (s *generationSession) context(ctx context.Context) context.Context {
+	if s.opts.Style != "" {
+		return withStyleContext(ctx, s.opts.Style)
+	}
+	return ctx
+}
+
+// newSessionID 生成随机会话ID
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("生成会话ID失败: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// cloneStory 复制故事的顶层切片，避免调用方增删元素影响会话中已确认的内容
+func cloneStory(story Story) Story {
+	story.WorldViews = cloneWorldviews(story.WorldViews)
+	story.Rules = append([]Rule(nil), story.Rules...)
+	story.Backgrounds = append([]Background(nil), story.Backgrounds...)
+	story.Entities = append([]Entity(nil), story.Entities...)
+	story.DuplicateWarnings = append([]DuplicateWarning(nil), story.DuplicateWarnings...)
+	story.SkippedPostProcessors = append([]string(nil), story.SkippedPostProcessors...)
+	return story
+}
+
+// cloneWorldviews 复制世界观切片
+func cloneWorldviews(worldviews []Worldview) []Worldview {
+	return append([]Worldview(nil), worldviews...)
+}
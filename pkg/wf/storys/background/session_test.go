@@ -0,0 +1,118 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestSessionStepwiseGeneration 测试分步调用下每步基于前一步结果且最终组成完整套装
+func TestSessionStepwiseGeneration(t *testing.T) {
+	ruleCalls := 0
+	var saved Story
+	options := []StoryOption{
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			return []Worldview{{ID: 1, Name: "星海帝国"}}, nil
+		}),
+		WithRuleGenerator(func(ctx context.Context, worldviews []Worldview) ([]Rule, error) {
+			ruleCalls++
+			if len(worldviews) != 1 || worldviews[0].Name != "星海帝国" {
+				t.Errorf("规则应基于已确认的世界观生成，实际为%+v", worldviews)
+			}
+			return []Rule{{ID: uint(ruleCalls), Name: "跃迁禁令", WorldviewID: worldviews[0].ID}}, nil
+		}),
+		WithBackgroundGenerator(func(ctx context.Context, worldviews []Worldview, rules []Rule) ([]Background, error) {
+			if len(rules) != 1 || rules[0].ID != 2 {
+				t.Errorf("背景应基于最近一次确认的规则生成，实际为%+v", rules)
+			}
+			return []Background{{ID: 1, Name: "北境要塞", WorldviewID: worldviews[0].ID, Description: "受" + rules[0].Name + "约束"}}, nil
+		}),
+		WithPostProcessor(func(ctx context.Context, story *Story) error {
+			saved = *story
+			return nil
+		}),
+	}
+
+	manager := NewSessionManager(time.Minute)
+	ctx := context.Background()
+	sessionID, worldviews, err := manager.StartGeneration(ctx, options...)
+	if err != nil {
+		t.Fatalf("开始生成失败: %v", err)
+	}
+	if sessionID == "" || len(worldviews) != 1 || worldviews[0].Name != "星海帝国" {
+		t.Fatalf("期望返回会话ID与世界观，实际为%q %+v", sessionID, worldviews)
+	}
+
+	// 未生成规则前不能跳到背景
+	if _, err := manager.ContinueGeneration(ctx, sessionID, StageBackground); !errors.Is(err, ErrInvalidGenerationStep) {
+		t.Errorf("跳过规则生成背景应返回ErrInvalidGenerationStep，实际为%v", err)
+	}
+
+	story, err := manager.ContinueGeneration(ctx, sessionID, StageRule)
+	if err != nil {
+		t.Fatalf("生成规则失败: %v", err)
+	}
+	if len(story.Rules) != 1 || len(story.Backgrounds) != 0 {
+		t.Errorf("规则步骤后期望只有世界观与规则，实际为%+v", story)
+	}
+
+	// 对规则不满意时重新生成该步
+	story, err = manager.ContinueGeneration(ctx, sessionID, StageRule)
+	if err != nil || story.Rules[0].ID != 2 {
+		t.Fatalf("重新生成规则失败: %v %+v", err, story.Rules)
+	}
+
+	story, err = manager.ContinueGeneration(ctx, sessionID, StageBackground)
+	if err != nil {
+		t.Fatalf("生成背景失败: %v", err)
+	}
+	if len(story.WorldViews) != 1 || len(story.Rules) != 1 || len(story.Backgrounds) != 1 {
+		t.Fatalf("背景步骤后期望得到完整套装，实际为%+v", story)
+	}
+
+	if _, err := manager.ContinueGeneration(ctx, sessionID, StagePostProcess); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	if len(saved.WorldViews) != 1 || saved.Rules[0].ID != 2 || saved.Backgrounds[0].Description != "受跃迁禁令约束" {
+		t.Errorf("保存的套装不完整或不一致: %+v", saved)
+	}
+
+	// 保存后会话结束
+	if _, err := manager.ContinueGeneration(ctx, sessionID, StageRule); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("保存后会话应结束，实际为%v", err)
+	}
+}
+
+// TestSessionTTL 测试会话在有效期内访问会续期，超时后失效
+func TestSessionTTL(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.Local)
+	manager := NewSessionManager(10 * time.Minute)
+	manager.SetClock(func() time.Time { return now })
+
+	ctx := context.Background()
+	sessionID, _, err := manager.StartGeneration(ctx)
+	if err != nil {
+		t.Fatalf("开始生成失败: %v", err)
+	}
+
+	now = now.Add(9 * time.Minute)
+	if _, err := manager.ContinueGeneration(ctx, sessionID, StageRule); err != nil {
+		t.Fatalf("有效期内继续生成失败: %v", err)
+	}
+
+	// 上次访问后重新计时
+	now = now.Add(9 * time.Minute)
+	if _, err := manager.ContinueGeneration(ctx, sessionID, StageBackground); err != nil {
+		t.Fatalf("续期后继续生成失败: %v", err)
+	}
+
+	now = now.Add(10 * time.Minute)
+	if _, err := manager.ContinueGeneration(ctx, sessionID, StagePostProcess); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("过期会话应返回ErrSessionNotFound，实际为%v", err)
+	}
+
+	if _, err := manager.ContinueGeneration(ctx, "missing", StageRule); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("不存在的会话应返回ErrSessionNotFound，实际为%v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// EmbedFunc 文本向量化函数，返回文本的嵌入向量
+type EmbedFunc func(ctx context.Context, text string) ([]float64, error)
+
+// SimilarWorldview 相似世界观推荐结果
+type SimilarWorldview struct {
+	Worldview Worldview // 候选世界观
+	Score     float64   // 与目标世界观的余弦相似度，越大越相似
+}
+
+// embeddingEntry 缓存的世界观向量，文本变化后失效
+type embeddingEntry struct {
+	text   string
+	vector []float64
+}
+
+// WorldviewRecommender 基于向量相似度推荐相似世界观
+// 按世界观ID缓存向量，名称、描述或标签变化后重新计算；可并发使用
+type WorldviewRecommender struct {
+	embed EmbedFunc
+	mu    sync.Mutex
+	cache map[uint]embeddingEntry
+}
+
+// NewWorldviewRecommender 创建相似世界观推荐器
+func NewWorldviewRecommender(embed EmbedFunc) *WorldviewRecommender {
+	return &WorldviewRecommender{
+		embed: embed,
+		cache: make(map[uint]embeddingEntry),
+	}
+}
+
+// RecommendSimilarWorldviews 从候选世界观中找出与目标最相似的 topK 个
+// 参数:
+// - ctx: 上下文
+// - target: 目标世界观
+// - candidates: 已有的世界观，与目标ID（非0）相同的会被跳过
+// - topK: 返回数量上限，须大于0
+// 返回:
+// - 按相似度降序排列的推荐结果，相似度相同时按ID升序
+// - 参数非法或向量化失败时返回错误
+func (r *WorldviewRecommender) RecommendSimilarWorldviews(ctx context.Context, target Worldview, candidates []Worldview, topK int) ([]SimilarWorldview, error) {
+	if r.embed == nil {
+		return nil, errors.New("向量化函数不能为空")
+	}
+	if topK <= 0 {
+		return nil, errors.New("推荐数量必须大于0")
+	}
+
+	targetVector, err := r.vector(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SimilarWorldview, 0, len(candidates))
+	for _, candidate := range candidates {
+		if target.ID != 0 && candidate.ID == target.ID {
+			continue
+		}
+		vector, err := r.vector(ctx, candidate)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SimilarWorldview{
+			Worldview: candidate,
+			Score:     cosineSimilarity(targetVector, vector),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Worldview.ID < results[j].Worldview.ID
+	})
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// vector 返回世界观的向量，ID为0的世界观不缓存
+func (r *WorldviewRecommender) vector(ctx context.Context, w Worldview) ([]float64, error) {
+	text := worldviewEmbeddingText(w)
+	if w.ID != 0 {
+		r.mu.Lock()
+		entry, ok := r.cache[w.ID]
+		r.mu.Unlock()
+		if ok && entry.text == text {
+			return entry.vector, nil
+		}
+	}
+
+	vector, err := r.embed(ctx, text)
+	if err != nil {
+		return nil, fmt.Errorf("世界观 %d 向量化失败: %w", w.ID, err)
+	}
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("世界观 %d 的向量为空", w.ID)
+	}
+
+	if w.ID != 0 {
+		r.mu.Lock()
+		r.cache[w.ID] = embeddingEntry{text: text, vector: vector}
+		r.mu.Unlock()
+	}
+	return vector, nil
+}
+
+// worldviewEmbeddingText 拼接用于向量化的世界观文本
+func worldviewEmbeddingText(w Worldview) string {
+	parts := []string{w.Name, w.Description}
+	if w.Tag != "" {
+		parts = append(parts, "标签："+w.Tag)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// cosineSimilarity 计算两个向量的余弦相似度，维度不同或存在零向量时返回0
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,90 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeEmbedder 按名称返回固定向量的假向量化函数，记录调用次数
+func fakeEmbedder(vectors map[string][]float64, calls *int) EmbedFunc {
+	return func(ctx context.Context, text string) ([]float64, error) {
+		*calls++
+		name, _, _ := strings.Cut(text, "\n")
+		vector, ok := vectors[name]
+		if !ok {
+			return nil, errors.New("未知文本")
+		}
+		return vector, nil
+	}
+}
+
+// TestRecommendSimilarWorldviews 测试按相似度排序返回 topK 个世界观并缓存向量
+func TestRecommendSimilarWorldviews(t *testing.T) {
+	var calls int
+	recommender := NewWorldviewRecommender(fakeEmbedder(map[string][]float64{
+		"星海帝国": {1, 0, 0},
+		"银河联邦": {0.9, 0.1, 0},
+		"机械星域": {0.6, 0.8, 0},
+		"仙侠九州": {0, 0, 1},
+	}, &calls))
+
+	target := Worldview{ID: 1, Name: "星海帝国"}
+	candidates := []Worldview{
+		{ID: 1, Name: "星海帝国"},
+		{ID: 2, Name: "仙侠九州"},
+		{ID: 3, Name: "机械星域"},
+		{ID: 4, Name: "银河联邦"},
+	}
+
+	results, err := recommender.RecommendSimilarWorldviews(context.Background(), target, candidates, 2)
+	if err != nil {
+		t.Fatalf("推荐失败: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("期望返回2个结果，实际为%d", len(results))
+	}
+	if results[0].Worldview.ID != 4 || results[1].Worldview.ID != 3 {
+		t.Errorf("期望按相似度依次为4、3，实际为%d、%d", results[0].Worldview.ID, results[1].Worldview.ID)
+	}
+	if results[0].Score < results[1].Score {
+		t.Errorf("期望相似度降序，实际为%v", results)
+	}
+
+	// 再次推荐命中缓存，不再调用向量化函数
+	before := calls
+	if _, err := recommender.RecommendSimilarWorldviews(context.Background(), target, candidates, 3); err != nil {
+		t.Fatalf("再次推荐失败: %v", err)
+	}
+	if calls != before {
+		t.Errorf("期望命中向量缓存，额外调用了%d次", calls-before)
+	}
+
+	// 内容变化后重新计算向量
+	candidates[1].Name = "银河联邦"
+	if _, err := recommender.RecommendSimilarWorldviews(context.Background(), target, candidates, 3); err != nil {
+		t.Fatalf("内容变化后推荐失败: %v", err)
+	}
+	if calls != before+1 {
+		t.Errorf("期望仅重新计算变化的世界观，实际调用%d次", calls-before)
+	}
+}
+
+// TestRecommendSimilarWorldviews_Errors 测试参数非法与向量化失败
+func TestRecommendSimilarWorldviews_Errors(t *testing.T) {
+	var calls int
+	recommender := NewWorldviewRecommender(fakeEmbedder(map[string][]float64{"星海帝国": {1, 0}}, &calls))
+	ctx := context.Background()
+	target := Worldview{ID: 1, Name: "星海帝国"}
+
+	if _, err := recommender.RecommendSimilarWorldviews(ctx, target, nil, 0); err == nil {
+		t.Error("topK为0时应返回错误")
+	}
+	if _, err := recommender.RecommendSimilarWorldviews(ctx, target, []Worldview{{ID: 2, Name: "未知"}}, 1); err == nil {
+		t.Error("候选向量化失败时应返回错误")
+	}
+	if _, err := NewWorldviewRecommender(nil).RecommendSimilarWorldviews(ctx, target, nil, 1); err == nil {
+		t.Error("向量化函数为空时应返回错误")
+	}
+}
@@ -0,0 +1,99 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultWorldviewSections 结构化世界观的默认章节
+var DefaultWorldviewSections = []string{"地理", "历史", "势力", "魔法体系"}
+
+// sectionPromptTemplate 章节生成模板，参数依次为主题、章节名和已生成的章节
+const sectionPromptTemplate = `你是一名小说世界观设计师，正在为主题为“%s”的小说分章节撰写世界观设定。
+请撰写“%s”章节的内容，只描述该章节相关的设定，约200字。
+%s只输出章节正文，不要输出章节标题或其他内容。`
+
+// WorldviewSection 结构化世界观中的一个章节
+type WorldviewSection struct {
+	Title   string // 章节名称，如“地理”
+	Content string // 章节内容
+}
+
+// GenerateStructuredWorldview 按章节分别生成并组装结构化世界观
+// 每个章节单独调用一次模型，提示词中附带已生成的章节以保持前后一致；
+// 组装后的世界观以主题为名称，Sections 保存各章节，Description 为按章节拼接的全文
+// 参数:
+// - ctx: 上下文，带有风格时渲染进提示词
+// - model: 模型调用函数
+// - theme: 世界观主题，不能为空
+// - sections: 章节名称列表，为空时使用 DefaultWorldviewSections，重复与空白项会被忽略
+// 返回:
+// - 结构化世界观
+// - 模型调用失败或某个章节输出为空时返回错误
+func GenerateStructuredWorldview(ctx context.Context, model ModelFunc, theme string, sections []string) (*Worldview, error) {
+	if model == nil {
+		return nil, errors.New("章节生成模型函数不能为空")
+	}
+	theme = strings.TrimSpace(theme)
+	if theme == "" {
+		return nil, errors.New("世界观主题不能为空")
+	}
+	titles := normalizeSections(sections)
+	if len(titles) == 0 {
+		titles = DefaultWorldviewSections
+	}
+
+	worldview := &Worldview{Name: theme}
+	for _, title := range titles {
+		prompt := fmt.Sprintf(sectionPromptTemplate, theme, title, previousSectionsText(worldview.Sections))
+		output, err := model(ctx, StyledPrompt(ctx, prompt))
+		if err != nil {
+			return nil, NewGenerationError(StageWorldview, ErrorKindModel, fmt.Errorf("章节 %s: %w", title, err))
+		}
+		content := strings.TrimSpace(output)
+		if content == "" {
+			return nil, NewGenerationError(StageWorldview, ErrorKindParse, fmt.Errorf("章节 %s 内容为空", title))
+		}
+		worldview.Sections = append(worldview.Sections, WorldviewSection{Title: title, Content: content})
+	}
+	worldview.Description = joinSections(worldview.Sections)
+	return worldview, nil
+}
+
+// normalizeSections 去掉章节名称的首尾空白，并过滤空白与重复项
+func normalizeSections(sections []string) []string {
+	seen := make(map[string]bool, len(sections))
+	result := make([]string, 0, len(sections))
+	for _, s := range sections {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+	}
+	return result
+}
+
+// previousSectionsText 将已生成的章节渲染为提示词中的参考内容，没有时返回空字符串
+func previousSectionsText(sections []WorldviewSection) string {
+	if len(sections) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("已完成的章节如下，新章节需与其保持一致：\n")
+	b.WriteString(joinSections(sections))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// joinSections 将章节按“【章节名】内容”的格式拼接为全文
+func joinSections(sections []WorldviewSection) string {
+	parts := make([]string, 0, len(sections))
+	for _, s := range sections {
+		parts = append(parts, "【"+s.Title+"】"+s.Content)
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,73 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestGenerateStructuredWorldview 测试请求的每个章节在结果中都有对应的非空内容
+func TestGenerateStructuredWorldview(t *testing.T) {
+	var prompts []string
+	fakeModel := func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		for _, title := range []string{"地理", "历史", "势力"} {
+			if strings.Contains(prompt, "撰写“"+title+"”章节") {
+				return "  关于" + title + "的设定。\n", nil
+			}
+		}
+		return "", errors.New("未知章节")
+	}
+
+	sections := []string{"地理", " 历史 ", "势力", "地理", ""}
+	w, err := GenerateStructuredWorldview(context.Background(), fakeModel, "星海帝国", sections)
+	if err != nil {
+		t.Fatalf("生成结构化世界观失败: %v", err)
+	}
+	if w.Name != "星海帝国" {
+		t.Errorf("期望以主题为名称，实际为%s", w.Name)
+	}
+	if len(w.Sections) != 3 || len(prompts) != 3 {
+		t.Fatalf("期望去重后生成3个章节，实际为%d个章节、%d次调用", len(w.Sections), len(prompts))
+	}
+	for i, title := range []string{"地理", "历史", "势力"} {
+		section := w.Sections[i]
+		if section.Title != title || section.Content != "关于"+title+"的设定。" {
+			t.Errorf("第%d个章节不正确: %+v", i, section)
+		}
+		if !strings.Contains(w.Description, "【"+title+"】") {
+			t.Errorf("期望全文包含章节%s，实际为%s", title, w.Description)
+		}
+	}
+	if !strings.Contains(prompts[1], "关于地理的设定") {
+		t.Errorf("期望后续章节的提示词附带已生成的章节，实际为%s", prompts[1])
+	}
+}
+
+// TestGenerateStructuredWorldview_Defaults 测试未指定章节时使用默认章节，空输出返回解析错误
+func TestGenerateStructuredWorldview_Defaults(t *testing.T) {
+	calls := 0
+	fakeModel := func(ctx context.Context, prompt string) (string, error) {
+		calls++
+		return "内容", nil
+	}
+	w, err := GenerateStructuredWorldview(context.Background(), fakeModel, "蒸汽王国", nil)
+	if err != nil {
+		t.Fatalf("生成结构化世界观失败: %v", err)
+	}
+	if len(w.Sections) != len(DefaultWorldviewSections) || calls != len(DefaultWorldviewSections) {
+		t.Errorf("期望生成%d个默认章节，实际为%d", len(DefaultWorldviewSections), len(w.Sections))
+	}
+
+	emptyModel := func(ctx context.Context, prompt string) (string, error) { return " ", nil }
+	_, err = GenerateStructuredWorldview(context.Background(), emptyModel, "蒸汽王国", []string{"历史"})
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("期望空章节返回解析错误，实际为%v", err)
+	}
+
+	if _, err := GenerateStructuredWorldview(context.Background(), fakeModel, " ", nil); err == nil {
+		t.Error("主题为空时应返回错误")
+	}
+}
@@ -0,0 +1,76 @@
+package background
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Style 生成内容的风格/语气
+type Style string
+
+// 预置风格常量
+const (
+	StyleDark     Style = "dark"     // 黑暗严肃
+	StyleHumorous Style = "humorous" // 轻松幽默
+	StyleEpic     Style = "epic"     // 史诗恢弘
+)
+
+// styleInstructions 预置风格对应的提示词指令
+var styleInstructions = map[Style]string{
+	StyleDark:     "整体风格黑暗严肃，基调压抑沉重，突出冲突、代价与人性的阴暗面，避免轻松调侃的表达。",
+	StyleHumorous: "整体风格轻松幽默，基调明快诙谐，可以加入巧妙的反差和俏皮的细节，避免沉重压抑的表达。",
+	StyleEpic:     "整体风格史诗恢弘，基调庄严宏大，突出宏观格局、悠久历史与命运抉择，语言富有气势。",
+}
+
+// Instruction 返回风格对应的提示词指令，未知风格返回空字符串
+func (s Style) Instruction() string {
+	return styleInstructions[s]
+}
+
+// IsValid 判断是否为预置风格
+func (s Style) IsValid() bool {
+	_, ok := styleInstructions[s]
+	return ok
+}
+
+// WithStyle 设置生成内容的风格
+// 风格会写入传给各生成函数的上下文，生成函数可通过 StyledPrompt 渲染进提示词
+func WithStyle(style Style) StoryOption {
+	return func(opts *StoryOptions) error {
+		if !style.IsValid() {
+			return fmt.Errorf("未知的风格: %s", style)
+		}
+		opts.Style = style
+		return nil
+	}
+}
+
+// styleKey 风格在上下文中的键
+type styleKey struct{}
+
+// withStyleContext 在上下文中附加风格
+func withStyleContext(ctx context.Context, style Style) context.Context {
+	return context.WithValue(ctx, styleKey{}, style)
+}
+
+// StyleFromContext 从上下文中读取风格，未设置时返回空字符串
+func StyleFromContext(ctx context.Context) Style {
+	style, _ := ctx.Value(styleKey{}).(Style)
+	return style
+}
+
+// StyledPrompt 将上下文中的风格指令渲染进提示词
+// 未设置风格时原样返回提示词
+// 参数:
+// - ctx: 生成函数收到的上下文
+// - prompt: 原始提示词
+// 返回:
+// - 附加风格要求后的提示词
+func StyledPrompt(ctx context.Context, prompt string) string {
+	instruction := StyleFromContext(ctx).Instruction()
+	if instruction == "" {
+		return prompt
+	}
+	return strings.TrimRight(prompt, "\n") + "\n风格要求：" + instruction
+}
@@ -0,0 +1,53 @@
+package background
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestGenerateWithStyle 测试指定风格时提示词包含对应指令
+func TestGenerateWithStyle(t *testing.T) {
+	const basePrompt = "你是一个小说世界观生成助手，请生成一个世界观。"
+
+	generatePrompt := func(options ...StoryOption) string {
+		var prompt string
+		options = append(options, WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			prompt = StyledPrompt(ctx, basePrompt)
+			return nil, nil
+		}))
+		if _, err := Generate(context.Background(), options...); err != nil {
+			t.Fatalf("生成失败: %v", err)
+		}
+		return prompt
+	}
+
+	// 未指定风格时提示词不变
+	if prompt := generatePrompt(); prompt != basePrompt {
+		t.Errorf("未指定风格时提示词不应改变，实际为%s", prompt)
+	}
+
+	prompts := make(map[Style]string)
+	for _, style := range []Style{StyleDark, StyleHumorous, StyleEpic} {
+		prompt := generatePrompt(WithStyle(style))
+		if !strings.HasPrefix(prompt, basePrompt) {
+			t.Errorf("风格%s的提示词应保留原始内容，实际为%s", style, prompt)
+		}
+		if !strings.Contains(prompt, style.Instruction()) {
+			t.Errorf("风格%s的提示词缺少对应指令，实际为%s", style, prompt)
+		}
+		prompts[style] = prompt
+	}
+
+	// 不同风格产生不同的提示词
+	if prompts[StyleDark] == prompts[StyleHumorous] || prompts[StyleHumorous] == prompts[StyleEpic] || prompts[StyleDark] == prompts[StyleEpic] {
+		t.Errorf("不同风格应产生不同的提示词: %v", prompts)
+	}
+}
+
+// TestWithStyleInvalid 测试未知风格被拒绝
+func TestWithStyleInvalid(t *testing.T) {
+	if _, err := Generate(context.Background(), WithStyle("cute")); err == nil {
+		t.Error("未知风格应返回错误")
+	}
+}
@@ -0,0 +1,86 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultSummaryMaxChars 摘要的默认字数上限
+const DefaultSummaryMaxChars = 50
+
+// summaryPromptTemplate 摘要生成模板，参数依次为字数上限和原文
+const summaryPromptTemplate = `请为下面的小说设定写一句不超过%d字的摘要，概括其最核心的特征，用于列表展示。
+设定内容：
+%s
+只输出摘要本身，不要输出标题、引号或其他内容。`
+
+// GenerateSummary 让模型为一段设定文本生成短摘要
+// 参数:
+// - ctx: 上下文
+// - model: 模型调用函数
+// - text: 待摘要的文本，不能为空
+// - maxChars: 摘要字数上限，小于等于0时使用 DefaultSummaryMaxChars
+// 返回:
+// - 去掉多余空白与引号后的摘要，模型超出字数上限时截断
+// - 模型调用失败或输出为空时返回错误
+func GenerateSummary(ctx context.Context, model ModelFunc, text string, maxChars int) (string, error) {
+	if model == nil {
+		return "", errors.New("摘要模型函数不能为空")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("待摘要的文本不能为空")
+	}
+	if maxChars <= 0 {
+		maxChars = DefaultSummaryMaxChars
+	}
+
+	output, err := model(ctx, fmt.Sprintf(summaryPromptTemplate, maxChars, text))
+	if err != nil {
+		return "", NewGenerationError("", ErrorKindModel, err)
+	}
+	summary := strings.Join(strings.Fields(output), " ")
+	summary = strings.Trim(summary, "\"'“”「」`")
+	summary = strings.TrimPrefix(summary, "摘要：")
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return "", NewGenerationError("", ErrorKindParse, errors.New("摘要为空"))
+	}
+	if runes := []rune(summary); len(runes) > maxChars {
+		summary = string(runes[:maxChars])
+	}
+	return summary, nil
+}
+
+// WithWorldviewSummary 启用世界观摘要，生成世界观后为其及子世界观补充 Summary
+// maxChars 小于等于0时使用 DefaultSummaryMaxChars
+func WithWorldviewSummary(model ModelFunc, maxChars int) StoryOption {
+	return func(opts *StoryOptions) error {
+		if model == nil {
+			return errors.New("摘要模型函数不能为空")
+		}
+		opts.SummaryModel = model
+		opts.SummaryMaxChars = maxChars
+		return nil
+	}
+}
+
+// summarizeWorldviews 为世界观及其子世界观生成摘要，描述为空的跳过
+func summarizeWorldviews(ctx context.Context, model ModelFunc, maxChars int, worldviews []Worldview) error {
+	for i := range worldviews {
+		w := &worldviews[i]
+		if strings.TrimSpace(w.Description) != "" {
+			summary, err := GenerateSummary(ctx, model, w.Name+"："+w.Description, maxChars)
+			if err != nil {
+				return fmt.Errorf("世界观 %s 生成摘要失败: %w", w.Name, err)
+			}
+			w.Summary = summary
+		}
+		if err := summarizeWorldviews(ctx, model, maxChars, w.Children); err != nil {
+			return err
+		}
+	}
+	return nil
+}
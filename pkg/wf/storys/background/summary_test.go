@@ -0,0 +1,75 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestGenerateSummary 测试假模型返回的摘要被清理且不超过字数上限
+func TestGenerateSummary(t *testing.T) {
+	var prompt string
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "“摘要：横跨银河的星际帝国，贵族乘坐巨舰巡游群星，\n边境战火不断。”", nil
+	}
+
+	summary, err := GenerateSummary(context.Background(), fakeModel, "星海帝国是一个横跨银河的星际帝国……", 20)
+	if err != nil {
+		t.Fatalf("生成摘要失败: %v", err)
+	}
+	if !strings.Contains(prompt, "20字") || !strings.Contains(prompt, "星海帝国") {
+		t.Errorf("期望提示词包含字数上限和原文，实际为%s", prompt)
+	}
+	if summary == "" || utf8.RuneCountInString(summary) > 20 {
+		t.Errorf("期望摘要非空且不超过20字，实际为%q", summary)
+	}
+	if !strings.HasPrefix(summary, "横跨银河") {
+		t.Errorf("期望去掉引号与前缀，实际为%q", summary)
+	}
+}
+
+// TestGenerateSummary_Invalid 测试参数校验与空输出
+func TestGenerateSummary_Invalid(t *testing.T) {
+	ctx := context.Background()
+	emptyModel := func(ctx context.Context, p string) (string, error) { return " \n ", nil }
+
+	if _, err := GenerateSummary(ctx, emptyModel, " ", 50); err == nil {
+		t.Error("文本为空时应返回错误")
+	}
+	if _, err := GenerateSummary(ctx, nil, "文本", 50); err == nil {
+		t.Error("模型函数为空时应返回错误")
+	}
+
+	_, err := GenerateSummary(ctx, emptyModel, "文本", 50)
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("期望空输出返回解析错误，实际为%v", err)
+	}
+}
+
+// TestGenerate_WorldviewSummary 测试启用摘要后生成的世界观及子世界观带有摘要
+func TestGenerate_WorldviewSummary(t *testing.T) {
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		return strings.Repeat("帝", 80), nil
+	}
+	story, err := Generate(context.Background(),
+		WithWorldviewGenerator(func(ctx context.Context) ([]Worldview, error) {
+			return []Worldview{{
+				ID: 1, Name: "星海帝国", Description: "横跨银河的星际帝国",
+				Children: []Worldview{{ID: 2, Name: "边境星域", Description: "战火不断的边境", ParentID: 1}},
+			}}, nil
+		}),
+		WithWorldviewSummary(fakeModel, 0),
+	)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+	for _, w := range []Worldview{story.WorldViews[0], story.WorldViews[0].Children[0]} {
+		if n := utf8.RuneCountInString(w.Summary); n == 0 || n > DefaultSummaryMaxChars {
+			t.Errorf("期望世界观%s的摘要非空且不超过%d字，实际为%d字", w.Name, DefaultSummaryMaxChars, n)
+		}
+	}
+}
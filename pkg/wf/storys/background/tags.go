@@ -0,0 +1,80 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxSuggestedTags 单次建议的标签数量上限
+const MaxSuggestedTags = 50
+
+// tagPromptTemplate 标签建议提示词模板，参数依次为主题和标签数量
+const tagPromptTemplate = `你是一个小说设定整理助手，请围绕下面的主题给出%[2]d个便于检索设定的标签。
+标签应简短（2到6个字），彼此不重复，不要包含逗号。
+主题：%[1]s
+请严格按照如下JSON格式输出：["标签1", "标签2"]。不要输出除JSON以外的内容。`
+
+// SuggestTags 基于主题让模型建议一批候选标签，供用户在生成前挑选
+// 参数:
+// - ctx: 上下文
+// - model: 模型调用函数
+// - theme: 用户输入的主题
+// - n: 期望的标签数量，取值 1~MaxSuggestedTags
+// 返回:
+// - 去掉空白与重复项后的标签列表，数量不超过 n
+// - 参数非法、模型调用或结果解析失败时返回错误
+func SuggestTags(ctx context.Context, model ModelFunc, theme string, n int) ([]string, error) {
+	if model == nil {
+		return nil, errors.New("标签建议模型函数不能为空")
+	}
+	theme = strings.TrimSpace(theme)
+	if theme == "" {
+		return nil, errors.New("主题不能为空")
+	}
+	if n <= 0 || n > MaxSuggestedTags {
+		return nil, fmt.Errorf("标签数量必须在1到%d之间", MaxSuggestedTags)
+	}
+
+	output, err := model(ctx, fmt.Sprintf(tagPromptTemplate, theme, n))
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindModel, err)
+	}
+	tags, err := ParseTags(output)
+	if err != nil {
+		return nil, NewGenerationError("", ErrorKindParse, err)
+	}
+	if len(tags) > n {
+		tags = tags[:n]
+	}
+	return tags, nil
+}
+
+// ParseTags 解析模型返回的标签列表
+// 兼容模型在JSON前后附带说明文字或代码块标记的情况；
+// 标签以英文逗号分隔存储，因此含逗号的项会被拆开，空标签丢弃，重复标签（忽略大小写）只保留第一个
+func ParseTags(s string) ([]string, error) {
+	var raw []string
+	if err := decodeModelJSON(s, '[', &raw); err != nil {
+		return nil, fmt.Errorf("解析标签建议结果失败: %w", err)
+	}
+
+	tags := make([]string, 0, len(raw))
+	seen := make(map[string]struct{}, len(raw))
+	for _, item := range raw {
+		for _, tag := range strings.FieldsFunc(item, func(r rune) bool { return r == ',' || r == '，' }) {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			key := strings.ToLower(tag)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
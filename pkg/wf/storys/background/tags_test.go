@@ -0,0 +1,55 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSuggestTags 测试假模型返回的标签被去重并截断到 n 个
+func TestSuggestTags(t *testing.T) {
+	var prompt string
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "推荐标签如下：\n```json\n" + `["蒸汽朋克", "机械", " 蒸汽朋克 ", "", "飞艇,齿轮", "Steam", "steam", "工业革命"]` + "\n```", nil
+	}
+
+	tags, err := SuggestTags(context.Background(), fakeModel, "蒸汽时代的空中城市", 5)
+	if err != nil {
+		t.Fatalf("建议标签失败: %v", err)
+	}
+	if !strings.Contains(prompt, "蒸汽时代的空中城市") || !strings.Contains(prompt, "5个") {
+		t.Errorf("期望提示词包含主题和数量，实际为%s", prompt)
+	}
+
+	want := []string{"蒸汽朋克", "机械", "飞艇", "齿轮", "Steam"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("期望标签为%v，实际为%v", want, tags)
+	}
+}
+
+// TestSuggestTags_InvalidArgs 测试参数校验与解析失败
+func TestSuggestTags_InvalidArgs(t *testing.T) {
+	fakeModel := func(ctx context.Context, p string) (string, error) {
+		return "没有JSON", nil
+	}
+	ctx := context.Background()
+
+	if _, err := SuggestTags(ctx, fakeModel, " ", 3); err == nil {
+		t.Error("主题为空时应返回错误")
+	}
+	if _, err := SuggestTags(ctx, fakeModel, "主题", 0); err == nil {
+		t.Error("数量为0时应返回错误")
+	}
+	if _, err := SuggestTags(ctx, nil, "主题", 3); err == nil {
+		t.Error("模型函数为空时应返回错误")
+	}
+
+	_, err := SuggestTags(ctx, fakeModel, "主题", 3)
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("期望解析类错误，实际为%v", err)
+	}
+}
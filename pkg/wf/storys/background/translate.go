@@ -0,0 +1,115 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LanguageTagPrefix 译文在 Tag 中的语言标记前缀，如 lang:en
+const LanguageTagPrefix = "lang:"
+
+// translatePromptTemplate 世界观翻译提示词模板，参数依次为目标语言、名称、摘要和描述
+const translatePromptTemplate = `你是一名小说翻译，请将下面的小说世界观翻译为 %s。
+专有名词需前后统一，保留原文的设定细节和语气，不要增删内容。
+名称：%s
+摘要：%s
+描述：
+%s
+请严格按照如下JSON格式输出：{"name": "", "summary": "", "description": ""}，摘要为空时输出空字符串。不要输出除JSON以外的内容。`
+
+// WorldviewStore 翻译时读取原世界观与保存译文所需的存储操作，由调用方基于数据层实现
+type WorldviewStore interface {
+	// GetWorldview 按ID读取世界观
+	GetWorldview(ctx context.Context, id uint) (*Worldview, error)
+	// CreateWorldview 创建世界观，成功后回填 ID
+	CreateWorldview(ctx context.Context, w *Worldview) error
+}
+
+// worldviewTranslation 模型返回的翻译结果
+type worldviewTranslation struct {
+	Name        string `json:"name"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+// TranslateWorldview 将已有世界观翻译为目标语言，并作为关联记录保存
+// 译文的 ParentID 指向原世界观，Tag 中附带 lang:<目标语言> 标记，封面提示词沿用原记录
+// 参数:
+// - ctx: 上下文
+// - model: 翻译模型函数
+// - store: 世界观存储
+// - worldviewID: 原世界观ID
+// - targetLang: 目标语言，如 en、ja
+// 返回:
+// - 保存后的译文世界观
+// - 读取原记录失败、模型调用或结果解析失败、保存失败时返回错误
+func TranslateWorldview(ctx context.Context, model ModelFunc, store WorldviewStore, worldviewID uint, targetLang string) (*Worldview, error) {
+	if model == nil {
+		return nil, errors.New("翻译模型函数不能为空")
+	}
+	if store == nil {
+		return nil, errors.New("世界观存储不能为空")
+	}
+	targetLang = strings.TrimSpace(targetLang)
+	if targetLang == "" {
+		return nil, errors.New("目标语言不能为空")
+	}
+
+	source, err := store.GetWorldview(ctx, worldviewID)
+	if err != nil {
+		return nil, fmt.Errorf("读取原世界观失败: %w", err)
+	}
+
+	output, err := model(ctx, fmt.Sprintf(translatePromptTemplate, targetLang, source.Name, source.Summary, source.Description))
+	if err != nil {
+		return nil, NewGenerationError(StageWorldview, ErrorKindModel, err)
+	}
+	translation, err := parseWorldviewTranslation(output)
+	if err != nil {
+		return nil, NewGenerationError(StageWorldview, ErrorKindParse, err)
+	}
+
+	translated := &Worldview{
+		Name:        translation.Name,
+		Description: translation.Description,
+		Summary:     translation.Summary,
+		Tag:         withLanguageTag(source.Tag, targetLang),
+		ParentID:    source.ID,
+		CoverPrompt: source.CoverPrompt,
+	}
+	if err := store.CreateWorldview(ctx, translated); err != nil {
+		return nil, NewGenerationError(StageWorldview, ErrorKindSave, fmt.Errorf("保存译文失败: %w", err))
+	}
+	return translated, nil
+}
+
+// parseWorldviewTranslation 解析模型返回的翻译结果
+// 兼容模型在JSON前后附带说明文字或代码块标记的情况，名称或描述为空时返回错误
+func parseWorldviewTranslation(s string) (*worldviewTranslation, error) {
+	var translation worldviewTranslation
+	if err := decodeModelJSON(s, '{', &translation); err != nil {
+		return nil, fmt.Errorf("解析翻译结果失败: %w", err)
+	}
+	translation.Name = strings.TrimSpace(translation.Name)
+	translation.Summary = strings.TrimSpace(translation.Summary)
+	translation.Description = strings.TrimSpace(translation.Description)
+	if translation.Name == "" || translation.Description == "" {
+		return nil, errors.New("翻译结果的名称或描述为空")
+	}
+	return &translation, nil
+}
+
+// withLanguageTag 在标签中设置语言标记，替换原有的语言标记
+func withLanguageTag(tag, lang string) string {
+	tags := []string{}
+	for _, t := range strings.Split(tag, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" || strings.HasPrefix(t, LanguageTagPrefix) {
+			continue
+		}
+		tags = append(tags, t)
+	}
+	return strings.Join(append(tags, LanguageTagPrefix+lang), ",")
+}
@@ -0,0 +1,92 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// memoryWorldviewStore 测试用的内存世界观存储
+type memoryWorldviewStore struct {
+	worldviews map[uint]Worldview
+	nextID     uint
+}
+
+// GetWorldview 实现WorldviewStore接口
+func (s *memoryWorldviewStore) GetWorldview(ctx context.Context, id uint) (*Worldview, error) {
+	w, ok := s.worldviews[id]
+	if !ok {
+		return nil, errors.New("世界观不存在")
+	}
+	return &w, nil
+}
+
+// CreateWorldview 实现WorldviewStore接口
+func (s *memoryWorldviewStore) CreateWorldview(ctx context.Context, w *Worldview) error {
+	s.nextID++
+	w.ID = s.nextID
+	s.worldviews[w.ID] = *w
+	return nil
+}
+
+// TestTranslateWorldview 测试翻译后新记录内容为目标语言且关联到原世界观
+func TestTranslateWorldview(t *testing.T) {
+	store := &memoryWorldviewStore{
+		worldviews: map[uint]Worldview{
+			1: {ID: 1, Name: "星海帝国", Description: "人类在银河建立的庞大帝国", Summary: "银河帝国", Tag: "科幻,lang:zh", CoverPrompt: "galactic empire"},
+		},
+		nextID: 1,
+	}
+	var prompt string
+	model := func(ctx context.Context, p string) (string, error) {
+		prompt = p
+		return "```json\n" + `{"name": "Star Sea Empire", "summary": "Galactic empire", "description": "A vast empire built by humanity across the galaxy"}` + "\n```", nil
+	}
+
+	translated, err := TranslateWorldview(context.Background(), model, store, 1, "en")
+	if err != nil {
+		t.Fatalf("翻译失败: %v", err)
+	}
+	if !strings.Contains(prompt, "翻译为 en") || !strings.Contains(prompt, "人类在银河建立的庞大帝国") {
+		t.Errorf("提示词应包含目标语言与原文，实际为%s", prompt)
+	}
+	if translated.Name != "Star Sea Empire" || translated.Description != "A vast empire built by humanity across the galaxy" || translated.Summary != "Galactic empire" {
+		t.Errorf("译文内容不符合预期: %+v", translated)
+	}
+	if translated.ParentID != 1 || translated.Tag != "科幻,lang:en" || translated.CoverPrompt != "galactic empire" {
+		t.Errorf("译文应关联原世界观并带目标语言标记，实际为%+v", translated)
+	}
+
+	saved, ok := store.worldviews[translated.ID]
+	if !ok || translated.ID == 1 || saved.Name != "Star Sea Empire" {
+		t.Errorf("译文应保存为新记录，实际为%+v", store.worldviews)
+	}
+	if store.worldviews[1].Name != "星海帝国" {
+		t.Error("原世界观不应被修改")
+	}
+}
+
+// TestTranslateWorldviewErrors 测试原记录不存在与模型输出无效时返回错误且不保存
+func TestTranslateWorldviewErrors(t *testing.T) {
+	store := &memoryWorldviewStore{worldviews: map[uint]Worldview{1: {ID: 1, Name: "星海帝国", Description: "庞大帝国"}}, nextID: 1}
+	model := func(ctx context.Context, p string) (string, error) {
+		return `{"name": "Star Sea Empire", "description": ""}`, nil
+	}
+
+	if _, err := TranslateWorldview(context.Background(), model, store, 2, "en"); err == nil {
+		t.Error("原世界观不存在时应返回错误")
+	}
+
+	_, err := TranslateWorldview(context.Background(), model, store, 1, "en")
+	var genErr *GenerationError
+	if !errors.As(err, &genErr) || genErr.Kind != ErrorKindParse {
+		t.Errorf("译文描述为空时应返回解析错误，实际为%v", err)
+	}
+	if _, err := TranslateWorldview(context.Background(), model, store, 1, " "); err == nil {
+		t.Error("目标语言为空时应返回错误")
+	}
+	if len(store.worldviews) != 1 {
+		t.Errorf("失败时不应保存译文，实际为%+v", store.worldviews)
+	}
+}